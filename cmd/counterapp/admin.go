@@ -0,0 +1,117 @@
+package main
+
+import (
+	"counterapp/internal/config"
+	"counterapp/internal/dao"
+	"counterapp/internal/events"
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+	"counterapp/internal/service"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// counterapp create-admin creates an admin user and prints its API token,
+// which is not stored and cannot be shown again
+func runCreateAdmin(cfg *config.Config, logger *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	name := fs.String("name", "", "name of the admin (required)")
+	email := fs.String("email", "", "email of the admin (required)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: counterapp create-admin -name <name> -email <email>")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *name == "" || *email == "" {
+		fs.Usage()
+		return errUsage
+	}
+	return createUser(cfg, logger, *name, *email, model.RoleAdmin)
+}
+
+// counterapp create-user creates a user with a role, staff by default, and
+// prints its API token like create-admin
+func runCreateUser(cfg *config.Config, logger *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("create-user", flag.ContinueOnError)
+	name := fs.String("name", "", "name of the user (required)")
+	email := fs.String("email", "", "email of the user (required)")
+	role := fs.String("role", string(model.RoleStaff), "role of the user, admin or staff")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: counterapp create-user -name <name> -email <email> [-role staff|admin]")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *name == "" || *email == "" {
+		fs.Usage()
+		return errUsage
+	}
+	return createUser(cfg, logger, *name, *email, model.Role(*role))
+}
+
+func createUser(cfg *config.Config, logger *slog.Logger, name string, email string, role model.Role) error {
+	db, err := connectMigrated(cfg, logger)
+	if err != nil {
+		return err
+	}
+	svc := service.New(repository.NewGormRepositories(db), logger)
+
+	user, token, err := svc.Users.Create(name, email, role)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created %s %s <%s> (%s)\n", user.Role, user.Name, user.Email, user.ID)
+	fmt.Printf("API token, shown only once: %s\n", token)
+	return nil
+}
+
+// counterapp check-out-overdue checks out every visit still checked in after
+// its departure date, meant to run daily from cron
+func runCheckOutOverdue(cfg *config.Config, logger *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("check-out-overdue", flag.ContinueOnError)
+	date := fs.String("date", "", "visits departing before this day are overdue, YYYY-MM-DD (default today)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: counterapp check-out-overdue [-date YYYY-MM-DD]")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	today := time.Now()
+	if *date != "" {
+		parsed, err := time.Parse("2006-01-02", *date)
+		if err != nil {
+			return errors.New("-date must be YYYY-MM-DD")
+		}
+		today = parsed
+	}
+
+	db, err := connectMigrated(cfg, logger)
+	if err != nil {
+		return err
+	}
+	// stream clients of running servers only hear about these when events go
+	// through postgres notify, webhook deliveries are recorded either way
+	if cfg.Features.EventsPGNotify {
+		dao.SetEventPublisher(events.NewPostgresPublisher(db, logger))
+	}
+	svc := service.New(repository.NewGormRepositories(db), logger)
+
+	checkedOut, err := svc.Visits.CheckOutOverdue(today)
+	for _, visit := range checkedOut {
+		fmt.Printf("checked out visit %s of profile %s, departure was %s\n",
+			visit.ID, visit.ProfileID, visit.DepartureDate.Format(time.DateOnly))
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("checked out %d overdue visits\n", len(checkedOut))
+	return nil
+}
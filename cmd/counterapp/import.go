@@ -0,0 +1,82 @@
+package main
+
+import (
+	"counterapp/internal/config"
+	"counterapp/internal/dao"
+	"counterapp/internal/events"
+	"counterapp/internal/importer"
+	"counterapp/internal/repository"
+	"counterapp/internal/service"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// counterapp import reads profiles and planned visits from a CSV file, like
+// POST /api/imports/profiles, and prints what happened to every row
+func runImport(cfg *config.Config, logger *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := fs.String("file", "", "CSV file to import, - reads standard input (required)")
+	dryRun := fs.Bool("dry-run", false, "report what would change without saving anything")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: counterapp import -file <profiles.csv> [-dry-run]")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *file == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	rows, err := importer.ParseProfiles(in)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", *file, err)
+	}
+
+	db, err := connectMigrated(cfg, logger)
+	if err != nil {
+		return err
+	}
+	// like check-out-overdue, running servers hear about the new profiles
+	// only when events go through postgres notify
+	if cfg.Features.EventsPGNotify {
+		dao.SetEventPublisher(events.NewPostgresPublisher(db, logger))
+	}
+	svc := service.New(repository.NewGormRepositories(db), logger)
+
+	report, err := importer.ImportProfiles(svc, rows, *dryRun)
+	if err != nil {
+		return err
+	}
+	for _, row := range report.Rows {
+		fmt.Printf("row %d %s %s", row.Row, row.Email, row.Status)
+		if len(row.Messages) > 0 {
+			fmt.Printf(": %s", strings.Join(row.Messages, "; "))
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%d created, %d updated, %d skipped, %d with errors\n", report.Created, report.Updated, report.Skipped, report.Errors)
+
+	switch {
+	case *dryRun:
+		fmt.Println("dry run, nothing was saved")
+	case !report.Committed:
+		return errors.New("import rejected, nothing was saved")
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Command counterapp serves the API and runs the maintenance tasks around
+// it, every subcommand reads the same configuration.
+package main
+
+import (
+	"counterapp/internal/config"
+	"counterapp/internal/dao"
+	"counterapp/internal/logging"
+	"counterapp/internal/notify"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const usage = `usage: counterapp [-config file] <command> [flags]
+
+commands:
+  serve              run the API server, applying pending migrations first
+  migrate            apply, revert or list schema migrations
+  seed               fill the database with reference and generated data
+  clear              remove every profile, visit and the reference data
+  import             import profiles and planned visits from a CSV file
+  create-admin       create an admin user and print its API token
+  create-user        create a staff or admin user and print its API token
+  check-out-overdue  check out visits past their departure date
+
+-config names the YAML configuration file, by default COUNTERAPP_CONFIG or
+counterapp.yaml when it exists. Environment variables and .env override it.
+
+run counterapp <command> -h for the flags of a command`
+
+type command func(cfg *config.Config, logger *slog.Logger, args []string) error
+
+var commands = map[string]command{
+	"serve":             runServe,
+	"migrate":           runMigrate,
+	"seed":              runSeed,
+	"clear":             runClear,
+	"import":            runImport,
+	"create-admin":      runCreateAdmin,
+	"create-user":       runCreateUser,
+	"check-out-overdue": runCheckOutOverdue,
+}
+
+// returned by a command whose arguments are wrong, after it printed its usage
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	global := flag.NewFlagSet("counterapp", flag.ContinueOnError)
+	global.Usage = func() { fmt.Fprintln(global.Output(), usage) }
+	configPath := global.String("config", "", "configuration file")
+	if err := global.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		os.Exit(2)
+	}
+	if global.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	name, args := global.Arg(0), global.Args()[1:]
+	if name == "help" {
+		fmt.Println(usage)
+		return
+	}
+
+	run, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", name, usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	time.Local = cfg.Location
+	notify.SetDefaultCountryCode(cfg.Notify.DefaultCountryCode)
+	logger := logging.New(cfg.Log, os.Stderr)
+	slog.SetDefault(logger)
+
+	if err := run(cfg, logger, args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+		os.Exit(1)
+	}
+}
+
+func connect(cfg *config.Config, logger *slog.Logger) (*gorm.DB, error) {
+	db, err := dao.Connect(cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the database: %w", err)
+	}
+	return db, nil
+}
+
+// parses the flags of a command, which prints its own errors and usage
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	fs.SetOutput(os.Stderr)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "unexpected argument %q\n", fs.Arg(0))
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"counterapp/internal/config"
+	"counterapp/internal/migrations"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+)
+
+const migrateUsage = "usage: counterapp migrate up | down [steps] | status"
+
+// counterapp migrate up applies pending migrations, down reverts the latest
+// one (or steps of them) and status lists every migration with when it was
+// applied
+func runMigrate(cfg *config.Config, logger *slog.Logger, args []string) error {
+	if len(args) == 0 || (args[0] != "up" && args[0] != "down" && args[0] != "status") {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return errUsage
+	}
+
+	db, err := connect(cfg, logger)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrations.Up(db)
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return errors.New("steps must be a positive number")
+			}
+			steps = n
+		}
+		reverted, err := migrations.Down(db, steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("nothing to revert")
+		}
+		return err
+
+	case "status":
+		statuses, err := migrations.StatusOf(db)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if !s.Known {
+				state += " (unknown to this binary)"
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, state)
+		}
+		return nil
+	}
+	return nil
+}
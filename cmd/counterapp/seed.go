@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"counterapp/internal/config"
+	"counterapp/internal/migrations"
+	"counterapp/internal/seed"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// counterapp seed generates volunteers with their visits, schedules and
+// feedback, the same flags on the same data always produce the same result
+func runSeed(cfg *config.Config, logger *slog.Logger, args []string) error {
+	defaults := seed.DefaultOptions()
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	profiles := fs.Int("profiles", defaults.Profiles, "number of volunteers to generate")
+	visits := fs.Int("visits", defaults.Visits, "total visits to aim for, 0 gives one or two per volunteer")
+	lockers := fs.Int("lockers", defaults.Lockers, "number of lockers to create")
+	stayAreas := fs.Int("stay-areas", defaults.StayAreas, "generated stay areas to add to the reference ones")
+	appendData := fs.Bool("append", false, "add to the existing profiles instead of refusing")
+	seedValue := fs.Uint64("seed", defaults.Seed, "random seed, the same seed and date give the same data")
+	date := fs.String("date", "", "day visits and schedules are placed around, YYYY-MM-DD (default today)")
+	clearFirst := fs.Bool("clear", false, "clear the database first, asking for confirmation")
+	yes := fs.Bool("yes", false, "with -clear, do not ask for confirmation")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: counterapp seed [flags]")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *profiles < 0 || *visits < 0 || *lockers < 0 || *stayAreas < 0 {
+		return errors.New("-profiles, -visits, -lockers and -stay-areas cannot be negative")
+	}
+	if *appendData && *clearFirst {
+		return errors.New("-append and -clear cannot be combined")
+	}
+
+	opts := seed.Options{
+		Profiles:  *profiles,
+		Visits:    *visits,
+		Lockers:   *lockers,
+		StayAreas: *stayAreas,
+		Append:    *appendData,
+		Seed:      *seedValue,
+		Logger:    logger,
+	}
+	if *date != "" {
+		today, err := time.Parse("2006-01-02", *date)
+		if err != nil {
+			return errors.New("-date must be YYYY-MM-DD")
+		}
+		opts.Today = today
+	}
+
+	db, err := connectMigrated(cfg, logger)
+	if err != nil {
+		return err
+	}
+	if *clearFirst {
+		if err := clearDatabase(db, cfg, *yes); err != nil {
+			return err
+		}
+	}
+
+	summary, err := seed.Run(db, opts)
+	if errors.Is(err, seed.ErrNotEmpty) {
+		return fmt.Errorf("%w (run counterapp clear, or pass -clear or -append)", err)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("seeded %s with seed %d:\n", cfg.Database.Name, opts.Seed)
+	fmt.Printf("  seva types %d, stay areas %d, lockers %d\n", summary.SevaTypes, summary.StayAreas, summary.Lockers)
+	fmt.Printf("  profiles %d, visits %d, schedules %d, feedback %d\n", summary.Profiles, summary.Visits, summary.Schedules, summary.Feedbacks)
+	return nil
+}
+
+// counterapp clear removes every profile, visit and the reference data after
+// the database name is typed back
+func runClear(cfg *config.Config, logger *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("clear", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: counterapp clear [-yes]")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	db, err := connectMigrated(cfg, logger)
+	if err != nil {
+		return err
+	}
+	return clearDatabase(db, cfg, *yes)
+}
+
+func clearDatabase(db *gorm.DB, cfg *config.Config, yes bool) error {
+	if !yes {
+		fmt.Printf("This deletes every profile, visit, schedule, feedback, locker, stay area and seva type in %s on %s.\n", cfg.Database.Name, cfg.Database.Host)
+		fmt.Print("Type the database name to confirm: ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(answer) != cfg.Database.Name {
+			return errors.New("not confirmed, nothing was cleared")
+		}
+	}
+
+	if err := seed.Clear(db); err != nil {
+		return err
+	}
+	fmt.Printf("cleared %s\n", cfg.Database.Name)
+	return nil
+}
+
+// connects and makes sure the schema is current, the maintenance commands
+// leave migrating to counterapp migrate or serve
+func connectMigrated(cfg *config.Config, logger *slog.Logger) (*gorm.DB, error) {
+	db, err := connect(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrations.Check(db); err != nil {
+		return nil, fmt.Errorf("%w (run counterapp migrate up)", err)
+	}
+	return db, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"counterapp/internal/config"
+	"counterapp/internal/dao"
+	"counterapp/internal/events"
+	"counterapp/internal/metrics"
+	"counterapp/internal/migrations"
+	"counterapp/internal/notify"
+	"counterapp/internal/repository"
+	"counterapp/internal/retention"
+	"counterapp/internal/service"
+	"counterapp/internal/webhook"
+	"counterapp/server/api"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// number of recent events kept for stream clients that reconnect
+	eventHistorySize = 1000
+
+	// slow clients cannot hold a connection open by trickling headers
+	readHeaderTimeout = 10 * time.Second
+)
+
+// counterapp serve applies pending migrations, starts the background jobs
+// and serves the API until SIGTERM or SIGINT, then drains in-flight requests
+func runServe(cfg *config.Config, logger *slog.Logger, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: counterapp serve\n\nlistens on PORT from the configuration")
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	// cancelled by the first signal, which stops the background jobs; the
+	// second one kills the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := connect(cfg, logger)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+	logger.Info("connected to database", "database", cfg.Database.Name, "host", cfg.Database.Host)
+
+	applied, err := migrations.Up(db)
+	if err != nil {
+		return fmt.Errorf("migrating schemas: %w", err)
+	}
+	logger.Info("applied migrations", "count", len(applied))
+	if err := dao.CheckEncryptionKey(db); err != nil {
+		return err
+	}
+
+	var jobs sync.WaitGroup
+	background := func(run func(ctx context.Context)) {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			run(ctx)
+		}()
+	}
+
+	if cfg.Features.Notifications {
+		notifiers, err := notify.NewNotifiers(cfg.Notify)
+		if err != nil {
+			return fmt.Errorf("configuring notifications: %w", err)
+		}
+		notify.SetChannels(notifiers)
+		if len(notifiers) > 0 {
+			sender := notify.NewSender(db, notifiers, notify.SenderOptions{
+				PollInterval: cfg.Notify.PollInterval,
+				MaxAttempts:  cfg.Notify.MaxAttempts,
+				Logger:       logger.With("component", "notify"),
+			})
+			background(sender.Run)
+			logger.Info("started notification sender", "channels", len(notifiers))
+		}
+	}
+
+	if cfg.Features.Webhooks {
+		background(webhook.NewDispatcher(db, webhook.DispatcherOptions{Logger: logger.With("component", "webhook")}).Run)
+	}
+
+	bus := events.NewBus(eventHistorySize)
+	if cfg.Features.EventsPGNotify {
+		dao.SetEventPublisher(events.NewPostgresPublisher(db, logger))
+		background(func(ctx context.Context) {
+			events.ListenPostgres(ctx, cfg.Database.DSN(), bus, logger)
+		})
+		logger.Info("sharing events through postgres notify")
+	} else {
+		dao.SetEventPublisher(bus)
+	}
+
+	// erasing profiles emits events, so after the publisher is set
+	if cfg.Retention.Enabled {
+		// on a context that ends at shutdown, so a run in progress stops
+		retentionSvc := service.New(repository.NewGormRepositories(db.WithContext(ctx)), logger).Retention
+		background(retention.NewJob(retentionSvc, cfg.Retention, logger.With("component", "retention")).Run)
+		logger.Info("started retention job", "interval", cfg.Retention.Interval.String())
+	}
+
+	// gin's own debug lines are not structured
+	if cfg.Log.Level != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	m := metrics.New(db, logger)
+	svc := service.New(repository.NewGormRepositories(db), logger)
+	router := api.SetupRouter(cfg, logger, svc, bus, m)
+
+	server := newHTTPServer(cfg.Server, cfg.Server.Port, router, logger)
+	// streams never finish on their own, end them so the drain can
+	server.RegisterOnShutdown(bus.Close)
+	servers := []*http.Server{server}
+	if cfg.Server.MetricsPort != 0 {
+		servers = append(servers, newHTTPServer(cfg.Server, cfg.Server.MetricsPort, m.Handler(), logger))
+	}
+
+	failed := make(chan error, len(servers))
+	for _, s := range servers {
+		go func() {
+			if err := s.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				failed <- fmt.Errorf("serving on %s: %w", s.Addr, err)
+			}
+		}()
+	}
+	logger.Info("starting server", "addr", server.Addr)
+	if len(servers) > 1 {
+		logger.Info("serving metrics", "addr", servers[1].Addr)
+	}
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		logger.Info("shutting down", "timeout", cfg.Server.ShutdownTimeout.String())
+	case serveErr = <-failed:
+		logger.Error("server failed, shutting down", "error", serveErr)
+	}
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	for _, s := range servers {
+		if err := s.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("requests still running at shutdown timeout", "addr", s.Addr, "error", err)
+		}
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		jobs.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		logger.Warn("background jobs still running at shutdown timeout")
+	}
+
+	logger.Info("stopped")
+	return serveErr
+}
+
+func newHTTPServer(cfg config.Server, port int, handler http.Handler, logger *slog.Logger) *http.Server {
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           handler,
+		ReadHeaderTimeout: min(readHeaderTimeout, cfg.ReadTimeout),
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(logger.With("component", "http").Handler(), slog.LevelWarn),
+	}
+}
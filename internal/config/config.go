@@ -1,51 +1,259 @@
+// Package config loads the application settings once at startup: defaults,
+// then an optional YAML file, then .env and environment variables, and
+// validates the result before anything uses it.
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
+// read when neither a path nor COUNTERAPP_CONFIG is given and it exists
+const DefaultFile = "counterapp.yaml"
+
 type Config struct {
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	Port       string
+	Server   Server   `yaml:"server"`
+	Database Database `yaml:"database"`
+	Features Features `yaml:"features"`
+	Notify   Notify   `yaml:"notify"`
+	Log      Log      `yaml:"log"`
+	// how long personal data is kept
+	Retention Retention `yaml:"retention"`
+
+	// IANA name of the zone "today" and other calendar dates are taken in,
+	// Local is the zone of the machine
+	Timezone string `yaml:"timezone"`
+	// Timezone once loaded
+	Location *time.Location `yaml:"-"`
 }
 
-func Load() *Config {
-	if err := godotenv.Load(".env"); err != nil {
-		fmt.Println("⚠️  Warning: .env file not found, using environment variables or defaults")
-		fmt.Printf("   Error: %v\n", err)
-	} else {
-		fmt.Println("✅ .env file loaded successfully")
-	}
+type Server struct {
+	Port int `yaml:"port"`
+	// /metrics is served on this port, away from the public one, or on Port
+	// when 0
+	MetricsPort int `yaml:"metrics_port"`
+	// browser origins allowed to call the API, scheme and host only
+	CORSOrigins []string `yaml:"cors_origins"`
+
+	// limits on reading a whole request and writing a whole response, the
+	// event stream and exports lift the write limit for themselves
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	// how long a keep-alive connection waits for its next request
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+	// how long in-flight requests get to finish after SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+type Database struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"ssl_mode"`
+
+	// zero leaves the database/sql default
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+
+	// base64 of the 32 byte key medical info is encrypted with, it cannot be
+	// stored without one
+	EncryptionKey string `yaml:"encryption_key"`
+	// EncryptionKey once decoded, nil when none is set
+	Key []byte `yaml:"-"`
+}
+
+type Features struct {
+	// share stream events between app machines through PostgreSQL NOTIFY
+	EventsPGNotify bool `yaml:"events_pg_notify"`
+	// deliver outbound webhooks, deliveries queue up while it is off
+	Webhooks bool `yaml:"webhooks"`
+	// send queued notifications on the configured channels
+	Notifications bool `yaml:"notifications"`
+}
+
+type Notify struct {
+	SMTP SMTP `yaml:"smtp"`
+
+	SMSWebhookURL      string `yaml:"sms_webhook_url"`
+	WhatsAppWebhookURL string `yaml:"whatsapp_webhook_url"`
+	WebhookToken       string `yaml:"webhook_token"`
+	DefaultCountryCode string `yaml:"default_country_code"`
+
+	PollInterval time.Duration `yaml:"poll_interval"`
+	MaxAttempts  int           `yaml:"max_attempts"`
+}
+
+type Log struct {
+	// debug, info, warn or error
+	Level string `yaml:"level"`
+	// json or text
+	Format string `yaml:"format"`
+
+	// SQL statements logged: silent, error, warn (errors and slow queries)
+	// or info (every statement)
+	SQLLevel  string        `yaml:"sql_level"`
+	SlowQuery time.Duration `yaml:"slow_query"`
+	// log statements with their parameters, which hold names and phone
+	// numbers, instead of placeholders
+	SQLParams bool `yaml:"sql_params"`
+}
+
+// A period of 0 keeps the data for good. Periods are counted from the last
+// visit for remarks and profiles, so nothing is removed about people who
+// still come.
+type Retention struct {
+	// apply the rules in the background every Interval, the dry-run report
+	// is available either way
+	Enabled  bool          `yaml:"enabled"`
+	Interval time.Duration `yaml:"interval"`
+
+	// profiles without a visit in this many years are anonymized
+	InactiveProfileYears int `yaml:"inactive_profile_years"`
+	// remarks on profiles, visits and schedules are cleared this many months
+	// after the last visit
+	RemarksMonths int `yaml:"remarks_months"`
+	// feedback text is replaced this many months after it was written
+	FeedbackMonths int `yaml:"feedback_months"`
+	// deleted profiles and visits are purged this many days after deletion
+	PurgeDeletedDays int `yaml:"purge_deleted_days"`
+}
+
+type SMTP struct {
+	// email is only sent when a host is configured
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
 
+// the settings used when neither the file nor the environment sets them
+func Default() *Config {
 	return &Config{
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBPort:     getEnv("DB_PORT", "5432"),
-		DBUser:     getEnv("DB_USER", "postgres"),
-		DBPassword: getEnv("DB_PASSWORD", ""),
-		DBName:     getEnv("DB_NAME", "counter_db"),
-		Port:       getEnv("PORT", "8080"),
+		Server: Server{
+			Port:            8080,
+			MetricsPort:     9091,
+			CORSOrigins:     []string{"https://ashram-connect.vercel.app", "http://localhost:3000", "http://localhost:5173"},
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    60 * time.Second,
+			IdleTimeout:     2 * time.Minute,
+			ShutdownTimeout: 25 * time.Second,
+		},
+		Database: Database{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "postgres",
+			Name:            "counter_db",
+			SSLMode:         "disable",
+			MaxOpenConns:    20,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		Features: Features{Webhooks: true, Notifications: true},
+		Notify: Notify{
+			SMTP:               SMTP{Port: 587, From: "Ashram Connect <no-reply@localhost>"},
+			DefaultCountryCode: "91",
+			PollInterval:       10 * time.Second,
+			MaxAttempts:        6,
+		},
+		Log: Log{
+			Level:     "info",
+			Format:    "json",
+			SQLLevel:  "warn",
+			SlowQuery: 200 * time.Millisecond,
+		},
+		Retention: Retention{Interval: 24 * time.Hour},
+		Timezone:  "Local",
+		Location:  time.Local,
 	}
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// reads the configuration file at path, or COUNTERAPP_CONFIG, or
+// counterapp.yaml when it exists, applies .env and the environment on top
+// and validates the result
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	required := true
+	if path == "" {
+		path = os.Getenv("COUNTERAPP_CONFIG")
+	}
+	if path == "" {
+		path, required = DefaultFile, false
+	}
+	if err := cfg.readFile(path, required); err != nil {
+		return nil, err
+	}
+
+	if err := godotenv.Load(".env"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("reading .env: %w", err)
+	}
+
+	problems := cfg.applyEnv()
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return cfg, nil
+}
+
+func (c *Config) readFile(path string, required bool) error {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading configuration: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("reading configuration %s: %w", path, err)
 	}
-	return defaultValue
+	return nil
 }
 
-func (c *Config) GetDSN() string {
-	if c.DBPassword == "" {
-		return fmt.Sprintf("host=%s port=%s user=%s dbname=%s sslmode=disable",
-			c.DBHost, c.DBPort, c.DBUser, c.DBName)
+// a libpq keyword/value connection string, values are quoted so passwords
+// may hold spaces and quotes
+func (d Database) DSN() string {
+	parts := []string{
+		"host=" + quoteDSN(d.Host),
+		fmt.Sprintf("port=%d", d.Port),
+		"user=" + quoteDSN(d.User),
+		"dbname=" + quoteDSN(d.Name),
+		"sslmode=" + quoteDSN(d.SSLMode),
+	}
+	if d.Password != "" {
+		parts = append(parts, "password="+quoteDSN(d.Password))
 	}
-	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		c.DBHost, c.DBPort, c.DBUser, c.DBPassword, c.DBName)
+	return strings.Join(parts, " ")
+}
+
+func quoteDSN(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// notifications are only sent when an SMTP host is configured
+func (s SMTP) Enabled() bool {
+	return s.Host != ""
 }
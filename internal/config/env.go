@@ -0,0 +1,157 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// overrides the settings that have an environment variable set, returning a
+// problem for every value that does not parse. Secrets can also be read
+// from a file named by the variable with a _FILE suffix, as mounted by
+// Docker and Kubernetes secrets.
+func (c *Config) applyEnv() []string {
+	e := &envReader{}
+
+	e.int("PORT", &c.Server.Port)
+	e.int("METRICS_PORT", &c.Server.MetricsPort)
+	e.list("CORS_ORIGINS", &c.Server.CORSOrigins)
+	e.duration("HTTP_READ_TIMEOUT", &c.Server.ReadTimeout)
+	e.duration("HTTP_WRITE_TIMEOUT", &c.Server.WriteTimeout)
+	e.duration("HTTP_IDLE_TIMEOUT", &c.Server.IdleTimeout)
+	e.duration("SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout)
+	e.string("TIMEZONE", &c.Timezone)
+
+	e.string("DB_HOST", &c.Database.Host)
+	e.int("DB_PORT", &c.Database.Port)
+	e.string("DB_USER", &c.Database.User)
+	e.secret("DB_PASSWORD", &c.Database.Password)
+	e.string("DB_NAME", &c.Database.Name)
+	e.string("DB_SSLMODE", &c.Database.SSLMode)
+	e.int("DB_MAX_OPEN_CONNS", &c.Database.MaxOpenConns)
+	e.int("DB_MAX_IDLE_CONNS", &c.Database.MaxIdleConns)
+	e.duration("DB_CONN_MAX_LIFETIME", &c.Database.ConnMaxLifetime)
+	e.duration("DB_CONN_MAX_IDLE_TIME", &c.Database.ConnMaxIdleTime)
+	e.secret("DB_ENCRYPTION_KEY", &c.Database.EncryptionKey)
+
+	e.bool("EVENTS_PG_NOTIFY", &c.Features.EventsPGNotify)
+	e.bool("FEATURE_WEBHOOKS", &c.Features.Webhooks)
+	e.bool("FEATURE_NOTIFICATIONS", &c.Features.Notifications)
+
+	e.string("SMTP_HOST", &c.Notify.SMTP.Host)
+	e.int("SMTP_PORT", &c.Notify.SMTP.Port)
+	e.string("SMTP_USERNAME", &c.Notify.SMTP.Username)
+	e.secret("SMTP_PASSWORD", &c.Notify.SMTP.Password)
+	e.string("SMTP_FROM", &c.Notify.SMTP.From)
+	e.string("SMS_WEBHOOK_URL", &c.Notify.SMSWebhookURL)
+	e.string("WHATSAPP_WEBHOOK_URL", &c.Notify.WhatsAppWebhookURL)
+	e.secret("NOTIFIER_WEBHOOK_TOKEN", &c.Notify.WebhookToken)
+	e.string("DEFAULT_COUNTRY_CODE", &c.Notify.DefaultCountryCode)
+	e.duration("NOTIFY_POLL_INTERVAL", &c.Notify.PollInterval)
+	e.int("NOTIFY_MAX_ATTEMPTS", &c.Notify.MaxAttempts)
+
+	e.string("LOG_LEVEL", &c.Log.Level)
+	e.string("LOG_FORMAT", &c.Log.Format)
+	e.string("LOG_SQL_LEVEL", &c.Log.SQLLevel)
+	e.duration("LOG_SLOW_QUERY", &c.Log.SlowQuery)
+	e.bool("LOG_SQL_PARAMS", &c.Log.SQLParams)
+
+	e.bool("RETENTION_ENABLED", &c.Retention.Enabled)
+	e.duration("RETENTION_INTERVAL", &c.Retention.Interval)
+	e.int("RETENTION_INACTIVE_PROFILE_YEARS", &c.Retention.InactiveProfileYears)
+	e.int("RETENTION_REMARKS_MONTHS", &c.Retention.RemarksMonths)
+	e.int("RETENTION_FEEDBACK_MONTHS", &c.Retention.FeedbackMonths)
+	e.int("RETENTION_PURGE_DELETED_DAYS", &c.Retention.PurgeDeletedDays)
+
+	return e.problems
+}
+
+type envReader struct {
+	problems []string
+}
+
+// an empty variable counts as unset, like the earlier getEnv did
+func (e *envReader) lookup(key string) (string, bool) {
+	value := strings.TrimSpace(os.Getenv(key))
+	return value, value != ""
+}
+
+func (e *envReader) string(key string, dst *string) {
+	if value, ok := e.lookup(key); ok {
+		*dst = value
+	}
+}
+
+func (e *envReader) secret(key string, dst *string) {
+	value, ok := e.lookup(key)
+	path, fromFile := e.lookup(key + "_FILE")
+	switch {
+	case ok && fromFile:
+		e.problems = append(e.problems, fmt.Sprintf("%s and %s_FILE are both set, use one", key, key))
+	case fromFile:
+		body, err := os.ReadFile(path)
+		if err != nil {
+			e.problems = append(e.problems, fmt.Sprintf("%s_FILE: %v", key, err))
+			return
+		}
+		*dst = strings.TrimRight(string(body), "\r\n")
+	case ok:
+		*dst = value
+	}
+}
+
+func (e *envReader) int(key string, dst *int) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		e.problems = append(e.problems, fmt.Sprintf("%s must be a whole number, got %q", key, value))
+		return
+	}
+	*dst = n
+}
+
+func (e *envReader) bool(key string, dst *bool) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		e.problems = append(e.problems, fmt.Sprintf("%s must be true or false, got %q", key, value))
+		return
+	}
+	*dst = b
+}
+
+func (e *envReader) duration(key string, dst *time.Duration) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		e.problems = append(e.problems, fmt.Sprintf("%s must be a duration such as 30s or 5m, got %q", key, value))
+		return
+	}
+	*dst = d
+}
+
+// comma separated, blank entries are dropped
+func (e *envReader) list(key string, dst *[]string) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*dst = items
+}
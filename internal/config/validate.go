@@ -0,0 +1,171 @@
+package config
+
+import (
+	"counterapp/internal/crypt"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	sslModes     = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	logLevels    = []string{"debug", "info", "warn", "error"}
+	logFormats   = []string{"json", "text"}
+	sqlLogLevels = []string{"silent", "error", "warn", "info"}
+)
+
+// checks every setting and loads the time zone, returning one problem per
+// broken setting named as in the configuration file
+func (c *Config) validate() []string {
+	var problems []string
+	fail := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if !validPort(c.Server.Port) {
+		fail("server.port must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	if c.Server.MetricsPort != 0 && !validPort(c.Server.MetricsPort) {
+		fail("server.metrics_port must be 0 or between 1 and 65535, got %d", c.Server.MetricsPort)
+	}
+	if c.Server.MetricsPort == c.Server.Port {
+		fail("server.metrics_port must differ from server.port, use 0 to serve /metrics on server.port")
+	}
+	for name, d := range map[string]time.Duration{
+		"server.read_timeout":     c.Server.ReadTimeout,
+		"server.write_timeout":    c.Server.WriteTimeout,
+		"server.idle_timeout":     c.Server.IdleTimeout,
+		"server.shutdown_timeout": c.Server.ShutdownTimeout,
+	} {
+		if d <= 0 {
+			fail("%s must be positive", name)
+		}
+	}
+	for _, origin := range c.Server.CORSOrigins {
+		if err := checkOrigin(origin); err != nil {
+			fail("server.cors_origins: %q %s", origin, err)
+		}
+	}
+
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		fail("timezone %q is not a known time zone", c.Timezone)
+	} else {
+		c.Location = location
+	}
+
+	db := c.Database
+	if db.Host == "" {
+		fail("database.host is required")
+	}
+	if !validPort(db.Port) {
+		fail("database.port must be between 1 and 65535, got %d", db.Port)
+	}
+	if db.User == "" {
+		fail("database.user is required")
+	}
+	if db.Name == "" {
+		fail("database.name is required")
+	}
+	if !slices.Contains(sslModes, db.SSLMode) {
+		fail("database.ssl_mode must be one of %s, got %q", strings.Join(sslModes, ", "), db.SSLMode)
+	}
+	if db.MaxOpenConns < 0 {
+		fail("database.max_open_conns cannot be negative")
+	}
+	if db.MaxIdleConns < 0 {
+		fail("database.max_idle_conns cannot be negative")
+	}
+	if db.MaxOpenConns > 0 && db.MaxIdleConns > db.MaxOpenConns {
+		fail("database.max_idle_conns (%d) cannot exceed database.max_open_conns (%d)", db.MaxIdleConns, db.MaxOpenConns)
+	}
+	if db.ConnMaxLifetime < 0 || db.ConnMaxIdleTime < 0 {
+		fail("database.conn_max_lifetime and database.conn_max_idle_time cannot be negative")
+	}
+	if db.EncryptionKey != "" {
+		key, err := crypt.ParseKey(db.EncryptionKey)
+		if err != nil {
+			fail("database.encryption_key: %s", err)
+		} else {
+			c.Database.Key = key
+		}
+	}
+
+	n := c.Notify
+	if n.SMTP.Enabled() {
+		if !validPort(n.SMTP.Port) {
+			fail("notify.smtp.port must be between 1 and 65535, got %d", n.SMTP.Port)
+		}
+		if _, err := mail.ParseAddress(n.SMTP.From); err != nil {
+			fail("notify.smtp.from %q is not an email address", n.SMTP.From)
+		}
+	}
+	for name, raw := range map[string]string{"notify.sms_webhook_url": n.SMSWebhookURL, "notify.whatsapp_webhook_url": n.WhatsAppWebhookURL} {
+		if raw == "" {
+			continue
+		}
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("%s must be an http or https URL", name)
+		}
+	}
+	if len(n.DefaultCountryCode) < 1 || len(n.DefaultCountryCode) > 3 || strings.Trim(n.DefaultCountryCode, "0123456789") != "" {
+		fail("notify.default_country_code must be 1 to 3 digits without +, got %q", n.DefaultCountryCode)
+	}
+	if n.PollInterval <= 0 {
+		fail("notify.poll_interval must be positive")
+	}
+	if n.MaxAttempts < 1 {
+		fail("notify.max_attempts must be at least 1")
+	}
+
+	if !slices.Contains(logLevels, c.Log.Level) {
+		fail("log.level must be one of %s, got %q", strings.Join(logLevels, ", "), c.Log.Level)
+	}
+	if !slices.Contains(logFormats, c.Log.Format) {
+		fail("log.format must be one of %s, got %q", strings.Join(logFormats, ", "), c.Log.Format)
+	}
+	if !slices.Contains(sqlLogLevels, c.Log.SQLLevel) {
+		fail("log.sql_level must be one of %s, got %q", strings.Join(sqlLogLevels, ", "), c.Log.SQLLevel)
+	}
+	if c.Log.SlowQuery < 0 {
+		fail("log.slow_query cannot be negative")
+	}
+
+	r := c.Retention
+	if r.Interval <= 0 {
+		fail("retention.interval must be positive")
+	}
+	for name, period := range map[string]int{
+		"retention.inactive_profile_years": r.InactiveProfileYears,
+		"retention.remarks_months":         r.RemarksMonths,
+		"retention.feedback_months":        r.FeedbackMonths,
+		"retention.purge_deleted_days":     r.PurgeDeletedDays,
+	} {
+		if period < 0 {
+			fail("%s cannot be negative, use 0 to keep the data", name)
+		}
+	}
+
+	slices.Sort(problems)
+	return problems
+}
+
+func validPort(port int) bool {
+	return port >= 1 && port <= 65535
+}
+
+func checkOrigin(origin string) error {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("must be an http or https origin")
+	}
+	// browsers send the origin without a trailing slash, it would never match
+	if u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+		return errors.New("must be scheme and host only, without a path or trailing slash")
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Package crypt encrypts the columns that are kept encrypted at rest with
+// AES-256-GCM under the key configured as database.encryption_key.
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// KeySize is the length of a key in bytes, keys are configured base64 encoded
+const KeySize = 32
+
+// sealed values start with the version of the format, so the scheme can
+// change without guessing what older rows hold
+const prefix = "v1:"
+
+var ErrNoKey = errors.New("no encryption key is configured")
+
+var (
+	mu   sync.RWMutex
+	aead cipher.AEAD
+)
+
+// decodes a base64 key as configured, e.g. made with openssl rand -base64 32
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.New("key is not base64")
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// sets the key values are sealed and opened with, nil removes it
+func SetKey(key []byte) error {
+	var next cipher.AEAD
+	if key != nil {
+		if len(key) != KeySize {
+			return fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return err
+		}
+		if next, err = cipher.NewGCM(block); err != nil {
+			return err
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	aead = next
+	return nil
+}
+
+// reports whether a key is set, without one nothing can be sealed or opened
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return aead != nil
+}
+
+func current() (cipher.AEAD, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if aead == nil {
+		return nil, ErrNoKey
+	}
+	return aead, nil
+}
+
+// encrypts plaintext with a random nonce, the result is text so it fits a
+// text column
+func Seal(plaintext []byte) (string, error) {
+	aead, err := current()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, nil)
+	return prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypts what Seal returned, failing when it was sealed with another key
+// or changed since
+func Open(sealed string) ([]byte, error) {
+	aead, err := current()
+	if err != nil {
+		return nil, err
+	}
+	encoded, ok := strings.CutPrefix(sealed, prefix)
+	if !ok {
+		return nil, errors.New("unknown encryption format")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < aead.NonceSize() {
+		return nil, errors.New("malformed encrypted value")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("encrypted value cannot be opened with the configured key")
+	}
+	return plaintext, nil
+}
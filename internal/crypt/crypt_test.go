@@ -0,0 +1,111 @@
+package crypt_test
+
+import (
+	"bytes"
+	"counterapp/internal/crypt"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func setKey(t *testing.T, b byte) {
+	t.Helper()
+	if err := crypt.SetKey(bytes.Repeat([]byte{b}, crypt.KeySize)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { crypt.SetKey(nil) })
+}
+
+func TestSealAndOpen(t *testing.T) {
+	setKey(t, 1)
+	plaintext := []byte(`{"Notes":"Asthma, carries an inhaler"}`)
+
+	sealed, err := crypt.Seal(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sealed, "v1:") || strings.Contains(sealed, "Asthma") {
+		t.Fatalf("sealed value %q, want a versioned ciphertext", sealed)
+	}
+	again, err := crypt.Seal(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == sealed {
+		t.Fatal("sealing twice gave the same ciphertext, want a fresh nonce each time")
+	}
+
+	opened, err := crypt.Open(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Fatalf("opened %q, want %q", opened, plaintext)
+	}
+}
+
+func TestOpenWithWrongKey(t *testing.T) {
+	setKey(t, 1)
+	sealed, err := crypt.Seal([]byte("No nuts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setKey(t, 2)
+	if _, err := crypt.Open(sealed); err == nil {
+		t.Fatal("opened a value sealed with another key")
+	}
+}
+
+func TestOpenRejectsChangedValues(t *testing.T) {
+	setKey(t, 1)
+	sealed, err := crypt.Seal([]byte("No nuts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, "v1:"))
+	data[len(data)-1] ^= 1
+
+	for _, value := range []string{
+		"v1:" + base64.StdEncoding.EncodeToString(data),
+		strings.TrimPrefix(sealed, "v1:"),
+		"v2:" + strings.TrimPrefix(sealed, "v1:"),
+		"v1:not base64",
+		"v1:" + base64.StdEncoding.EncodeToString([]byte("short")),
+	} {
+		if _, err := crypt.Open(value); err == nil {
+			t.Errorf("opened %q", value)
+		}
+	}
+}
+
+func TestWithoutKey(t *testing.T) {
+	crypt.SetKey(nil)
+	if crypt.Enabled() {
+		t.Fatal("enabled without a key")
+	}
+	if _, err := crypt.Seal([]byte("No nuts")); !errors.Is(err, crypt.ErrNoKey) {
+		t.Fatalf("seal without a key returned %v, want ErrNoKey", err)
+	}
+	if _, err := crypt.Open("v1:AAAA"); !errors.Is(err, crypt.ErrNoKey) {
+		t.Fatalf("open without a key returned %v, want ErrNoKey", err)
+	}
+	if err := crypt.SetKey([]byte("too short")); err == nil {
+		t.Fatal("set a 9 byte key")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key := bytes.Repeat([]byte{3}, crypt.KeySize)
+	parsed, err := crypt.ParseKey(" " + base64.StdEncoding.EncodeToString(key) + "\n")
+	if err != nil || !bytes.Equal(parsed, key) {
+		t.Fatalf("ParseKey = %x, %v, want %x", parsed, err, key)
+	}
+
+	for _, encoded := range []string{"", "not base64!", base64.StdEncoding.EncodeToString(key[:16])} {
+		if _, err := crypt.ParseKey(encoded); err == nil {
+			t.Errorf("parsed %q", encoded)
+		}
+	}
+}
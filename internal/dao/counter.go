@@ -2,48 +2,47 @@ package dao
 
 import (
 	"counterapp/internal/config"
+	"counterapp/internal/crypt"
+	"counterapp/internal/events"
+	"counterapp/internal/logging"
 	"counterapp/internal/model"
 	"counterapp/internal/util"
 	"errors"
-	"fmt"
+	"log/slog"
 	"time"
 
-	"github.com/google/uuid"
-	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func Connect() (*gorm.DB, error) {
-	_= godotenv.Load()
-
-	cfg := config.Load()
-	dsn := cfg.GetDSN()
-
-	return gorm.Open(postgres.Open(dsn), &gorm.Config{})
-}
+// opens the database and sizes its connection pool, statements are logged
+// to logger as configured in cfg.Log. Encrypted columns are read and written
+// with the configured key from then on.
+func Connect(cfg *config.Config, logger *slog.Logger) (*gorm.DB, error) {
+	if err := crypt.SetKey(cfg.Database.Key); err != nil {
+		return nil, err
+	}
 
-func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(&model.Profile{}, &model.Locker{}, &model.Visit{}, &model.Schedule{}, &model.SevaType{}, &model.StayArea{}, &model.Feedback{})
-}
+	db, err := gorm.Open(postgres.Open(cfg.Database.DSN()), &gorm.Config{
+		TranslateError: true,
+		Logger:         logging.NewGormLogger(logger, cfg.Log),
+	})
+	if err != nil {
+		return nil, err
+	}
 
-type GetProfilesDataResponse struct {
-	ID            string
-	Name          string
-	PhoneNumber   string
-	Email         string
-	Gender        string
-	Category      string
-	IsBlocked     bool
-	Remarks       string
-	ActiveVisitID *string
-	DepartureDate *string
-	StayArea      *string
-	Status        *string
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
+	return db, nil
 }
 
-func GetProfilesData(db *gorm.DB) ([]GetProfilesDataResponse, error) {
-	sql := `
+const profilesDataSQL = `
 	SELECT    
 	    p.id,
 		p.NAME,
@@ -63,45 +62,53 @@ LEFT JOIN LATERAL
 			select   *
 			from     visits v
 			WHERE    v.profile_id = p.id AND
-			v.status = 'checked-in'
+			v.status = 'checked-in' AND
+			v.deleted_at IS NULL
 ) v
 ON true
 LEFT JOIN stay_areas sa ON v.stay_area_id = sa.id
+WHERE p.deleted_at IS NULL
 	`
+
+func GetProfilesData(db *gorm.DB) ([]model.ProfileOverview, error) {
 	var result []sqlGetProfileData
-	err := db.Raw(sql).Scan(&result).Error
+	err := db.Raw(profilesDataSQL).Scan(&result).Error
 
 	if err != nil {
 		return nil, err
 	}
 
-	var profilesData []GetProfilesDataResponse
+	var profilesData []model.ProfileOverview
 	for _, r := range result {
-		response := GetProfilesDataResponse{
-			ID:            r.ID,
-			Name:          r.Name,
-			PhoneNumber:   r.PhoneNumber,
-			Email:         r.Email,
-			Category:      string(r.Category),
-			IsBlocked:     r.IsBlocked,
-			Remarks:       r.Remarks,
-			StayArea:      r.StayArea,
-			ActiveVisitID: r.ActiveVisitID,
-		}
+		profilesData = append(profilesData, newProfilesDataResponse(r))
+	}
 
-		if r.DepartureDate != nil {
-			formatted := util.FormatDate(*r.DepartureDate)
-			response.DepartureDate = &formatted
-		}
-		if r.Status != nil {
-			statusPtr := string(*r.Status)
-			response.Status = &statusPtr
-		}
+	return profilesData, nil
+}
 
-		profilesData = append(profilesData, response)
+func newProfilesDataResponse(r sqlGetProfileData) model.ProfileOverview {
+	response := model.ProfileOverview{
+		ID:            r.ID,
+		Name:          r.Name,
+		PhoneNumber:   r.PhoneNumber,
+		Email:         r.Email,
+		Gender:        string(r.Gender),
+		Category:      string(r.Category),
+		IsBlocked:     r.IsBlocked,
+		Remarks:       r.Remarks,
+		StayArea:      r.StayArea,
+		ActiveVisitID: r.ActiveVisitID,
 	}
 
-	return profilesData, nil
+	if r.DepartureDate != nil {
+		formatted := util.FormatDate(*r.DepartureDate)
+		response.DepartureDate = &formatted
+	}
+	if r.Status != nil {
+		statusPtr := string(*r.Status)
+		response.Status = &statusPtr
+	}
+	return response
 }
 
 func CreateProfile(db *gorm.DB, profile *model.Profile) (*model.Profile, error) {
@@ -112,23 +119,37 @@ func CreateProfile(db *gorm.DB, profile *model.Profile) (*model.Profile, error)
 	return profile, result.Error
 }
 
-type ProfileUpdate struct {
-	Name        *string
-	PhoneNumber *string
-	Gender      *model.Gender
-	Category    *model.Category
-	IsBlocked   *bool
-	Remarks     *string
-}
+func UpdateProfile(db *gorm.DB, profileID string, updates *model.ProfileUpdate) (*model.Profile, error) {
+	var updatedProfile model.Profile
+	err := transactionWithEvents(db, func(tx *gorm.DB, emit emitFunc) error {
+		var previous model.Profile
+		if err := tx.First(&previous, "id = ?", profileID).Error; err != nil {
+			return err
+		}
 
-func UpdateProfile(db *gorm.DB, profileID string, updates *ProfileUpdate) (*model.Profile, error) {
-	result := db.Model(&model.Profile{}).Where("id = ?", profileID).Updates(updates)
-	if result.Error != nil {
-		return nil, result.Error
-	}
+		result := tx.Model(&model.Profile{}).Where("id = ?", profileID).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if updates.EmergencyContacts != nil {
+			contacts := model.Profile{EmergencyContacts: *updates.EmergencyContacts}
+			err := tx.Model(&model.Profile{}).Where("id = ?", profileID).Select("EmergencyContacts").Updates(&contacts).Error
+			if err != nil {
+				return err
+			}
+		}
 
-	var updatedProfile model.Profile
-	db.First(&updatedProfile, "id = ?", profileID)
+		if err := tx.First(&updatedProfile, "id = ?", profileID).Error; err != nil {
+			return err
+		}
+		if !previous.IsBlocked && updatedProfile.IsBlocked {
+			return emit(events.New(events.ProfileBlocked, events.NewProfileData(&updatedProfile)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return &updatedProfile, nil
 }
 
@@ -141,11 +162,20 @@ func GetVisitsByProfileID(db *gorm.DB, profileID string) ([]model.Visit, error)
 	return visits, nil
 }
 
+// returns checked-in visits whose departure date is before today
+func GetOverdueVisits(db *gorm.DB, today time.Time) ([]model.Visit, error) {
+	var visits []model.Visit
+	result := db.Where("status = ? AND departure_date < ?", model.StatusCheckedIn, today).Order("departure_date").Find(&visits)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return visits, nil
+}
+
 func GetAllLockers(db *gorm.DB) ([]model.Locker, error) {
 	var lockers []model.Locker
 	result := db.Find(&lockers)
 	if result.Error != nil {
-		fmt.Print("error while fetching lockers")
 		return nil, result.Error
 	}
 
@@ -167,47 +197,104 @@ func GetScheduleForDateRange(db *gorm.DB, startDate time.Time, endDate time.Time
 	return schedules, nil
 }
 
+// returns gorm.ErrRecordNotFound when no profile has the ID
 func GetProfileByID(db *gorm.DB, profileID string) (*model.Profile, error) {
 	var profile model.Profile
-	result := db.Find(&profile, "id = ?", profileID)
+	result := db.First(&profile, "id = ?", profileID)
 	if result.Error != nil {
-		fmt.Printf("error while fetching profile for id: %s", profileID)
 		return nil, result.Error
 	}
 	return &profile, nil
 }
 
-type UpdateVisitRequest struct {
-	DepartureDate *time.Time
-	StayAreaID    *uuid.UUID
-	Status        *model.ProfileStatus
-	LockerID      *uuid.UUID
-	Remarks       *string
+// like GetProfileByID, finding deleted profiles too
+func GetProfileByIDWithDeleted(db *gorm.DB, profileID string) (*model.Profile, error) {
+	return GetProfileByID(db.Unscoped(), profileID)
 }
 
-func UpdateVisit(db *gorm.DB, visitID string, req UpdateVisitRequest) (*model.Visit, error) {
-	result := db.Model(&model.Visit{}).Where("id = ?", visitID).Updates(req)
+// returns nil without error when no profile has the email
+func GetProfileByEmail(db *gorm.DB, email string) (*model.Profile, error) {
+	var profile model.Profile
+	result := db.Where("LOWER(email) = LOWER(?)", email).Limit(1).Find(&profile)
 	if result.Error != nil {
 		return nil, result.Error
 	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &profile, nil
+}
+
+func UpdateVisit(db *gorm.DB, visitID string, req model.UpdateVisitRequest) (*model.Visit, error) {
 	var updatedVisit *model.Visit
-	db.First(&updatedVisit, "id = ?", visitID)
+	err := transactionWithEvents(db, func(tx *gorm.DB, emit emitFunc) error {
+		var previous model.Visit
+		if err := tx.First(&previous, "id = ?", visitID).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&model.Visit{}).Where("id = ?", visitID).Updates(req)
+		if result.Error != nil {
+			return result.Error
+		}
+		if req.Status != nil && *req.Status != previous.Status {
+			var checkedOutAt *time.Time
+			if *req.Status == model.StatusCheckedOut {
+				now := time.Now()
+				checkedOutAt = &now
+			}
+			if err := tx.Model(&model.Visit{}).Where("id = ?", visitID).Update("checked_out_at", checkedOutAt).Error; err != nil {
+				return err
+			}
+			if *req.Status == model.StatusCheckedIn && previous.CheckedInAt == nil {
+				if err := tx.Model(&model.Visit{}).Where("id = ?", visitID).Update("checked_in_at", time.Now()).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := tx.First(&updatedVisit, "id = ?", visitID).Error; err != nil {
+			return err
+		}
+		return emitVisitChanges(emit, &previous, updatedVisit)
+	})
+	if err != nil {
+		return nil, err
+	}
 	return updatedVisit, nil
 }
 
-type AddVisitRequest struct {
-	ProfileID     uuid.UUID
-	ArrivalDate   time.Time
-	DepartureDate *time.Time
-	StayAreaID    uuid.UUID
-	Status        model.ProfileStatus
-	LockerID      *uuid.UUID
-	Remarks       *string
+func emitVisitChanges(emit emitFunc, previous *model.Visit, visit *model.Visit) error {
+	data := events.NewVisitData(visit)
+
+	if previous.Status != visit.Status {
+		var err error
+		switch visit.Status {
+		case model.StatusCheckedIn:
+			err = emit(events.New(events.VisitCheckedIn, data))
+		case model.StatusCheckedOut:
+			err = emit(events.New(events.VisitCheckedOut, data))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if previous.StayAreaID != visit.StayAreaID {
+		if err := emit(events.New(events.VisitStayAreaChanged, data)); err != nil {
+			return err
+		}
+	}
+	if visit.LockerID != nil && (previous.LockerID == nil || *previous.LockerID != *visit.LockerID) {
+		if err := emit(events.New(events.VisitLockerAssigned, data)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func AddVisit(db *gorm.DB, req AddVisitRequest) (*model.Visit, error) {
+func AddVisit(db *gorm.DB, req model.AddVisitRequest) (*model.Visit, error) {
 	var visit *model.Visit
-	err := db.Transaction(func(tx *gorm.DB) error {
+	err := transactionWithEvents(db, func(tx *gorm.DB, emit emitFunc) error {
 		visit = &model.Visit{
 			ProfileID:     req.ProfileID,
 			ArrivalDate:   req.ArrivalDate,
@@ -217,7 +304,17 @@ func AddVisit(db *gorm.DB, req AddVisitRequest) (*model.Visit, error) {
 			LockerID:      req.LockerID,
 			Remarks:       req.Remarks,
 		}
-		return tx.Create(&visit).Error
+		if visit.Status == model.StatusCheckedIn {
+			now := time.Now()
+			visit.CheckedInAt = &now
+		}
+		if err := tx.Create(&visit).Error; err != nil {
+			return err
+		}
+		if visit.Status == model.StatusCheckedIn {
+			return emit(events.New(events.VisitCheckedIn, events.NewVisitData(visit)))
+		}
+		return nil
 	})
 	if err != nil {
 		return nil, err
@@ -225,15 +322,39 @@ func AddVisit(db *gorm.DB, req AddVisitRequest) (*model.Visit, error) {
 	return visit, nil
 }
 
+// returns gorm.ErrRecordNotFound when no visit has the ID
 func GetVisitByID(db *gorm.DB, visitID string) (*model.Visit, error) {
 	var visit model.Visit
-	result := db.Preload("StayArea").Preload("Locker").Find(&visit, "id = ?", visitID)
+	result := db.Preload("StayArea").Preload("Locker").First(&visit, "id = ?", visitID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &visit, nil
+}
+
+// returns the visit with its profile, stay area and locker, or
+// gorm.ErrRecordNotFound when no visit has the ID
+func GetVisitDetailsByID(db *gorm.DB, visitID string) (*model.Visit, error) {
+	var visit model.Visit
+	result := db.Preload("Profile").Preload("StayArea").Preload("Locker").First(&visit, "id = ?", visitID)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &visit, nil
 }
 
+func GetVisitByProfileAndArrivalDate(db *gorm.DB, profileID string, arrivalDate time.Time) (*model.Visit, error) {
+	var visit model.Visit
+	result := db.Limit(1).Find(&visit, "profile_id = ? AND arrival_date = ?", profileID, arrivalDate)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &visit, nil
+}
+
 func GetScheduleByProfileAndDate(db *gorm.DB, profileID string, date time.Time) (*model.Schedule, error) {
 	var sch model.Schedule
 	result := db.Find(&sch, "profile_id = ? AND date = ?", profileID, date)
@@ -246,15 +367,7 @@ func GetScheduleByProfileAndDate(db *gorm.DB, profileID string, date time.Time)
 	return &sch, nil
 }
 
-type AddScheduleRequest struct {
-	ProfileID  uuid.UUID
-	VisitID    uuid.UUID
-	SevaTypeID uuid.UUID
-	Location   *string
-	Date       time.Time
-}
-
-func AddSchedule(db *gorm.DB, req AddScheduleRequest) (*model.Schedule, error) {
+func AddSchedule(db *gorm.DB, req model.AddScheduleRequest) (*model.Schedule, error) {
 	schedule := &model.Schedule{
 		ProfileID:  req.ProfileID,
 		VisitID:    req.VisitID,
@@ -262,9 +375,14 @@ func AddSchedule(db *gorm.DB, req AddScheduleRequest) (*model.Schedule, error) {
 		Location:   req.Location,
 		Date:       req.Date,
 	}
-	result := db.Create(schedule)
-	if result.Error != nil {
-		return nil, result.Error
+	err := transactionWithEvents(db, func(tx *gorm.DB, emit emitFunc) error {
+		if err := tx.Create(schedule).Error; err != nil {
+			return err
+		}
+		return emit(events.New(events.ScheduleCreated, events.NewScheduleData(schedule)))
+	})
+	if err != nil {
+		return nil, err
 	}
 	return schedule, nil
 }
@@ -287,15 +405,7 @@ func GetFeedbacksForProfile(db *gorm.DB, profileID string) ([]model.Feedback, er
 	return feedbacks, nil
 }
 
-type AddFeedbackRequest struct {
-	ProfileID uuid.UUID
-	VisitID   *uuid.UUID
-	Content   string
-	Type      model.FeedbackType
-	CreatedBy *string
-}
-
-func AddFeedback(db *gorm.DB, req AddFeedbackRequest) (*model.Feedback, error) {
+func AddFeedback(db *gorm.DB, req model.AddFeedbackRequest) (*model.Feedback, error) {
 	feedback := &model.Feedback{
 		ProfileID: req.ProfileID,
 		VisitID:   req.VisitID,
@@ -337,12 +447,7 @@ func GetSevaTypeByID(db *gorm.DB, id string) (*model.SevaType, error) {
 	return &sevaType, nil
 }
 
-type AddSevaTypeRequest struct {
-	Name        string
-	Description *string
-}
-
-func AddSevaType(db *gorm.DB, req AddSevaTypeRequest) (*model.SevaType, error) {
+func AddSevaType(db *gorm.DB, req model.AddSevaTypeRequest) (*model.SevaType, error) {
 	sevaType := &model.SevaType{
 		Name:        req.Name,
 		Description: req.Description,
@@ -382,12 +487,7 @@ func GetStayAreaByName(db *gorm.DB, name string) (*model.StayArea, error) {
 	return &stayArea, nil
 }
 
-type AddStayAreaRequest struct {
-	Name     string
-	Capacity int
-}
-
-func AddStayArea(db *gorm.DB, req AddStayAreaRequest) (*model.StayArea, error) {
+func AddStayArea(db *gorm.DB, req model.AddStayAreaRequest) (*model.StayArea, error) {
 	stayArea := &model.StayArea{
 		Name:     req.Name,
 		Capacity: req.Capacity,
@@ -399,14 +499,7 @@ func AddStayArea(db *gorm.DB, req AddStayAreaRequest) (*model.StayArea, error) {
 	return stayArea, nil
 }
 
-type GetStayAreaOccupancyResponse struct {
-	StayAreaID           string
-	StayName             string
-	StayCapacity         int
-	CurrentOccupiedCount int
-}
-
-func GetAllStayAreasWithOccupancy(db *gorm.DB) ([]GetStayAreaOccupancyResponse, error) {
+func GetAllStayAreasWithOccupancy(db *gorm.DB) ([]model.StayAreaOccupancy, error) {
 	sql := `
 		SELECT
 			sa.id,
@@ -414,7 +507,7 @@ func GetAllStayAreasWithOccupancy(db *gorm.DB) ([]GetStayAreaOccupancyResponse,
 			sa.capacity,
 			COUNT(v.id) as occupied_count
 		FROM stay_areas sa
-		LEFT JOIN visits v ON v.stay_area_id = sa.id AND v.status = 'checked-in'
+		LEFT JOIN visits v ON v.stay_area_id = sa.id AND v.status = 'checked-in' AND v.deleted_at IS NULL
 		GROUP BY sa.id, sa.name, sa.capacity
 		ORDER BY sa.name
 	`
@@ -425,9 +518,9 @@ func GetAllStayAreasWithOccupancy(db *gorm.DB) ([]GetStayAreaOccupancyResponse,
 		return nil, err
 	}
 
-	responses := make([]GetStayAreaOccupancyResponse, 0, len(results))
+	responses := make([]model.StayAreaOccupancy, 0, len(results))
 	for _, result := range results {
-		responses = append(responses, GetStayAreaOccupancyResponse{
+		responses = append(responses, model.StayAreaOccupancy{
 			StayAreaID:           result.ID,
 			StayName:             result.Name,
 			StayCapacity:         result.Capacity,
@@ -0,0 +1,173 @@
+package dao
+
+import (
+	"counterapp/internal/events"
+	"counterapp/internal/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrParentDeleted is returned when restoring a visit of a deleted profile,
+// the profile has to be restored first
+var ErrParentDeleted = errors.New("parent record is deleted")
+
+// soft deletes the profile with its visits, schedules and feedback. They
+// share one deletion time, so RestoreProfile brings back exactly these rows
+// and not the ones deleted on their own before.
+func DeleteProfile(db *gorm.DB, profileID string) error {
+	return transactionWithEvents(db, func(tx *gorm.DB, emit emitFunc) error {
+		var profile model.Profile
+		if err := tx.First(&profile, "id = ?", profileID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, rows := range []any{&model.Schedule{}, &model.Feedback{}, &model.Visit{}} {
+			if err := tx.Model(rows).Where("profile_id = ?", profileID).Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&profile).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return emit(events.New(events.ProfileDeleted, events.NewProfileData(&profile)))
+	})
+}
+
+// undoes DeleteProfile. Returns gorm.ErrRecordNotFound unless the profile is
+// deleted and gorm.ErrDuplicatedKey when another profile has taken its email.
+func RestoreProfile(db *gorm.DB, profileID string) (*model.Profile, error) {
+	var restored model.Profile
+	err := transactionWithEvents(db, func(tx *gorm.DB, emit emitFunc) error {
+		var profile model.Profile
+		if err := findDeleted(tx, &profile, profileID); err != nil {
+			return err
+		}
+		deletedAt := profile.DeletedAt.Time
+
+		if err := tx.Unscoped().Model(&profile).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		for _, rows := range []any{&model.Visit{}, &model.Schedule{}, &model.Feedback{}} {
+			err := tx.Unscoped().Model(rows).
+				Where("profile_id = ? AND deleted_at = ?", profileID, deletedAt).
+				Update("deleted_at", nil).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.First(&restored, "id = ?", profileID).Error; err != nil {
+			return err
+		}
+		return emit(events.New(events.ProfileRestored, events.NewProfileData(&restored)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &restored, nil
+}
+
+// removes a deleted profile with all its visits, schedules and feedback for
+// good. Returns gorm.ErrRecordNotFound unless the profile is deleted.
+func PurgeProfile(db *gorm.DB, profileID string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := findDeleted(tx, &model.Profile{}, profileID); err != nil {
+			return err
+		}
+		for _, rows := range []any{&model.Schedule{}, &model.Feedback{}, &model.Visit{}} {
+			if err := tx.Unscoped().Where("profile_id = ?", profileID).Delete(rows).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(&model.Profile{}, "id = ?", profileID).Error
+	})
+}
+
+// soft deletes the visit with its schedules and the feedback given on it,
+// sharing one deletion time like DeleteProfile
+func DeleteVisit(db *gorm.DB, visitID string) error {
+	return transactionWithEvents(db, func(tx *gorm.DB, emit emitFunc) error {
+		var visit model.Visit
+		if err := tx.First(&visit, "id = ?", visitID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, rows := range []any{&model.Schedule{}, &model.Feedback{}} {
+			if err := tx.Model(rows).Where("visit_id = ?", visitID).Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&visit).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return emit(events.New(events.VisitDeleted, events.NewVisitData(&visit)))
+	})
+}
+
+// undoes DeleteVisit. Returns gorm.ErrRecordNotFound unless the visit is
+// deleted, ErrParentDeleted when its profile is deleted and
+// gorm.ErrDuplicatedKey when the profile has since checked in again or has
+// a seva on one of the restored days.
+func RestoreVisit(db *gorm.DB, visitID string) (*model.Visit, error) {
+	var restored model.Visit
+	err := transactionWithEvents(db, func(tx *gorm.DB, emit emitFunc) error {
+		var visit model.Visit
+		if err := findDeleted(tx, &visit, visitID); err != nil {
+			return err
+		}
+		deletedAt := visit.DeletedAt.Time
+		result := tx.Limit(1).Find(&model.Profile{}, "id = ?", visit.ProfileID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrParentDeleted
+		}
+
+		if err := tx.Unscoped().Model(&visit).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		for _, rows := range []any{&model.Schedule{}, &model.Feedback{}} {
+			err := tx.Unscoped().Model(rows).
+				Where("visit_id = ? AND deleted_at = ?", visitID, deletedAt).
+				Update("deleted_at", nil).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Preload("StayArea").Preload("Locker").First(&restored, "id = ?", visitID).Error; err != nil {
+			return err
+		}
+		return emit(events.New(events.VisitRestored, events.NewVisitData(&restored)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &restored, nil
+}
+
+// removes a deleted visit with its schedules and feedback for good. Returns
+// gorm.ErrRecordNotFound unless the visit is deleted.
+func PurgeVisit(db *gorm.DB, visitID string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := findDeleted(tx, &model.Visit{}, visitID); err != nil {
+			return err
+		}
+		for _, rows := range []any{&model.Schedule{}, &model.Feedback{}} {
+			if err := tx.Unscoped().Where("visit_id = ?", visitID).Delete(rows).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(&model.Visit{}, "id = ?", visitID).Error
+	})
+}
+
+// returns gorm.ErrRecordNotFound unless the row with the ID is soft deleted
+func findDeleted(tx *gorm.DB, row any, id string) error {
+	return tx.Unscoped().Where("deleted_at IS NOT NULL").First(row, "id = ?", id).Error
+}
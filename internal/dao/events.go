@@ -0,0 +1,116 @@
+package dao
+
+import (
+	"context"
+	"counterapp/internal/events"
+	"counterapp/internal/model"
+
+	"gorm.io/gorm"
+)
+
+var publisher events.Publisher
+
+// SetEventPublisher sets where events are published once the transaction
+// that emitted them commits. Nothing is published when it is not set.
+func SetEventPublisher(p events.Publisher) {
+	publisher = p
+}
+
+type emitFunc func(evt events.Event) error
+
+// runs fn in a transaction. Events passed to emit get their webhook
+// deliveries recorded in the same transaction and are published after it
+// commits, followed by an occupancy update when any of them moved people.
+func transactionWithEvents(db *gorm.DB, fn func(tx *gorm.DB, emit emitFunc) error) error {
+	var emitted []events.Event
+	err := db.Transaction(func(tx *gorm.DB) error {
+		emitted = nil
+		return fn(tx, func(evt events.Event) error {
+			if err := recordWebhookDeliveries(tx, evt); err != nil {
+				return err
+			}
+			emitted = append(emitted, evt)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	// inside Transaction the events wait for the outer commit
+	if pending, ok := db.Statement.Context.Value(pendingEventsKey{}).(*pendingEvents); ok {
+		pending.events = append(pending.events, emitted...)
+		return nil
+	}
+	publishEvents(db, emitted)
+	return nil
+}
+
+type pendingEventsKey struct{}
+
+// the events emitted inside a Transaction, published once it commits
+type pendingEvents struct {
+	events []events.Event
+}
+
+// Transaction runs fn in a transaction for callers combining several dao
+// calls. The events those calls emit are published after the transaction
+// commits rather than after each call, and dropped when it rolls back.
+func Transaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	if _, ok := db.Statement.Context.Value(pendingEventsKey{}).(*pendingEvents); ok {
+		return db.Transaction(fn)
+	}
+
+	pending := &pendingEvents{}
+	ctx := context.WithValue(db.Statement.Context, pendingEventsKey{}, pending)
+	if err := db.WithContext(ctx).Transaction(fn); err != nil {
+		return err
+	}
+	publishEvents(db, pending.events)
+	return nil
+}
+
+func publishEvents(db *gorm.DB, emitted []events.Event) {
+	if publisher == nil || len(emitted) == 0 {
+		return
+	}
+
+	occupancyChanged := false
+	for _, evt := range emitted {
+		publisher.Publish(evt)
+		occupancyChanged = occupancyChanged || evt.Type.AffectsOccupancy()
+	}
+	if !occupancyChanged {
+		return
+	}
+
+	occupancy, err := GetOccupancyEventData(db)
+	if err != nil {
+		db.Logger.Error(context.Background(), "unable to publish occupancy update: %v", err)
+		return
+	}
+	publisher.Publish(events.New(events.OccupancyUpdated, occupancy))
+}
+
+// returns the occupancy of every stay area in the shape streamed to clients
+func GetOccupancyEventData(db *gorm.DB) ([]events.OccupancyData, error) {
+	stayAreas, err := GetAllStayAreasWithOccupancy(db)
+	if err != nil {
+		return nil, err
+	}
+	return OccupancyEventData(stayAreas), nil
+}
+
+func OccupancyEventData(stayAreas []model.StayAreaOccupancy) []events.OccupancyData {
+	occupancy := make([]events.OccupancyData, 0, len(stayAreas))
+	for _, sa := range stayAreas {
+		occupancy = append(occupancy, events.OccupancyData{
+			StayAreaID:           sa.StayAreaID,
+			StayName:             sa.StayName,
+			Capacity:             sa.StayCapacity,
+			CurrentOccupiedCount: sa.CurrentOccupiedCount,
+			Available:            sa.StayCapacity - sa.CurrentOccupiedCount,
+		})
+	}
+	return occupancy
+}
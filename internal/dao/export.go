@@ -0,0 +1,95 @@
+package dao
+
+import (
+	"counterapp/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// streams schedules in the date range ordered by date, seva type and name
+// without loading the whole result set in memory
+func StreamSchedulesForDateRange(db *gorm.DB, startDate time.Time, endDate time.Time, fn func(model.ScheduleExportRow) error) error {
+	sql := `
+		SELECT
+			s.id,
+			s.date,
+			p.name AS profile_name,
+			p.email,
+			p.phone_number,
+			p.gender,
+			p.category,
+			st.name AS seva_type,
+			s.location,
+			s.notes,
+			sa.name AS stay_area,
+			l.locker_number,
+			l.section AS locker_section,
+			v.arrival_date,
+			v.departure_date
+		FROM schedules s
+		JOIN profiles p ON p.id = s.profile_id
+		JOIN seva_types st ON st.id = s.seva_type_id
+		JOIN visits v ON v.id = s.visit_id
+		LEFT JOIN stay_areas sa ON sa.id = v.stay_area_id
+		LEFT JOIN lockers l ON l.id = v.locker_id
+		WHERE s.date >= ? AND s.date <= ? AND s.deleted_at IS NULL
+		ORDER BY s.date, st.name, p.name
+	`
+	return streamRows(db.Raw(sql, startDate, endDate), fn)
+}
+
+// streams currently checked-in residents grouped by stay area, an empty
+// stayAreaID returns residents of every stay area
+func StreamCheckedInResidents(db *gorm.DB, stayAreaID string, fn func(model.ResidentExportRow) error) error {
+	sql := `
+		SELECT
+			v.id AS visit_id,
+			p.id AS profile_id,
+			p.name,
+			p.email,
+			p.phone_number,
+			p.gender,
+			p.category,
+			sa.id AS stay_area_id,
+			sa.name AS stay_area,
+			l.locker_number,
+			l.section AS locker_section,
+			v.arrival_date,
+			v.departure_date,
+			v.remarks
+		FROM visits v
+		JOIN profiles p ON p.id = v.profile_id
+		JOIN stay_areas sa ON sa.id = v.stay_area_id
+		LEFT JOIN lockers l ON l.id = v.locker_id
+		WHERE v.status = 'checked-in' AND v.deleted_at IS NULL AND (? = '' OR v.stay_area_id::text = ?)
+		ORDER BY sa.name, p.name
+	`
+	return streamRows(db.Raw(sql, stayAreaID, stayAreaID), fn)
+}
+
+// streams the same rows as GetProfilesData
+func StreamProfilesData(db *gorm.DB, fn func(model.ProfileOverview) error) error {
+	return streamRows(db.Raw(profilesDataSQL+" ORDER BY p.name"), func(r sqlGetProfileData) error {
+		return fn(newProfilesDataResponse(r))
+	})
+}
+
+func streamRows[T any](query *gorm.DB, fn func(T) error) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err := query.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
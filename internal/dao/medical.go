@@ -0,0 +1,50 @@
+package dao
+
+import (
+	"counterapp/internal/crypt"
+	"counterapp/internal/model"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// fails when medical info is stored that the configured key cannot open, so
+// a missing or wrong key stops the server at startup instead of failing
+// every request that reads a profile
+func CheckEncryptionKey(db *gorm.DB) error {
+	var sealed []string
+	err := db.Unscoped().Model(&model.Profile{}).Where("medical IS NOT NULL").Limit(1).Pluck("medical", &sealed).Error
+	if err != nil {
+		return err
+	}
+	if len(sealed) == 0 {
+		return nil
+	}
+	if _, err := crypt.Open(sealed[0]); err != nil {
+		if errors.Is(err, crypt.ErrNoKey) {
+			return errors.New("profiles hold encrypted medical info, set database.encryption_key")
+		}
+		return fmt.Errorf("database.encryption_key: %w", err)
+	}
+	return nil
+}
+
+// replaces the medical info of a profile, nil clears it
+func SetProfileMedical(db *gorm.DB, profileID string, medical *model.MedicalInfo) (*model.Profile, error) {
+	var updated model.Profile
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Profile{}).Where("id = ?", profileID).Select("Medical").Updates(&model.Profile{Medical: medical})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.First(&updated, "id = ?", profileID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
@@ -0,0 +1,102 @@
+package dao
+
+import (
+	"counterapp/internal/model"
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CheckedInCount struct {
+	StayArea string
+	Gender   string
+	Count    int
+}
+
+type StayAreaCapacity struct {
+	Name     string
+	Capacity int
+}
+
+type LockerSectionCount struct {
+	Section string
+	Free    int
+	Total   int
+}
+
+// the domain numbers exposed as metrics, read from one snapshot of the
+// database so they agree with each other. Stay areas are keyed by name, the
+// label users see, areas sharing a name are added up.
+type MetricsSnapshot struct {
+	CheckedIn []CheckedInCount
+	StayAreas []StayAreaCapacity
+	Lockers   []LockerSectionCount
+	Overdue   int64
+
+	CheckInsTotal  int64
+	CheckOutsTotal int64
+	SchedulesTotal int64
+
+	CheckInsToday  int64
+	CheckOutsToday int64
+	SchedulesToday int64
+}
+
+// today is the calendar day visits are overdue after, startOfToday the
+// instant the day began in the configured time zone
+func GetMetricsSnapshot(db *gorm.DB, today time.Time, startOfToday time.Time) (*MetricsSnapshot, error) {
+	var snapshot MetricsSnapshot
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("visits").
+			Select("stay_areas.name AS stay_area, profiles.gender AS gender, COUNT(*) AS count").
+			Joins("JOIN stay_areas ON stay_areas.id = visits.stay_area_id").
+			Joins("JOIN profiles ON profiles.id = visits.profile_id").
+			Where("visits.status = ? AND visits.deleted_at IS NULL", model.StatusCheckedIn).
+			Group("stay_areas.name, profiles.gender").
+			Scan(&snapshot.CheckedIn).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.StayArea{}).Select("name, SUM(capacity) AS capacity").Group("name").Scan(&snapshot.StayAreas).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&model.Locker{}).
+			Select("section, COUNT(*) FILTER (WHERE NOT is_occupied) AS free, COUNT(*) AS total").
+			Group("section").
+			Scan(&snapshot.Lockers).Error
+		if err != nil {
+			return err
+		}
+
+		// what happened is counted with deleted rows too, so the totals
+		// do not go down when a visit is deleted. They still go down when
+		// retention purges rows, which is why they are exported as gauges.
+		happened := tx.Unscoped().Session(&gorm.Session{})
+		counts := []struct {
+			dst   *int64
+			query *gorm.DB
+		}{
+			{&snapshot.Overdue, tx.Model(&model.Visit{}).Where("status = ? AND departure_date < ?", model.StatusCheckedIn, today)},
+			{&snapshot.CheckInsTotal, happened.Model(&model.Visit{}).Where("checked_in_at IS NOT NULL")},
+			{&snapshot.CheckOutsTotal, happened.Model(&model.Visit{}).Where("checked_out_at IS NOT NULL")},
+			{&snapshot.SchedulesTotal, happened.Model(&model.Schedule{})},
+			{&snapshot.CheckInsToday, happened.Model(&model.Visit{}).Where("checked_in_at >= ?", startOfToday)},
+			{&snapshot.CheckOutsToday, happened.Model(&model.Visit{}).Where("checked_out_at >= ?", startOfToday)},
+			{&snapshot.SchedulesToday, happened.Model(&model.Schedule{}).Where("created_at >= ?", startOfToday)},
+		}
+		for _, c := range counts {
+			if err := c.query.Count(c.dst).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
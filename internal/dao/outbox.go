@@ -0,0 +1,158 @@
+package dao
+
+import (
+	"counterapp/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// inserts the message unless one with the same DedupKey already exists
+func CreateOutboxMessage(db *gorm.DB, msg *model.OutboxMessage) error {
+	if msg.NextAttemptAt.IsZero() {
+		msg.NextAttemptAt = time.Now()
+	}
+	if msg.Status == "" {
+		msg.Status = model.DeliveryPending
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(msg).Error
+}
+
+// marks up to limit due messages as sending until leaseUntil and returns
+// them, so that several app machines can run senders against the same outbox
+// without sending twice. The claim commits before anything is sent, a
+// message whose lease ran out because its sender stopped is claimed again.
+func ClaimDueOutboxMessages(db *gorm.DB, now, leaseUntil time.Time, limit int) ([]model.OutboxMessage, error) {
+	var messages []model.OutboxMessage
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND next_attempt_at <= ?", []model.DeliveryStatus{model.DeliveryPending, model.DeliverySending}, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&messages).Error
+		if err != nil || len(messages) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(messages))
+		for i := range messages {
+			ids[i] = messages[i].ID
+			messages[i].Status = model.DeliverySending
+			messages[i].NextAttemptAt = leaseUntil
+		}
+		return tx.Model(&model.OutboxMessage{}).Where("id IN ?", ids).Updates(map[string]any{
+			"status":          model.DeliverySending,
+			"next_attempt_at": leaseUntil,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// the result of a send is only recorded while the message is still claimed,
+// so a sender that outlived its lease does not overwrite a newer attempt
+func claimedOutboxMessage(db *gorm.DB, id string) *gorm.DB {
+	return db.Model(&model.OutboxMessage{}).Where("id = ? AND status = ?", id, model.DeliverySending)
+}
+
+func MarkOutboxMessageSent(db *gorm.DB, id string, sentAt time.Time) error {
+	return claimedOutboxMessage(db, id).Updates(map[string]any{
+		"status":     model.DeliverySent,
+		"attempts":   gorm.Expr("attempts + 1"),
+		"sent_at":    sentAt,
+		"last_error": nil,
+	}).Error
+}
+
+// records a failed attempt, the message stays pending until nextAttemptAt
+// unless failed is set
+func MarkOutboxMessageAttemptFailed(db *gorm.DB, id string, sendErr string, nextAttemptAt time.Time, failed bool) error {
+	status := model.DeliveryPending
+	if failed {
+		status = model.DeliveryFailed
+	}
+	return claimedOutboxMessage(db, id).Updates(map[string]any{
+		"status":          status,
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      sendErr,
+		"next_attempt_at": nextAttemptAt,
+	}).Error
+}
+
+// puts a failed message back in the queue with a fresh set of attempts
+func RetryOutboxMessage(db *gorm.DB, id string) (*model.OutboxMessage, error) {
+	result := db.Model(&model.OutboxMessage{}).
+		Where("id = ? AND status = ?", id, model.DeliveryFailed).
+		Updates(map[string]any{
+			"status":          model.DeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var msg model.OutboxMessage
+	if err := db.First(&msg, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// lists the most recent outbox messages, optionally filtered by status
+func GetOutboxMessages(db *gorm.DB, status string, limit int) ([]model.OutboxMessage, error) {
+	var messages []model.OutboxMessage
+	query := db.Order("created_at DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+type DepartingVisit struct {
+	VisitID             string
+	ProfileID           string
+	Name                string
+	Email               string
+	PhoneNumber         string
+	NotificationChannel model.NotificationChannel
+	NotificationsOptOut bool
+	StayArea            string
+	DepartureDate       time.Time
+}
+
+// returns checked-in visits whose departure date is the given day
+func GetCheckedInVisitsDepartingOn(db *gorm.DB, date time.Time) ([]DepartingVisit, error) {
+	sql := `
+		SELECT
+			v.id AS visit_id,
+			p.id AS profile_id,
+			p.name,
+			p.email,
+			p.phone_number,
+			p.notification_channel,
+			p.notifications_opt_out,
+			sa.name AS stay_area,
+			v.departure_date
+		FROM visits v
+		JOIN profiles p ON p.id = v.profile_id
+		JOIN stay_areas sa ON sa.id = v.stay_area_id
+		WHERE v.status = 'checked-in' AND v.deleted_at IS NULL AND v.departure_date::date = ?::date
+	`
+	var visits []DepartingVisit
+	if err := db.Raw(sql, date).Scan(&visits).Error; err != nil {
+		return nil, err
+	}
+	return visits, nil
+}
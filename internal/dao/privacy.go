@@ -0,0 +1,187 @@
+package dao
+
+import (
+	"counterapp/internal/events"
+	"counterapp/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// collects the personal data of a profile and audits the export in the same
+// transaction. Deleted profiles, visits, schedules and feedback are included
+// since they are stored until purged.
+func ExportPersonalData(db *gorm.DB, profileID string, actorID *uuid.UUID) (*model.PersonalData, error) {
+	data := model.PersonalData{ExportedAt: time.Now().UTC()}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().First(&data.Profile, "id = ?", profileID).Error; err != nil {
+			return err
+		}
+		data.Medical = data.Profile.Medical
+
+		all := tx.Unscoped().Session(&gorm.Session{})
+		err := all.Preload("StayArea").Preload("Locker").
+			Where("profile_id = ?", profileID).Order("arrival_date").Find(&data.Visits).Error
+		if err != nil {
+			return err
+		}
+		err = all.Preload("SevaType").Where("profile_id = ?", profileID).Order("date").Find(&data.Schedules).Error
+		if err != nil {
+			return err
+		}
+		if err := all.Where("profile_id = ?", profileID).Order("created_at").Find(&data.Feedbacks).Error; err != nil {
+			return err
+		}
+		if err := all.Where("profile_id = ?", profileID).Order("created_at").Find(&data.Notifications).Error; err != nil {
+			return err
+		}
+
+		return AddAuditRecords(tx, model.AuditRecord{
+			Action:     model.AuditPersonalDataExported,
+			EntityType: "profile",
+			EntityID:   data.Profile.ID,
+			ActorID:    actorID,
+			Details: map[string]any{
+				"visits":        len(data.Visits),
+				"schedules":     len(data.Schedules),
+				"feedbacks":     len(data.Feedbacks),
+				"notifications": len(data.Notifications),
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// anonymizes a profile: the name, email, phone number, emergency contacts,
+// medical info and every free text written about the person are replaced,
+// the notifications sent to them are deleted. Visits, schedules and feedback
+// types are kept so the statistics still add up. reason is audited when the
+// erasure was not asked for by a person. Deleted profiles are erased too,
+// they hold the same data until purged. Returns gorm.ErrRecordNotFound when
+// the profile does not exist or is already erased.
+func EraseProfile(db *gorm.DB, profileID string, actorID *uuid.UUID, reason string) (*model.Profile, error) {
+	var erased model.Profile
+	err := transactionWithEvents(db, func(tx *gorm.DB, emit emitFunc) error {
+		var profile model.Profile
+		if err := tx.Unscoped().First(&profile, "id = ? AND erased_at IS NULL", profileID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Unscoped().Model(&profile).Updates(map[string]any{
+			"name":                  model.ErasedName,
+			"email":                 model.ErasedEmail(profile.ID),
+			"phone_number":          "",
+			"remarks":               nil,
+			"emergency_contacts":    "[]",
+			"medical":               nil,
+			"notifications_opt_out": true,
+			"erased_at":             time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// deleted rows are anonymized too, they are kept until purged
+		all := tx.Unscoped().Session(&gorm.Session{})
+		details := map[string]any{}
+		for _, change := range []struct {
+			name   string
+			model  any
+			column string
+			value  any
+		}{
+			{"visits", &model.Visit{}, "remarks", nil},
+			{"schedules", &model.Schedule{}, "notes", nil},
+			{"feedbacks", &model.Feedback{}, "content", model.ErasedFeedback},
+		} {
+			result := all.Model(change.model).Where("profile_id = ?", profileID).Update(change.column, change.value)
+			if result.Error != nil {
+				return result.Error
+			}
+			details[change.name] = result.RowsAffected
+		}
+
+		result := all.Where("profile_id = ?", profileID).Delete(&model.OutboxMessage{})
+		if result.Error != nil {
+			return result.Error
+		}
+		details["notifications"] = result.RowsAffected
+
+		// webhook payloads of profile events carry the name and email
+		result = tx.Exec(`
+			UPDATE webhook_deliveries
+			SET payload = jsonb_set(jsonb_set(payload, '{data,name}', to_jsonb(?::text)), '{data,email}', to_jsonb(?::text))
+			WHERE payload->'data'->>'profile_id' = ? AND payload->'data'->>'email' IS NOT NULL`,
+			model.ErasedName, model.ErasedEmail(profile.ID), profile.ID.String())
+		if result.Error != nil {
+			return result.Error
+		}
+		details["webhook_deliveries"] = result.RowsAffected
+
+		// events shared between instances are stored for a few minutes,
+		// listeners have read them by now
+		err = tx.Exec(`DELETE FROM event_payloads WHERE payload->'data'->>'profile_id' = ?`, profile.ID.String()).Error
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			details["reason"] = reason
+		}
+
+		err = AddAuditRecords(tx, model.AuditRecord{
+			Action:     model.AuditProfileErased,
+			EntityType: "profile",
+			EntityID:   profile.ID,
+			ActorID:    actorID,
+			Details:    details,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().First(&erased, "id = ?", profileID).Error; err != nil {
+			return err
+		}
+		return emit(events.New(events.ProfileErased, events.NewProfileData(&erased)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &erased, nil
+}
+
+// audit records are written in batches of this size
+const auditBatchSize = 500
+
+func AddAuditRecords(tx *gorm.DB, records ...model.AuditRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	for i := range records {
+		if records[i].Details == nil {
+			records[i].Details = map[string]any{}
+		}
+	}
+	return tx.CreateInBatches(records, auditBatchSize).Error
+}
+
+// returns audit records newest first
+func GetAuditRecords(db *gorm.DB, filter model.AuditFilter) ([]model.AuditRecord, error) {
+	query := db.Order("created_at DESC").Limit(filter.Limit)
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityID != nil {
+		query = query.Where("entity_id = ?", *filter.EntityID)
+	}
+
+	var records []model.AuditRecord
+	if err := query.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,260 @@
+package dao
+
+import (
+	"counterapp/internal/model"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rows changed by a retention rule in one transaction
+const retentionBatchSize = 500
+
+type retentionRule struct {
+	name   string
+	entity string
+	action model.AuditAction
+	cutoff time.Time
+	// selects the rows that are due
+	due func(db *gorm.DB) *gorm.DB
+	// changes the rows and audits every change, returns the IDs of the rows
+	// that were changed since some may have been changed in the meantime
+	apply func(db *gorm.DB, r retentionRule, ids []uuid.UUID) ([]uuid.UUID, error)
+}
+
+// the enabled rules in the order they are applied. Deleted rows are purged
+// first so nothing is anonymized just to be removed afterwards.
+func retentionRules(policy model.RetentionPolicy, now time.Time) []retentionRule {
+	var rs []retentionRule
+
+	if days := policy.PurgeDeletedDays; days > 0 {
+		cutoff := now.AddDate(0, 0, -days)
+		rs = append(rs, retentionRule{
+			name:   "purge_deleted_profiles",
+			entity: "profile",
+			action: model.AuditRecordPurged,
+			cutoff: cutoff,
+			due: func(db *gorm.DB) *gorm.DB {
+				return db.Unscoped().Model(&model.Profile{}).Where("deleted_at < ?", cutoff)
+			},
+			apply: purgeRows(PurgeProfile),
+		}, retentionRule{
+			name:   "purge_deleted_visits",
+			entity: "visit",
+			action: model.AuditRecordPurged,
+			cutoff: cutoff,
+			// visits of deleted profiles go with their profile
+			due: func(db *gorm.DB) *gorm.DB {
+				return db.Unscoped().Model(&model.Visit{}).
+					Where("deleted_at < ?", cutoff).
+					Where("NOT EXISTS (SELECT 1 FROM profiles p WHERE p.id = visits.profile_id AND p.deleted_at IS NOT NULL)")
+			},
+			apply: purgeRows(PurgeVisit),
+		})
+	}
+
+	if years := policy.InactiveProfileYears; years > 0 {
+		cutoff := now.AddDate(-years, 0, 0)
+		rs = append(rs, retentionRule{
+			name:   "inactive_profiles",
+			entity: "profile",
+			action: model.AuditProfileErased,
+			cutoff: cutoff,
+			due: func(db *gorm.DB) *gorm.DB {
+				return inactiveProfiles(db.Model(&model.Profile{}), cutoff).Where("erased_at IS NULL")
+			},
+			apply: eraseRows,
+		})
+	}
+
+	if months := policy.RemarksMonths; months > 0 {
+		cutoff := now.AddDate(0, -months, 0)
+		// deleted rows are cleared too, they are kept until purged
+		ofInactive := func(db *gorm.DB) *gorm.DB {
+			return inactiveProfiles(db.Unscoped().Model(&model.Profile{}), cutoff).Select("id")
+		}
+		rs = append(rs, retentionRule{
+			name:   "profile_remarks",
+			entity: "profile",
+			action: model.AuditRemarksCleared,
+			cutoff: cutoff,
+			due: func(db *gorm.DB) *gorm.DB {
+				return db.Unscoped().Model(&model.Profile{}).Where("remarks IS NOT NULL AND id IN (?)", ofInactive(db))
+			},
+			apply: clearColumn(&model.Profile{}, "remarks", nil),
+		}, retentionRule{
+			name:   "visit_remarks",
+			entity: "visit",
+			action: model.AuditRemarksCleared,
+			cutoff: cutoff,
+			due: func(db *gorm.DB) *gorm.DB {
+				return db.Unscoped().Model(&model.Visit{}).Where("remarks IS NOT NULL AND profile_id IN (?)", ofInactive(db))
+			},
+			apply: clearColumn(&model.Visit{}, "remarks", nil),
+		}, retentionRule{
+			name:   "schedule_notes",
+			entity: "schedule",
+			action: model.AuditRemarksCleared,
+			cutoff: cutoff,
+			due: func(db *gorm.DB) *gorm.DB {
+				return db.Unscoped().Model(&model.Schedule{}).Where("notes IS NOT NULL AND profile_id IN (?)", ofInactive(db))
+			},
+			apply: clearColumn(&model.Schedule{}, "notes", nil),
+		})
+	}
+
+	if months := policy.FeedbackMonths; months > 0 {
+		cutoff := now.AddDate(0, -months, 0)
+		rs = append(rs, retentionRule{
+			name:   "feedback",
+			entity: "feedback",
+			action: model.AuditFeedbackCleared,
+			cutoff: cutoff,
+			due: func(db *gorm.DB) *gorm.DB {
+				return db.Unscoped().Model(&model.Feedback{}).
+					Where("created_at < ? AND content <> ?", cutoff, model.ErasedFeedback)
+			},
+			apply: clearColumn(&model.Feedback{}, "content", model.ErasedFeedback),
+		})
+	}
+
+	return rs
+}
+
+// narrows a profile query to the profiles created before cutoff that have
+// not been checked in or on a visit since
+func inactiveProfiles(query *gorm.DB, cutoff time.Time) *gorm.DB {
+	return query.Where("created_at < ?", cutoff).Where(`NOT EXISTS (
+		SELECT 1 FROM visits v WHERE v.profile_id = profiles.id
+		AND (v.status = ? OR COALESCE(v.checked_out_at, v.departure_date, v.arrival_date) >= ?))`,
+		model.StatusCheckedIn, cutoff)
+}
+
+// reports what ApplyRetention would change at now without changing anything
+func PlanRetention(db *gorm.DB, policy model.RetentionPolicy, now time.Time) (*model.RetentionReport, error) {
+	report := model.RetentionReport{DryRun: true, RanAt: now, Rules: []model.RetentionRuleReport{}}
+	for _, r := range retentionRules(policy, now) {
+		var count int64
+		if err := r.due(db).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		ids := []uuid.UUID{}
+		if err := r.due(db).Order("id").Limit(model.RetentionReportIDs).Pluck("id", &ids).Error; err != nil {
+			return nil, err
+		}
+		report.Rules = append(report.Rules, r.report(int(count), ids))
+	}
+	return &report, nil
+}
+
+// runs every enabled retention rule, each batch of rows in its own
+// transaction so a failure keeps what was done before it
+func ApplyRetention(db *gorm.DB, policy model.RetentionPolicy, now time.Time) (*model.RetentionReport, error) {
+	report := model.RetentionReport{RanAt: now, Rules: []model.RetentionRuleReport{}}
+	for _, r := range retentionRules(policy, now) {
+		var due []uuid.UUID
+		if err := r.due(db).Order("id").Pluck("id", &due).Error; err != nil {
+			return nil, err
+		}
+
+		changed := []uuid.UUID{}
+		for start := 0; start < len(due); start += retentionBatchSize {
+			ids, err := r.apply(db, r, due[start:min(start+retentionBatchSize, len(due))])
+			if err != nil {
+				return nil, err
+			}
+			changed = append(changed, ids...)
+		}
+		report.Rules = append(report.Rules, r.report(len(changed), changed[:min(model.RetentionReportIDs, len(changed))]))
+	}
+	return &report, nil
+}
+
+func (r retentionRule) report(count int, ids []uuid.UUID) model.RetentionRuleReport {
+	return model.RetentionRuleReport{
+		Rule:   r.name,
+		Entity: r.entity,
+		Action: r.action,
+		Cutoff: r.cutoff,
+		Count:  count,
+		IDs:    ids,
+	}
+}
+
+func (r retentionRule) audit(id uuid.UUID, details map[string]any) model.AuditRecord {
+	details["rule"] = r.name
+	return model.AuditRecord{Action: r.action, EntityType: r.entity, EntityID: id, Details: details}
+}
+
+// purges the rows one by one with the function removing one row and
+// everything under it. Rows restored in the meantime are skipped.
+func purgeRows(remove func(db *gorm.DB, id string) error) func(db *gorm.DB, r retentionRule, ids []uuid.UUID) ([]uuid.UUID, error) {
+	return func(db *gorm.DB, r retentionRule, ids []uuid.UUID) ([]uuid.UUID, error) {
+		var purged []uuid.UUID
+		for _, id := range ids {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := remove(tx, id.String()); err != nil {
+					return err
+				}
+				return AddAuditRecords(tx, r.audit(id, map[string]any{}))
+			})
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			purged = append(purged, id)
+		}
+		return purged, nil
+	}
+}
+
+// erases the profiles one by one, EraseProfile audits them itself
+func eraseRows(db *gorm.DB, r retentionRule, ids []uuid.UUID) ([]uuid.UUID, error) {
+	var erased []uuid.UUID
+	for _, id := range ids {
+		_, err := EraseProfile(db, id.String(), nil, r.name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		erased = append(erased, id)
+	}
+	return erased, nil
+}
+
+// sets column to value on the rows that are still due
+func clearColumn(row any, column string, value any) func(db *gorm.DB, r retentionRule, ids []uuid.UUID) ([]uuid.UUID, error) {
+	return func(db *gorm.DB, r retentionRule, ids []uuid.UUID) ([]uuid.UUID, error) {
+		var cleared []uuid.UUID
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// locked so the audit matches what was cleared
+			err := r.due(tx).Where("id IN ?", ids).Clauses(clause.Locking{Strength: "UPDATE"}).Pluck("id", &cleared).Error
+			if err != nil {
+				return err
+			}
+			if len(cleared) == 0 {
+				return nil
+			}
+			if err := tx.Unscoped().Model(row).Where("id IN ?", cleared).Update(column, value).Error; err != nil {
+				return err
+			}
+
+			records := make([]model.AuditRecord, len(cleared))
+			for i, id := range cleared {
+				records[i] = r.audit(id, map[string]any{"field": column})
+			}
+			return AddAuditRecords(tx, records...)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return cleared, nil
+	}
+}
@@ -0,0 +1,165 @@
+package dao
+
+import (
+	"counterapp/internal/model"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// the checked-in visits as the entries of a roll call that is not stored,
+// with the seva each person has on today, ordered like SortRollCallEntries
+func GetOnSite(db *gorm.DB, today time.Time) ([]model.RollCallEntry, error) {
+	var visits []model.Visit
+	err := db.Preload("Profile").Preload("StayArea").Preload("Locker").
+		Where("status = ?", model.StatusCheckedIn).Find(&visits).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(visits) == 0 {
+		return []model.RollCallEntry{}, nil
+	}
+
+	visitIDs := make([]uuid.UUID, len(visits))
+	for i, v := range visits {
+		visitIDs[i] = v.ID
+	}
+	var schedules []model.Schedule
+	if err := db.Preload("SevaType").Where("date = ? AND visit_id IN ?", today, visitIDs).Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	byVisit := make(map[uuid.UUID]*model.Schedule, len(schedules))
+	for i := range schedules {
+		byVisit[schedules[i].VisitID] = &schedules[i]
+	}
+
+	entries := make([]model.RollCallEntry, len(visits))
+	for i, v := range visits {
+		entries[i] = model.RollCallEntry{VisitID: v.ID, Visit: v, StayAreaID: v.StayAreaID, StayArea: v.StayArea}
+		if schedule, ok := byVisit[v.ID]; ok {
+			entries[i].ScheduleID = &schedule.ID
+			entries[i].Schedule = schedule
+		}
+	}
+	SortRollCallEntries(entries)
+	return entries, nil
+}
+
+// orders entries by stay area, then by name, the order they are printed in
+func SortRollCallEntries(entries []model.RollCallEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.StayArea.Name != b.StayArea.Name {
+			return a.StayArea.Name < b.StayArea.Name
+		}
+		return a.Visit.Profile.Name < b.Visit.Profile.Name
+	})
+}
+
+// stores a roll call of everyone checked in now
+func StartRollCall(db *gorm.DB, note *string, startedBy *uuid.UUID, today time.Time) (*model.RollCall, error) {
+	var started *model.RollCall
+	err := db.Transaction(func(tx *gorm.DB) error {
+		entries, err := GetOnSite(tx, today)
+		if err != nil {
+			return err
+		}
+
+		rollCall := model.RollCall{Note: note, StartedBy: startedBy, StartedAt: time.Now()}
+		if err := tx.Omit(clause.Associations).Create(&rollCall).Error; err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			for i := range entries {
+				entries[i].RollCallID = rollCall.ID
+			}
+			if err := tx.Omit(clause.Associations).Create(&entries).Error; err != nil {
+				return err
+			}
+		}
+
+		started, err = GetRollCall(tx, rollCall.ID.String())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return started, nil
+}
+
+// loads what an entry shows. Deleted visits and schedules are loaded too,
+// the roll call still counted them.
+func preloadEntry(db *gorm.DB, path string) *gorm.DB {
+	unscoped := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+	return db.Preload(path+"Visit", unscoped).
+		Preload(path+"Visit.Profile", unscoped).
+		Preload(path+"Visit.Locker").
+		Preload(path+"StayArea").
+		Preload(path+"Schedule", unscoped).
+		Preload(path + "Schedule.SevaType")
+}
+
+// returns the roll call with its entries ordered like SortRollCallEntries
+func GetRollCall(db *gorm.DB, id string) (*model.RollCall, error) {
+	var rollCall model.RollCall
+	if err := preloadEntry(db, "Entries.").First(&rollCall, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	SortRollCallEntries(rollCall.Entries)
+	return &rollCall, nil
+}
+
+// returns the most recent roll calls first
+func GetRollCalls(db *gorm.DB, limit int) ([]model.RollCallSummary, error) {
+	summaries := []model.RollCallSummary{}
+	err := db.Model(&model.RollCall{}).
+		Select(`roll_calls.*,
+			(SELECT COUNT(*) FROM roll_call_entries e WHERE e.roll_call_id = roll_calls.id) AS total,
+			(SELECT COUNT(*) FROM roll_call_entries e WHERE e.roll_call_id = roll_calls.id AND e.accounted_at IS NOT NULL) AS accounted`).
+		Order("started_at DESC").Limit(limit).Scan(&summaries).Error
+	if err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// marks an entry of the roll call as accounted for by a user, or clears the
+// mark. Marking it again keeps the time it was first marked. Returns
+// gorm.ErrRecordNotFound when the entry is not in the roll call.
+func MarkRollCallEntry(db *gorm.DB, rollCallID string, entryID string, accounted bool, by *uuid.UUID) (*model.RollCallEntry, error) {
+	var marked model.RollCallEntry
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var entry model.RollCallEntry
+		if err := tx.First(&entry, "id = ? AND roll_call_id = ?", entryID, rollCallID).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]any{"accounted_at": nil, "accounted_by": nil}
+		if accounted {
+			updates = map[string]any{"accounted_at": time.Now(), "accounted_by": by}
+		}
+		if accounted != (entry.AccountedAt != nil) {
+			if err := tx.Model(&entry).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		return preloadEntry(tx, "").First(&marked, "id = ?", entryID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &marked, nil
+}
+
+// closes the roll call, closing it again keeps the time it was first closed
+func CloseRollCall(db *gorm.DB, id string) (*model.RollCall, error) {
+	err := db.Model(&model.RollCall{}).Where("id = ? AND closed_at IS NULL", id).Update("closed_at", time.Now()).Error
+	if err != nil {
+		return nil, err
+	}
+	return GetRollCall(db, id)
+}
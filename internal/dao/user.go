@@ -0,0 +1,23 @@
+package dao
+
+import (
+	"counterapp/internal/model"
+
+	"gorm.io/gorm"
+)
+
+func CreateUser(db *gorm.DB, user *model.User) (*model.User, error) {
+	if err := db.Create(user).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// returns gorm.ErrRecordNotFound when no user has the token
+func GetUserByTokenHash(db *gorm.DB, tokenHash string) (*model.User, error) {
+	var user model.User
+	if err := db.First(&user, "token_hash = ?", tokenHash).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
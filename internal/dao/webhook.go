@@ -0,0 +1,177 @@
+package dao
+
+import (
+	"counterapp/internal/events"
+	"counterapp/internal/model"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// records a delivery for every active subscription to the event type. It is
+// called with the transaction of the write that caused the event, so
+// deliveries only exist for changes that were committed.
+func recordWebhookDeliveries(tx *gorm.DB, evt events.Event) error {
+	filter, err := json.Marshal([]events.Type{evt.Type})
+	if err != nil {
+		return err
+	}
+
+	var subscriptions []model.WebhookSubscription
+	result := tx.Where("is_active = ? AND event_types @> ?::jsonb", true, string(filter)).Find(&subscriptions)
+	if result.Error != nil {
+		return result.Error
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]model.WebhookDelivery, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		deliveries = append(deliveries, model.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        evt.ID,
+			EventType:      string(evt.Type),
+			Payload:        string(payload),
+			Status:         model.DeliveryPending,
+			NextAttemptAt:  evt.OccurredAt,
+		})
+	}
+	return tx.Create(&deliveries).Error
+}
+
+func AddWebhookSubscription(db *gorm.DB, req model.AddWebhookSubscriptionRequest) (*model.WebhookSubscription, error) {
+	sub := &model.WebhookSubscription{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		IsActive:   true,
+	}
+	if err := db.Create(sub).Error; err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func GetAllWebhookSubscriptions(db *gorm.DB) ([]model.WebhookSubscription, error) {
+	var subscriptions []model.WebhookSubscription
+	if err := db.Order("created_at").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// stops new deliveries for the subscription, its delivery log is kept
+func DeactivateWebhookSubscription(db *gorm.DB, id string) error {
+	result := db.Model(&model.WebhookSubscription{}).Where("id = ?", id).Update("is_active", false)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// marks due deliveries as sending until leaseUntil and returns them with
+// their subscription, see ClaimDueOutboxMessages
+func ClaimDueWebhookDeliveries(db *gorm.DB, now, leaseUntil time.Time, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Preload("Subscription").
+			Where("status IN ? AND next_attempt_at <= ?", []model.DeliveryStatus{model.DeliveryPending, model.DeliverySending}, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+			deliveries[i].Status = model.DeliverySending
+			deliveries[i].NextAttemptAt = leaseUntil
+		}
+		return tx.Model(&model.WebhookDelivery{}).Where("id IN ?", ids).Updates(map[string]any{
+			"status":          model.DeliverySending,
+			"next_attempt_at": leaseUntil,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// see claimedOutboxMessage
+func claimedWebhookDelivery(db *gorm.DB, id string) *gorm.DB {
+	return db.Model(&model.WebhookDelivery{}).Where("id = ? AND status = ?", id, model.DeliverySending)
+}
+
+func MarkWebhookDeliverySent(db *gorm.DB, id string, statusCode int, deliveredAt time.Time) error {
+	return claimedWebhookDelivery(db, id).Updates(map[string]any{
+		"status":           model.DeliverySent,
+		"attempts":         gorm.Expr("attempts + 1"),
+		"last_status_code": statusCode,
+		"last_error":       nil,
+		"delivered_at":     deliveredAt,
+	}).Error
+}
+
+// records a failed attempt, statusCode is nil when no response was received
+func MarkWebhookDeliveryAttemptFailed(db *gorm.DB, id string, statusCode *int, deliveryErr string, nextAttemptAt time.Time, failed bool) error {
+	status := model.DeliveryPending
+	if failed {
+		status = model.DeliveryFailed
+	}
+	return claimedWebhookDelivery(db, id).Updates(map[string]any{
+		"status":           status,
+		"attempts":         gorm.Expr("attempts + 1"),
+		"last_status_code": statusCode,
+		"last_error":       deliveryErr,
+		"next_attempt_at":  nextAttemptAt,
+	}).Error
+}
+
+func GetWebhookDeliveries(db *gorm.DB, subscriptionID string, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	result := db.Where("subscription_id = ?", subscriptionID).Order("created_at DESC").Limit(limit).Find(&deliveries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return deliveries, nil
+}
+
+// queues a new delivery with the same payload as an earlier one, the
+// original stays in the log untouched
+func RedeliverWebhookDelivery(db *gorm.DB, id string) (*model.WebhookDelivery, error) {
+	var original model.WebhookDelivery
+	if err := db.First(&original, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	redelivery := &model.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         model.DeliveryPending,
+		NextAttemptAt:  time.Now(),
+		RedeliveryOf:   &original.ID,
+	}
+	if err := db.Create(redelivery).Error; err != nil {
+		return nil, err
+	}
+	return redelivery, nil
+}
@@ -0,0 +1,148 @@
+package events
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Publisher receives events after the change that caused them is committed
+type Publisher interface {
+	Publish(evt Event)
+}
+
+// Envelope is an event as seen by stream subscribers. ID is unique for the
+// life of the process and lets a reconnecting client resume where it left.
+type Envelope struct {
+	ID    string
+	Seq   uint64
+	Event Event
+}
+
+// Bus fans events out to in-process subscribers and keeps the most recent
+// ones so reconnecting clients can catch up
+type Bus struct {
+	mu      sync.Mutex
+	bootID  string
+	seq     uint64
+	history []Envelope
+	size    int
+	subs    map[*Subscription]struct{}
+	closed  bool
+}
+
+type Subscription struct {
+	C   <-chan Envelope
+	ch  chan Envelope
+	bus *Bus
+}
+
+const subscriberBuffer = 64
+
+func NewBus(historySize int) *Bus {
+	return &Bus{
+		bootID: uuid.NewString()[:8],
+		size:   historySize,
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+func (b *Bus) Publish(evt Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	env := Envelope{ID: fmt.Sprintf("%s-%d", b.bootID, b.seq), Seq: b.seq, Event: evt}
+
+	b.history = append(b.history, env)
+	if len(b.history) > b.size {
+		b.history = b.history[len(b.history)-b.size:]
+	}
+
+	for sub := range b.subs {
+		select {
+		case sub.ch <- env:
+		default:
+			// the subscriber is too slow, drop it so it reconnects and
+			// resumes from its last event instead of blocking everyone
+			close(sub.ch)
+			delete(b.subs, sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber. When lastEventID is set, the events
+// published after it are returned for replay. resumed is false when the
+// events since lastEventID are no longer known, for example after a restart
+// or when the client was away too long, and the client has to reload its
+// state instead.
+func (b *Bus) Subscribe(lastEventID string) (sub *Subscription, replay []Envelope, resumed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Envelope, subscriberBuffer)
+	sub = &Subscription{C: ch, ch: ch, bus: b}
+	if b.closed {
+		close(ch)
+		return sub, nil, false
+	}
+	b.subs[sub] = struct{}{}
+
+	if lastEventID == "" {
+		return sub, nil, true
+	}
+
+	seq, ok := b.parseID(lastEventID)
+	if !ok || seq > b.seq {
+		return sub, nil, false
+	}
+	if len(b.history) > 0 && seq+1 < b.history[0].Seq {
+		return sub, nil, false
+	}
+	for _, env := range b.history {
+		if env.Seq > seq {
+			replay = append(replay, env)
+		}
+	}
+	return sub, replay, true
+}
+
+func (b *Bus) parseID(id string) (uint64, bool) {
+	boot, seq, ok := strings.Cut(id, "-")
+	if !ok || boot != b.bootID {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// Close ends every subscription, and subscriptions made afterwards, so
+// streams return when the server shuts down. Published events are still kept
+// in the history.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subs {
+		close(sub.ch)
+		delete(b.subs, sub)
+	}
+}
+
+// Close unregisters the subscription, it is safe to call more than once
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	if _, ok := s.bus.subs[s]; ok {
+		delete(s.bus.subs, s)
+		close(s.ch)
+	}
+}
@@ -0,0 +1,124 @@
+package events_test
+
+import (
+	"counterapp/internal/events"
+	"testing"
+)
+
+func publish(bus *events.Bus, n int) []events.Envelope {
+	sub, _, _ := bus.Subscribe("")
+	defer sub.Close()
+
+	var published []events.Envelope
+	for range n {
+		bus.Publish(events.New(events.VisitCheckedIn, nil))
+		published = append(published, <-sub.C)
+	}
+	return published
+}
+
+func TestBusDeliversToSubscribers(t *testing.T) {
+	bus := events.NewBus(10)
+	first, _, _ := bus.Subscribe("")
+	second, _, _ := bus.Subscribe("")
+	defer first.Close()
+	defer second.Close()
+
+	evt := events.New(events.VisitCheckedIn, events.VisitData{Status: "checked-in"})
+	bus.Publish(evt)
+	for _, sub := range []*events.Subscription{first, second} {
+		env := <-sub.C
+		if env.Event.ID != evt.ID || env.Seq != 1 || env.ID == "" {
+			t.Fatalf("received %+v, want the published event as number 1", env)
+		}
+	}
+
+	second.Close()
+	second.Close()
+	bus.Publish(events.New(events.VisitCheckedOut, nil))
+	if env := <-first.C; env.Event.Type != events.VisitCheckedOut {
+		t.Fatalf("received %s, want the check-out", env.Event.Type)
+	}
+	if _, open := <-second.C; open {
+		t.Fatal("closed subscription still receives events")
+	}
+}
+
+func TestBusResumesAfterLastEventID(t *testing.T) {
+	bus := events.NewBus(10)
+	published := publish(bus, 5)
+
+	sub, replay, resumed := bus.Subscribe(published[1].ID)
+	defer sub.Close()
+	if !resumed || len(replay) != 3 {
+		t.Fatalf("resumed %v with %d events, want the 3 after the second", resumed, len(replay))
+	}
+	for i, env := range replay {
+		if env.ID != published[i+2].ID {
+			t.Errorf("replayed %s as number %d, want %s", env.ID, i, published[i+2].ID)
+		}
+	}
+
+	latest, replay, resumed := bus.Subscribe(published[4].ID)
+	defer latest.Close()
+	if !resumed || len(replay) != 0 {
+		t.Fatalf("resumed %v with %d events from the latest event, want nothing to replay", resumed, len(replay))
+	}
+}
+
+func TestBusCannotResume(t *testing.T) {
+	bus := events.NewBus(3)
+	published := publish(bus, 6)
+
+	for name, lastEventID := range map[string]string{
+		"evicted from the history": published[1].ID,
+		"another boot":             "0000dead-3",
+		"from the future":          published[5].ID[:len(published[5].ID)-1] + "9",
+		"malformed":                "yesterday",
+	} {
+		sub, replay, resumed := bus.Subscribe(lastEventID)
+		if resumed || len(replay) != 0 {
+			t.Errorf("%s: resumed %v with %d events, want a reset", name, resumed, len(replay))
+		}
+		sub.Close()
+	}
+
+	// the oldest event kept is still enough to resume
+	sub, replay, resumed := bus.Subscribe(published[2].ID)
+	defer sub.Close()
+	if !resumed || len(replay) != 3 {
+		t.Fatalf("resumed %v with %d events from the oldest kept, want the 3 after it", resumed, len(replay))
+	}
+}
+
+func TestBusDropsSlowSubscribers(t *testing.T) {
+	bus := events.NewBus(10)
+	slow, _, _ := bus.Subscribe("")
+	defer slow.Close()
+
+	for range 100 {
+		bus.Publish(events.New(events.VisitCheckedIn, nil))
+	}
+	received := 0
+	for range slow.C {
+		received++
+	}
+	if received == 0 || received >= 100 {
+		t.Fatalf("slow subscriber received %d events before being dropped", received)
+	}
+}
+
+func TestBusClose(t *testing.T) {
+	bus := events.NewBus(10)
+	sub, _, _ := bus.Subscribe("")
+	bus.Close()
+	if _, open := <-sub.C; open {
+		t.Fatal("subscription open after the bus closed")
+	}
+	late, _, _ := bus.Subscribe("")
+	if _, open := <-late.C; open {
+		t.Fatal("subscription made after the bus closed is open")
+	}
+	sub.Close()
+	late.Close()
+}
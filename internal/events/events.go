@@ -0,0 +1,136 @@
+package events
+
+import (
+	"counterapp/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Type string
+
+const (
+	VisitCheckedIn       Type = "visit.checked_in"
+	VisitCheckedOut      Type = "visit.checked_out"
+	VisitStayAreaChanged Type = "visit.stay_area_changed"
+	VisitLockerAssigned  Type = "visit.locker_assigned"
+	ScheduleCreated      Type = "schedule.created"
+	ProfileBlocked       Type = "profile.blocked"
+	ProfileDeleted       Type = "profile.deleted"
+	ProfileRestored      Type = "profile.restored"
+	VisitDeleted         Type = "visit.deleted"
+	VisitRestored        Type = "visit.restored"
+	ProfileErased        Type = "profile.erased"
+
+	// OccupancyUpdated carries the occupancy of every stay area after a
+	// change. It is only published to the stream, not to webhooks.
+	OccupancyUpdated Type = "occupancy.updated"
+)
+
+// Types lists the events webhooks can subscribe to
+var Types = []Type{
+	VisitCheckedIn, VisitCheckedOut, VisitStayAreaChanged, VisitLockerAssigned, ScheduleCreated, ProfileBlocked,
+	ProfileDeleted, ProfileRestored, VisitDeleted, VisitRestored, ProfileErased,
+}
+
+func (t Type) IsValid() bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is a change to the domain that other systems can react to
+type Event struct {
+	ID         uuid.UUID `json:"id"`
+	Type       Type      `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+func New(t Type, data any) Event {
+	return Event{
+		ID:         uuid.New(),
+		Type:       t,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+type VisitData struct {
+	VisitID       uuid.UUID  `json:"visit_id"`
+	ProfileID     uuid.UUID  `json:"profile_id"`
+	StayAreaID    uuid.UUID  `json:"stay_area_id"`
+	LockerID      *uuid.UUID `json:"locker_id"`
+	Status        string     `json:"status"`
+	ArrivalDate   time.Time  `json:"arrival_date"`
+	DepartureDate *time.Time `json:"departure_date"`
+}
+
+func NewVisitData(v *model.Visit) VisitData {
+	return VisitData{
+		VisitID:       v.ID,
+		ProfileID:     v.ProfileID,
+		StayAreaID:    v.StayAreaID,
+		LockerID:      v.LockerID,
+		Status:        string(v.Status),
+		ArrivalDate:   v.ArrivalDate,
+		DepartureDate: v.DepartureDate,
+	}
+}
+
+type ScheduleData struct {
+	ScheduleID uuid.UUID `json:"schedule_id"`
+	ProfileID  uuid.UUID `json:"profile_id"`
+	VisitID    uuid.UUID `json:"visit_id"`
+	SevaTypeID uuid.UUID `json:"seva_type_id"`
+	Date       time.Time `json:"date"`
+	Location   *string   `json:"location"`
+}
+
+func NewScheduleData(s *model.Schedule) ScheduleData {
+	return ScheduleData{
+		ScheduleID: s.ID,
+		ProfileID:  s.ProfileID,
+		VisitID:    s.VisitID,
+		SevaTypeID: s.SevaTypeID,
+		Date:       s.Date,
+		Location:   s.Location,
+	}
+}
+
+type ProfileData struct {
+	ProfileID uuid.UUID `json:"profile_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	IsBlocked bool      `json:"is_blocked"`
+}
+
+func NewProfileData(p *model.Profile) ProfileData {
+	return ProfileData{
+		ProfileID: p.ID,
+		Name:      p.Name,
+		Email:     p.Email,
+		IsBlocked: p.IsBlocked,
+	}
+}
+
+type OccupancyData struct {
+	StayAreaID           string `json:"stay_area_id"`
+	StayName             string `json:"stay_name"`
+	Capacity             int    `json:"capacity"`
+	CurrentOccupiedCount int    `json:"current_occupied_count"`
+	Available            int    `json:"available"`
+}
+
+// reports whether the event changes how many people are in a stay area.
+// Deleting or restoring a profile takes its visits along.
+func (t Type) AffectsOccupancy() bool {
+	switch t {
+	case VisitCheckedIn, VisitCheckedOut, VisitStayAreaChanged, VisitDeleted, VisitRestored, ProfileDeleted, ProfileRestored:
+		return true
+	}
+	return false
+}
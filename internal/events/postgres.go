@@ -0,0 +1,144 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// Channel is the PostgreSQL NOTIFY channel events are shared on
+const Channel = "counterapp_events"
+
+// how often stored payloads older than a few minutes are deleted, listeners
+// read them as soon as they are notified
+const pruneInterval = time.Minute
+
+// what is sent on Channel. NOTIFY payloads must stay under 8000 bytes, which
+// an occupancy update of many stay areas does not, so the event itself is
+// stored in event_payloads and read back by the listeners.
+type notifiedEvent struct {
+	ID   uuid.UUID `json:"id"`
+	Type Type      `json:"type"`
+}
+
+// PostgresPublisher sends events through PostgreSQL NOTIFY so that every app
+// machine listening with ListenPostgres gets them, including this one
+type PostgresPublisher struct {
+	db     *gorm.DB
+	logger *slog.Logger
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+func NewPostgresPublisher(db *gorm.DB, logger *slog.Logger) *PostgresPublisher {
+	return &PostgresPublisher{db: db, logger: logger}
+}
+
+func (p *PostgresPublisher) Publish(evt Event) {
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		p.logger.Error("unable to encode event", "event", evt.Type, "error", err)
+		return
+	}
+	note, err := json.Marshal(notifiedEvent{ID: evt.ID, Type: evt.Type})
+	if err != nil {
+		p.logger.Error("unable to encode event", "event", evt.Type, "error", err)
+		return
+	}
+
+	// the notification is only delivered once the payload is committed
+	err = p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("INSERT INTO event_payloads (id, payload) VALUES (?, ?)", evt.ID, string(payload)).Error; err != nil {
+			return err
+		}
+		return tx.Exec("SELECT pg_notify(?, ?)", Channel, string(note)).Error
+	})
+	if err != nil {
+		p.logger.Error("unable to notify event", "event", evt.Type, "error", err)
+	}
+	p.prune(time.Now())
+}
+
+func (p *PostgresPublisher) prune(now time.Time) {
+	p.mu.Lock()
+	if now.Sub(p.lastPruned) < pruneInterval {
+		p.mu.Unlock()
+		return
+	}
+	p.lastPruned = now
+	p.mu.Unlock()
+
+	if err := p.db.Exec("DELETE FROM event_payloads WHERE created_at < now() - interval '10 minutes'").Error; err != nil {
+		p.logger.Error("unable to prune event payloads", "error", err)
+	}
+}
+
+// ListenPostgres forwards notifications on Channel to bus until ctx is
+// cancelled, reconnecting when the connection drops
+func ListenPostgres(ctx context.Context, dsn string, bus *Bus, logger *slog.Logger) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		err := listen(ctx, dsn, bus, logger)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warn("event listener disconnected", "retry_in", backoff.String(), "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+func listen(ctx context.Context, dsn string, bus *Bus, logger *slog.Logger) error {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{Channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var note notifiedEvent
+		if err := json.Unmarshal([]byte(notification.Payload), &note); err != nil {
+			logger.Warn("ignoring malformed event notification", "error", err)
+			continue
+		}
+
+		var payload []byte
+		err = conn.QueryRow(ctx, "SELECT payload FROM event_payloads WHERE id = $1", note.ID).Scan(&payload)
+		if errors.Is(err, pgx.ErrNoRows) {
+			logger.Warn("ignoring event notification without a payload", "event", note.Type, "event_id", note.ID)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		var evt Event
+		if err := json.Unmarshal(payload, &evt); err != nil {
+			logger.Warn("ignoring malformed event payload", "event", note.Type, "error", err)
+			continue
+		}
+		bus.Publish(evt)
+	}
+}
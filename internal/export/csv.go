@@ -0,0 +1,55 @@
+package export
+
+import (
+	"counterapp/internal/util"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// spreadsheet apps evaluate a cell starting with one of these as a formula
+const formulaPrefixes = "=+-@\t\r"
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) WriteRow(values []string) error {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = escapeFormula(value)
+	}
+	return c.w.Write(escaped)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// prefixes a cell that would be read as a formula with a quote, so a name or
+// remark such as =HYPERLINK(...) opens as text. Numbers and phone numbers
+// such as +91 98450 12345 are left as they are, they cannot call anything.
+// Xlsx cells are written as inline strings and need no escaping.
+func escapeFormula(value string) string {
+	if value == "" || !strings.ContainsRune(formulaPrefixes, rune(value[0])) || plainValue(value) {
+		return value
+	}
+	return "'" + value
+}
+
+func plainValue(value string) bool {
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return true
+	}
+	if value[0] != '+' {
+		return false
+	}
+	_, err := util.NormalizePhoneE164(value, "")
+	return err == nil
+}
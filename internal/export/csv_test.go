@@ -0,0 +1,40 @@
+package export_test
+
+import (
+	"bytes"
+	"counterapp/internal/export"
+	"encoding/csv"
+	"testing"
+)
+
+func TestCSVEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	w := export.NewCSVWriter(&buf)
+	cells := []string{
+		"=HYPERLINK(\"http://x\")", "+91 98450 12345", "+919845012345", "-12.5", "+1+1", "-2+3", "+91 SUM(A1)",
+		"@SUM(A1)", "\tcmd", "\rcmd", "Asha", "", "a=b",
+	}
+	if err := w.WriteRow(cells); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"'=HYPERLINK(\"http://x\")", "+91 98450 12345", "+919845012345", "-12.5", "'+1+1", "'-2+3", "'+91 SUM(A1)",
+		"'@SUM(A1)", "'\tcmd", "'\rcmd", "Asha", "", "a=b",
+	}
+	if len(rows) != 1 || len(rows[0]) != len(want) {
+		t.Fatalf("rows %q, want one row of %d cells", rows, len(want))
+	}
+	for i, cell := range rows[0] {
+		if cell != want[i] {
+			t.Errorf("cell %d is %q, want %q", i, cell, want[i])
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Writer streams rows of a tabular export to the underlying io.Writer
+type Writer interface {
+	WriteRow(values []string) error
+	Close() error
+}
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// parses the format query value, defaulting to csv when empty
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(value)) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	}
+	return "", fmt.Errorf("unsupported export format: %s", value)
+}
+
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// returns a streaming writer for the format, sheetName is only used by xlsx
+func NewWriter(f Format, w io.Writer, sheetName string) (Writer, error) {
+	if f == FormatXLSX {
+		return NewXLSXWriter(w, sheetName)
+	}
+	return NewCSVWriter(w), nil
+}
+
+// Locale decides how dates are rendered in exported cells
+type Locale struct {
+	DateLayout string
+}
+
+var locales = map[string]Locale{
+	"iso":   {DateLayout: "2006-01-02"},
+	"en-in": {DateLayout: "02/01/2006"},
+	"en-gb": {DateLayout: "02/01/2006"},
+	"en-us": {DateLayout: "01/02/2006"},
+	"de-de": {DateLayout: "02.01.2006"},
+	"fr-fr": {DateLayout: "02/01/2006"},
+}
+
+// resolves a locale such as en-IN, falling back to ISO dates when empty
+func ParseLocale(value string) (Locale, error) {
+	if value == "" {
+		return locales["iso"], nil
+	}
+	locale, ok := locales[strings.ToLower(strings.ReplaceAll(value, "_", "-"))]
+	if !ok {
+		return Locale{}, fmt.Errorf("unsupported locale: %s", value)
+	}
+	return locale, nil
+}
+
+func (l Locale) Date(t time.Time) string {
+	return t.Format(l.DateLayout)
+}
+
+func (l Locale) OptionalDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return l.Date(*t)
+}
+
+// Column describes one exported field of a row of type T
+type Column[T any] struct {
+	Key    string
+	Header string
+	Value  func(row T, l Locale) string
+}
+
+// picks the columns listed in a comma separated string of keys, in the
+// requested order. An empty selection returns all columns.
+func SelectColumns[T any](all []Column[T], selection string) ([]Column[T], error) {
+	if strings.TrimSpace(selection) == "" {
+		return all, nil
+	}
+
+	byKey := make(map[string]Column[T], len(all))
+	for _, col := range all {
+		byKey[col.Key] = col
+	}
+
+	var selected []Column[T]
+	for _, key := range strings.Split(selection, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		col, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown column: %s", key)
+		}
+		selected = append(selected, col)
+	}
+	return selected, nil
+}
+
+func Headers[T any](cols []Column[T]) []string {
+	headers := make([]string, 0, len(cols))
+	for _, col := range cols {
+		headers = append(headers, col.Header)
+	}
+	return headers
+}
+
+func Values[T any](cols []Column[T], row T, l Locale) []string {
+	values := make([]string, 0, len(cols))
+	for _, col := range cols {
+		values = append(values, col.Value(row, l))
+	}
+	return values
+}
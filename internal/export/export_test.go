@@ -0,0 +1,106 @@
+package export_test
+
+import (
+	"bytes"
+	"counterapp/internal/export"
+	"encoding/csv"
+	"slices"
+	"testing"
+	"time"
+)
+
+type resident struct {
+	Name      string
+	Phone     string
+	Arrival   time.Time
+	Departure *time.Time
+}
+
+var residentColumns = []export.Column[resident]{
+	{Key: "name", Header: "Name", Value: func(r resident, _ export.Locale) string { return r.Name }},
+	{Key: "phone", Header: "Phone", Value: func(r resident, _ export.Locale) string { return r.Phone }},
+	{Key: "arrival", Header: "Arrival", Value: func(r resident, l export.Locale) string { return l.Date(r.Arrival) }},
+	{Key: "departure", Header: "Departure", Value: func(r resident, l export.Locale) string { return l.OptionalDate(r.Departure) }},
+}
+
+func TestSelectColumns(t *testing.T) {
+	all, err := export.SelectColumns(residentColumns, " ")
+	if err != nil || len(all) != len(residentColumns) {
+		t.Fatalf("empty selection gave %d columns, %v, want all", len(all), err)
+	}
+
+	selected, err := export.SelectColumns(residentColumns, "departure, name,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if headers := export.Headers(selected); !slices.Equal(headers, []string{"Departure", "Name"}) {
+		t.Fatalf("headers %v, want the requested order", headers)
+	}
+
+	if _, err := export.SelectColumns(residentColumns, "name,locker"); err == nil {
+		t.Fatal("selected an unknown column")
+	}
+}
+
+func TestCSVRows(t *testing.T) {
+	departure := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	rows := []resident{
+		{Name: "Asha", Phone: "+919845012345", Arrival: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Departure: &departure},
+		{Name: "Ravi, Jr.", Phone: "+91 98450 67890", Arrival: time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range []struct {
+		locale string
+		want   [][]string
+	}{
+		{"", [][]string{{"Name", "Phone", "Arrival", "Departure"}, {"Asha", "+919845012345", "2026-03-01", "2026-03-10"}, {"Ravi, Jr.", "+91 98450 67890", "2026-02-28", ""}}},
+		{"en_IN", [][]string{{"Name", "Phone", "Arrival", "Departure"}, {"Asha", "+919845012345", "01/03/2026", "10/03/2026"}, {"Ravi, Jr.", "+91 98450 67890", "28/02/2026", ""}}},
+		{"en-US", [][]string{{"Name", "Phone", "Arrival", "Departure"}, {"Asha", "+919845012345", "03/01/2026", "03/10/2026"}, {"Ravi, Jr.", "+91 98450 67890", "02/28/2026", ""}}},
+		{"de-DE", [][]string{{"Name", "Phone", "Arrival", "Departure"}, {"Asha", "+919845012345", "01.03.2026", "10.03.2026"}, {"Ravi, Jr.", "+91 98450 67890", "28.02.2026", ""}}},
+	} {
+		locale, err := export.ParseLocale(tc.locale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		w, err := export.NewWriter(export.FormatCSV, &buf, "Residents")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.WriteRow(export.Headers(residentColumns)); err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range rows {
+			if err := w.WriteRow(export.Values(residentColumns, row, locale)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.EqualFunc(got, tc.want, slices.Equal) {
+			t.Errorf("locale %q: rows %q, want %q", tc.locale, got, tc.want)
+		}
+	}
+
+	if _, err := export.ParseLocale("xx-XX"); err == nil {
+		t.Fatal("parsed an unknown locale")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for value, want := range map[string]export.Format{"": export.FormatCSV, "csv": export.FormatCSV, "XLSX": export.FormatXLSX} {
+		got, err := export.ParseFormat(value)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+	if _, err := export.ParseFormat("pdf"); err == nil {
+		t.Fatal("parsed pdf as an export format")
+	}
+}
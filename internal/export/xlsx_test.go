@@ -0,0 +1,105 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"counterapp/internal/export"
+	"encoding/xml"
+	"io"
+	"slices"
+	"testing"
+)
+
+// the parts of a worksheet the writer fills in
+type worksheet struct {
+	Rows []struct {
+		R     string `xml:"r,attr"`
+		Cells []struct {
+			R    string `xml:"r,attr"`
+			T    string `xml:"t,attr"`
+			Text string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type workbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+func readPart(t *testing.T, archive *zip.Reader, name string, into any) {
+	t.Helper()
+	f, err := archive.Open(name)
+	if err != nil {
+		t.Fatalf("opening %s: %v", name, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := xml.Unmarshal(data, into); err != nil {
+		t.Fatalf("parsing %s: %v", name, err)
+	}
+}
+
+func TestXLSXRows(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := export.NewWriter(export.FormatXLSX, &buf, "Residents: North/South [March] and a name that is far too long")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]string{
+		{"Name", "Remarks"},
+		{"Asha", `Needs a <ground floor> bed & "quiet" room`},
+		{"=1+1", ""},
+	}
+	// enough columns to need two letter names
+	wide := make([]string, 28)
+	for i := range wide {
+		wide[i] = "x"
+	}
+	rows = append(rows, wide)
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var book workbook
+	readPart(t, archive, "xl/workbook.xml", &book)
+	if len(book.Sheets) != 1 || book.Sheets[0].Name != "Residents- North-South -March- " {
+		t.Fatalf("sheets %+v, want one named without the characters excel refuses, cut to 31", book.Sheets)
+	}
+
+	var sheet worksheet
+	readPart(t, archive, "xl/worksheets/sheet1.xml", &sheet)
+	if len(sheet.Rows) != len(rows) {
+		t.Fatalf("sheet has %d rows, want %d", len(sheet.Rows), len(rows))
+	}
+	for i, row := range sheet.Rows {
+		var values []string
+		for _, cell := range row.Cells {
+			if cell.T != "inlineStr" {
+				t.Errorf("cell %s is of type %q, want an inline string so nothing is evaluated", cell.R, cell.T)
+			}
+			values = append(values, cell.Text)
+		}
+		if !slices.Equal(values, rows[i]) {
+			t.Errorf("row %s is %q, want %q", row.R, values, rows[i])
+		}
+	}
+
+	last := sheet.Rows[3].Cells
+	if refs := []string{last[0].R, last[25].R, last[26].R, last[27].R}; !slices.Equal(refs, []string{"A4", "Z4", "AA4", "AB4"}) {
+		t.Fatalf("cell references %v, want A4, Z4, AA4 and AB4", refs)
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/service"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const actorKey = "actor"
+
+// identifies the caller from an "Authorization: Bearer <token>" header.
+// Requests without the header pass through anonymously, an unknown token is
+// answered with 401.
+func Authenticate(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			writeError(c, 401, CodeUnauthorized, "Authorization header must be a bearer token")
+			return
+		}
+		user, err := svc.Users.Authenticate(strings.TrimSpace(token))
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.Set(actorKey, user)
+		c.Next()
+	}
+}
+
+// the authenticated user, nil for anonymous requests
+func actor(c *gin.Context) *model.User {
+	if user, ok := c.Get(actorKey); ok {
+		return user.(*model.User)
+	}
+	return nil
+}
+
+// lets only users with one of the roles through, anonymous callers get 401
+// and users with another role 403
+func RequireRole(roles ...model.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authorize(c, roles...) {
+			c.Next()
+		}
+	}
+}
+
+// checks the caller has one of the roles like RequireRole, for handlers that
+// only need a role for part of a request. Writes the error when not.
+func authorize(c *gin.Context, roles ...model.Role) bool {
+	user := actor(c)
+	if user == nil {
+		writeError(c, 401, CodeUnauthorized, "Sign in with an API token to do this")
+		return false
+	}
+	if !slices.Contains(roles, user.Role) {
+		writeError(c, 403, CodeForbidden, "Your role may not do this")
+		return false
+	}
+	return true
+}
+
+// reports whether the caller may see medical info, responses leave it out
+// for everyone else
+func mayReadMedical(c *gin.Context) bool {
+	user := actor(c)
+	return user != nil && slices.Contains(model.MedicalRoles, user.Role)
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"counterapp/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func DeleteProfile(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := svc.Profiles.Delete(c.Param("id")); err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.Status(204)
+	}
+}
+
+func RestoreProfile(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		profile, err := svc.Profiles.Restore(c.Param("id"))
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(200, profile)
+	}
+}
+
+func PurgeProfile(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := svc.Profiles.Purge(c.Param("id")); err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.Status(204)
+	}
+}
+
+func DeleteVisit(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := svc.Visits.Delete(c.Param("id")); err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.Status(204)
+	}
+}
+
+func RestoreVisit(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		visit, err := svc.Visits.Restore(c.Param("id"))
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(200, visit)
+	}
+}
+
+func PurgeVisit(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := svc.Visits.Purge(c.Param("id")); err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.Status(204)
+	}
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"counterapp/internal/service"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorCode is the machine readable part of an error response, clients
+// should branch on it rather than on the message
+type ErrorCode string
+
+const (
+	CodeBadRequest   ErrorCode = "bad_request"
+	CodeInvalidBody  ErrorCode = "invalid_body"
+	CodeValidation   ErrorCode = "validation_failed"
+	CodeNotFound     ErrorCode = "not_found"
+	CodeConflict     ErrorCode = "conflict"
+	CodeForbidden    ErrorCode = "forbidden"
+	CodeUnauthorized ErrorCode = "unauthorized"
+	CodeInternal     ErrorCode = "internal_error"
+)
+
+// ErrorResponse is the body of every 4xx and 5xx response
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code      ErrorCode            `json:"code"`
+	Message   string               `json:"message"`
+	Details   []service.FieldError `json:"details,omitempty"`
+	RequestID string               `json:"request_id,omitempty"`
+}
+
+var errorByKind = map[service.Kind]struct {
+	status int
+	code   ErrorCode
+}{
+	service.KindNotFound:     {404, CodeNotFound},
+	service.KindConflict:     {409, CodeConflict},
+	service.KindValidation:   {400, CodeValidation},
+	service.KindForbidden:    {403, CodeForbidden},
+	service.KindUnauthorized: {401, CodeUnauthorized},
+}
+
+func writeError(c *gin.Context, status int, code ErrorCode, message string, details ...service.FieldError) {
+	c.AbortWithStatusJSON(status, ErrorResponse{Error: ErrorBody{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: requestID(c),
+	}})
+}
+
+func badRequest(c *gin.Context, message string) {
+	writeError(c, 400, CodeBadRequest, message)
+}
+
+func invalidBody(c *gin.Context) {
+	writeError(c, 400, CodeInvalidBody, "Invalid request body")
+}
+
+func invalidField(c *gin.Context, field string, message string) {
+	writeError(c, 400, CodeValidation, message, service.FieldError{Field: field, Message: message})
+}
+
+// logs the cause and answers with a generic 500, database messages are not
+// meant for clients
+func internalError(c *gin.Context, err error) {
+	requestLogger(c).Error("request failed", "method", c.Request.Method, "route", c.FullPath(), "error", err)
+	writeError(c, 500, CodeInternal, "Internal server error")
+}
+
+// writes err returned by the service layer, domain errors get the status of
+// their kind and anything else is a 500
+func writeServiceError(c *gin.Context, err error) {
+	var serviceErr *service.Error
+	if errors.As(err, &serviceErr) {
+		mapped := errorByKind[serviceErr.Kind]
+		writeError(c, mapped.status, mapped.code, serviceErr.Message, serviceErr.Details...)
+		return
+	}
+	internalError(c, err)
+}
+
+// answers requests that match no route
+func NoRoute(c *gin.Context) {
+	writeError(c, 404, CodeNotFound, "Route not found")
+}
+
+// recovers panics in handlers with a 500 envelope, for gin.CustomRecovery
+func Recover(c *gin.Context, recovered any) {
+	internalError(c, fmt.Errorf("panic: %v", recovered))
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"counterapp/internal/export"
+	"counterapp/internal/model"
+	"counterapp/internal/service"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func optional(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+var scheduleExportColumns = []export.Column[model.ScheduleExportRow]{
+	{Key: "date", Header: "Date", Value: func(r model.ScheduleExportRow, l export.Locale) string { return l.Date(r.Date) }},
+	{Key: "name", Header: "Name", Value: func(r model.ScheduleExportRow, _ export.Locale) string { return r.ProfileName }},
+	{Key: "email", Header: "Email", Value: func(r model.ScheduleExportRow, _ export.Locale) string { return r.Email }},
+	{Key: "phone_number", Header: "Phone Number", Value: func(r model.ScheduleExportRow, _ export.Locale) string { return r.PhoneNumber }},
+	{Key: "gender", Header: "Gender", Value: func(r model.ScheduleExportRow, _ export.Locale) string { return r.Gender }},
+	{Key: "category", Header: "Category", Value: func(r model.ScheduleExportRow, _ export.Locale) string { return r.Category }},
+	{Key: "seva_type", Header: "Seva Type", Value: func(r model.ScheduleExportRow, _ export.Locale) string { return r.SevaType }},
+	{Key: "location", Header: "Location", Value: func(r model.ScheduleExportRow, _ export.Locale) string { return optional(r.Location) }},
+	{Key: "stay_area", Header: "Stay Area", Value: func(r model.ScheduleExportRow, _ export.Locale) string { return optional(r.StayArea) }},
+	{Key: "locker_number", Header: "Locker Number", Value: func(r model.ScheduleExportRow, _ export.Locale) string { return optional(r.LockerNumber) }},
+	{Key: "locker_section", Header: "Locker Section", Value: func(r model.ScheduleExportRow, _ export.Locale) string { return optional(r.LockerSection) }},
+	{Key: "arrival_date", Header: "Arrival Date", Value: func(r model.ScheduleExportRow, l export.Locale) string { return l.Date(r.ArrivalDate) }},
+	{Key: "departure_date", Header: "Departure Date", Value: func(r model.ScheduleExportRow, l export.Locale) string { return l.OptionalDate(r.DepartureDate) }},
+	{Key: "notes", Header: "Notes", Value: func(r model.ScheduleExportRow, _ export.Locale) string { return optional(r.Notes) }},
+}
+
+var residentExportColumns = []export.Column[model.ResidentExportRow]{
+	{Key: "stay_area", Header: "Stay Area", Value: func(r model.ResidentExportRow, _ export.Locale) string { return r.StayArea }},
+	{Key: "name", Header: "Name", Value: func(r model.ResidentExportRow, _ export.Locale) string { return r.Name }},
+	{Key: "email", Header: "Email", Value: func(r model.ResidentExportRow, _ export.Locale) string { return r.Email }},
+	{Key: "phone_number", Header: "Phone Number", Value: func(r model.ResidentExportRow, _ export.Locale) string { return r.PhoneNumber }},
+	{Key: "gender", Header: "Gender", Value: func(r model.ResidentExportRow, _ export.Locale) string { return r.Gender }},
+	{Key: "category", Header: "Category", Value: func(r model.ResidentExportRow, _ export.Locale) string { return r.Category }},
+	{Key: "locker_number", Header: "Locker Number", Value: func(r model.ResidentExportRow, _ export.Locale) string { return optional(r.LockerNumber) }},
+	{Key: "locker_section", Header: "Locker Section", Value: func(r model.ResidentExportRow, _ export.Locale) string { return optional(r.LockerSection) }},
+	{Key: "arrival_date", Header: "Arrival Date", Value: func(r model.ResidentExportRow, l export.Locale) string { return l.Date(r.ArrivalDate) }},
+	{Key: "departure_date", Header: "Departure Date", Value: func(r model.ResidentExportRow, l export.Locale) string { return l.OptionalDate(r.DepartureDate) }},
+	{Key: "remarks", Header: "Remarks", Value: func(r model.ResidentExportRow, _ export.Locale) string { return optional(r.Remarks) }},
+	{Key: "visit_id", Header: "Visit ID", Value: func(r model.ResidentExportRow, _ export.Locale) string { return r.VisitID }},
+}
+
+var profileExportColumns = []export.Column[model.ProfileOverview]{
+	{Key: "name", Header: "Name", Value: func(r model.ProfileOverview, _ export.Locale) string { return r.Name }},
+	{Key: "email", Header: "Email", Value: func(r model.ProfileOverview, _ export.Locale) string { return r.Email }},
+	{Key: "phone_number", Header: "Phone Number", Value: func(r model.ProfileOverview, _ export.Locale) string { return r.PhoneNumber }},
+	{Key: "gender", Header: "Gender", Value: func(r model.ProfileOverview, _ export.Locale) string { return r.Gender }},
+	{Key: "category", Header: "Category", Value: func(r model.ProfileOverview, _ export.Locale) string { return r.Category }},
+	{Key: "is_blocked", Header: "Blocked", Value: func(r model.ProfileOverview, _ export.Locale) string { return fmt.Sprint(r.IsBlocked) }},
+	{Key: "remarks", Header: "Remarks", Value: func(r model.ProfileOverview, _ export.Locale) string { return r.Remarks }},
+	{Key: "status", Header: "Status", Value: func(r model.ProfileOverview, _ export.Locale) string { return optional(r.Status) }},
+	{Key: "stay_area", Header: "Stay Area", Value: func(r model.ProfileOverview, _ export.Locale) string { return optional(r.StayArea) }},
+	{Key: "departure_date", Header: "Departure Date", Value: func(r model.ProfileOverview, l export.Locale) string {
+		if r.DepartureDate == nil {
+			return ""
+		}
+		// ProfileOverview carries the date already formatted as YYYY-MM-DD
+		date, err := time.Parse("2006-01-02", *r.DepartureDate)
+		if err != nil {
+			return *r.DepartureDate
+		}
+		return l.Date(date)
+	}},
+	{Key: "active_visit_id", Header: "Active Visit ID", Value: func(r model.ProfileOverview, _ export.Locale) string { return optional(r.ActiveVisitID) }},
+	{Key: "id", Header: "Profile ID", Value: func(r model.ProfileOverview, _ export.Locale) string { return r.ID }},
+}
+
+type exportOptions struct {
+	format export.Format
+	locale export.Locale
+}
+
+func parseExportOptions(c *gin.Context) (*exportOptions, error) {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		return nil, err
+	}
+	locale, err := export.ParseLocale(c.Query("locale"))
+	if err != nil {
+		return nil, err
+	}
+	return &exportOptions{format: format, locale: locale}, nil
+}
+
+// writes the export headers and rows straight to the response. Once the first
+// byte is written the status can no longer change, so errors from stream are
+// only logged and the response is cut short.
+func streamExport[T any](c *gin.Context, opts *exportOptions, filename string, cols []export.Column[T], stream func(func(T) error) error) {
+	// large exports take longer than the server write timeout
+	clearWriteDeadline(c)
+	c.Header("Content-Type", opts.format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, opts.format.Extension()))
+	c.Status(200)
+
+	w, err := export.NewWriter(opts.format, c.Writer, filename)
+	if err != nil {
+		requestLogger(c).Error("unable to start export", "export", filename, "error", err)
+		return
+	}
+
+	err = w.WriteRow(export.Headers(cols))
+	if err == nil {
+		err = stream(func(row T) error {
+			return w.WriteRow(export.Values(cols, row, opts.locale))
+		})
+	}
+	if err != nil {
+		requestLogger(c).Error("error while streaming export", "export", filename, "error", err)
+		return
+	}
+	if err := w.Close(); err != nil {
+		requestLogger(c).Error("unable to finish export", "export", filename, "error", err)
+	}
+}
+
+func ExportSchedules(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, err := parseExportOptions(c)
+		if err != nil {
+			badRequest(c, err.Error())
+			return
+		}
+		cols, err := export.SelectColumns(scheduleExportColumns, c.Query("columns"))
+		if err != nil {
+			invalidField(c, "columns", err.Error())
+			return
+		}
+
+		var query DateRangeQuery
+		if !bindQuery(c, &query) {
+			return
+		}
+
+		filename := fmt.Sprintf("schedules_%s_%s", query.StartDate, query.EndDate)
+		streamExport(c, opts, filename, cols, func(fn func(model.ScheduleExportRow) error) error {
+			return svc.Exports.Schedules(mustDate(query.StartDate), mustDate(query.EndDate), fn)
+		})
+	}
+}
+
+type ResidentsQuery struct {
+	StayAreaID string `form:"stay_area_id" binding:"omitempty,uuid"`
+}
+
+func ExportResidents(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, err := parseExportOptions(c)
+		if err != nil {
+			badRequest(c, err.Error())
+			return
+		}
+		cols, err := export.SelectColumns(residentExportColumns, c.Query("columns"))
+		if err != nil {
+			invalidField(c, "columns", err.Error())
+			return
+		}
+
+		var query ResidentsQuery
+		if !bindQuery(c, &query) {
+			return
+		}
+
+		streamExport(c, opts, "residents", cols, func(fn func(model.ResidentExportRow) error) error {
+			return svc.Exports.Residents(query.StayAreaID, fn)
+		})
+	}
+}
+
+func ExportProfiles(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, err := parseExportOptions(c)
+		if err != nil {
+			badRequest(c, err.Error())
+			return
+		}
+		cols, err := export.SelectColumns(profileExportColumns, c.Query("columns"))
+		if err != nil {
+			invalidField(c, "columns", err.Error())
+			return
+		}
+
+		streamExport(c, opts, "profiles", cols, func(fn func(model.ProfileOverview) error) error {
+			return svc.Exports.Profiles(fn)
+		})
+	}
+}
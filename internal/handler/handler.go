@@ -1,26 +1,18 @@
 package handler
 
 import (
-	"counterapp/internal/dao"
 	"counterapp/internal/model"
-	"counterapp/internal/util"
-	"fmt"
-	"time"
+	"counterapp/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 )
 
-const (
-	logKeyError = "error"
-)
-
-func GetProfiles(db *gorm.DB) gin.HandlerFunc {
+func GetProfiles(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		profiles, err := dao.GetProfilesData(db)
+		profiles, err := svc.Profiles.List()
 		if err != nil {
-			c.JSON(500, gin.H{logKeyError: err.Error()})
+			writeServiceError(c, err)
 			return
 		}
 		c.JSON(200, profiles)
@@ -28,21 +20,76 @@ func GetProfiles(db *gorm.DB) gin.HandlerFunc {
 }
 
 type CreateProfileRequest struct {
-	Profile model.Profile `json:"profile"`
+	Profile *CreateProfileInput `json:"profile" binding:"required"`
+}
+
+// the fields a client may set on a new profile, the ID, timestamps and the
+// blocked flag are left to the server
+type CreateProfileInput struct {
+	Name        string  `json:"name" binding:"required,max=255"`
+	Email       string  `json:"email" binding:"required,email"`
+	PhoneNumber string  `json:"phone_number" binding:"max=30"`
+	Gender      string  `json:"gender" binding:"required,gender"`
+	Category    string  `json:"category" binding:"omitempty,category"`
+	Remarks     *string `json:"remarks,omitempty"`
+
+	NotificationChannel string `json:"notification_channel,omitempty" binding:"omitempty,notification_channel"`
+	NotificationsOptOut bool   `json:"notifications_opt_out,omitempty"`
+
+	EmergencyContacts []EmergencyContactInput `json:"emergency_contacts,omitempty" binding:"max=5,dive"`
+}
+
+type EmergencyContactInput struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Relation    string `json:"relation" binding:"max=100"`
+	PhoneNumber string `json:"phone_number" binding:"required,max=30"`
+}
+
+func emergencyContacts(in []EmergencyContactInput) []model.EmergencyContact {
+	contacts := make([]model.EmergencyContact, len(in))
+	for i, contact := range in {
+		contacts[i] = model.EmergencyContact{Name: contact.Name, Relation: contact.Relation, PhoneNumber: contact.PhoneNumber}
+	}
+	return contacts
+}
+
+// nil when the list was not sent, so the contacts are left as they are
+func optionalEmergencyContacts(in *[]EmergencyContactInput) *[]model.EmergencyContact {
+	if in == nil {
+		return nil
+	}
+	contacts := emergencyContacts(*in)
+	return &contacts
 }
 
-func CreateProfile(db *gorm.DB) gin.HandlerFunc {
+func (in *CreateProfileInput) toModel() *model.Profile {
+	channel := model.NotificationChannel(in.NotificationChannel)
+	if channel == "" {
+		channel = model.ChannelEmail
+	}
+	return &model.Profile{
+		Name:                in.Name,
+		Email:               in.Email,
+		PhoneNumber:         in.PhoneNumber,
+		Gender:              model.Gender(in.Gender),
+		Category:            model.Category(in.Category),
+		Remarks:             in.Remarks,
+		NotificationChannel: channel,
+		NotificationsOptOut: in.NotificationsOptOut,
+		EmergencyContacts:   emergencyContacts(in.EmergencyContacts),
+	}
+}
+
+func CreateProfile(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req CreateProfileRequest
-		err := c.ShouldBindBodyWithJSON(&req)
-		if err != nil {
-			c.JSON(400, gin.H{"error": "Invalid request body"})
+		if !bindJSON(c, &req) {
 			return
 		}
 
-		profile, err := dao.CreateProfile(db, &req.Profile)
+		profile, err := svc.Profiles.Create(req.Profile.toModel())
 		if err != nil {
-			c.JSON(500, gin.H{logKeyError: err.Error()})
+			writeServiceError(c, err)
 			return
 		}
 		c.JSON(200, profile)
@@ -50,98 +97,91 @@ func CreateProfile(db *gorm.DB) gin.HandlerFunc {
 }
 
 type UpdateProfileRequest struct {
-	Name        *string         `json:"name,omitempty"`
-	PhoneNumber *string         `json:"phone_number,omitempty"`
-	Gender      *model.Gender   `json:"gender,omitempty"`
-	Category    *model.Category `json:"category,omitempty"`
+	Name        *string         `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
+	PhoneNumber *string         `json:"phone_number,omitempty" binding:"omitempty,max=30"`
+	Gender      *model.Gender   `json:"gender,omitempty" binding:"omitempty,gender"`
+	Category    *model.Category `json:"category,omitempty" binding:"omitempty,category"`
 	IsBlocked   *bool           `json:"is_blocked,omitempty"`
 	Remarks     *string         `json:"remarks,omitempty"`
+
+	NotificationChannel *model.NotificationChannel `json:"notification_channel,omitempty" binding:"omitempty,notification_channel"`
+	NotificationsOptOut *bool                      `json:"notifications_opt_out,omitempty"`
+
+	// replaces the whole list, [] removes every contact
+	EmergencyContacts *[]EmergencyContactInput `json:"emergency_contacts,omitempty" binding:"omitempty,max=5,dive"`
 }
 
-func UpdateProfile(db *gorm.DB) gin.HandlerFunc {
+func UpdateProfile(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		profileID := c.Param("id")
 		var req UpdateProfileRequest
-		err := c.ShouldBindBodyWithJSON(&req)
-		if err != nil {
-			c.JSON(400, gin.H{"error": "Invalid request body"})
+		if !bindJSON(c, &req) {
 			return
 		}
 
-		updatedProfile, err := dao.UpdateProfile(db, profileID, &dao.ProfileUpdate{
+		updatedProfile, err := svc.Profiles.Update(profileID, &model.ProfileUpdate{
 			Name:        req.Name,
 			PhoneNumber: req.PhoneNumber,
 			Gender:      req.Gender,
 			Category:    req.Category,
 			IsBlocked:   req.IsBlocked,
 			Remarks:     req.Remarks,
+
+			NotificationChannel: req.NotificationChannel,
+			NotificationsOptOut: req.NotificationsOptOut,
+			EmergencyContacts:   optionalEmergencyContacts(req.EmergencyContacts),
 		})
 		if err != nil {
-			c.JSON(500, gin.H{logKeyError: err.Error()})
+			writeServiceError(c, err)
 			return
 		}
 		c.JSON(200, updatedProfile)
 	}
 }
 
-func GetVisitsForProfile(db *gorm.DB) gin.HandlerFunc {
+func GetVisitsForProfile(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		profileID := c.Param("id")
 		if profileID == "" {
-			c.JSON(400, gin.H{logKeyError: "Please enter profile_id"})
+			badRequest(c, "Please enter profile_id")
+			return
 		}
 
-		visits, err := dao.GetVisitsByProfileID(db, profileID)
+		visits, err := svc.Visits.ListForProfile(profileID)
 		if err != nil {
-			c.JSON(500, gin.H{logKeyError: err.Error()})
+			writeServiceError(c, err)
 			return
 		}
 		c.JSON(200, visits)
 	}
 }
 
-func GetAllLockersDetails(db *gorm.DB) gin.HandlerFunc {
+func GetAllLockersDetails(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		lockers, err := dao.GetAllLockers(db)
+		lockers, err := svc.Lockers.List()
 		if err != nil {
-			c.JSON(500, gin.H{
-				logKeyError: err.Error(),
-			})
+			writeServiceError(c, err)
 			return
 		}
 		c.JSON(200, lockers)
 	}
 }
 
-func GetScheduleForDateRange(db *gorm.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		sDate := c.Query("start_date")
-		eDate := c.Query("end_date")
-		if sDate == "" || eDate == "" {
-			c.JSON(400, gin.H{logKeyError: "Cannot have empty values for start and end date"})
-			return
-		}
-
-		startDate, err := util.FormatDateToISO(sDate)
-		if err != nil {
-			c.JSON(400, gin.H{logKeyError: err})
-			return
-		}
-
-		endDate, err := util.FormatDateToISO(eDate)
-		if err != nil {
-			c.JSON(400, gin.H{logKeyError: err})
-			return
-		}
+type DateRangeQuery struct {
+	StartDate string `form:"start_date" binding:"required,date"`
+	EndDate   string `form:"end_date" binding:"required,date,date_gtefield=StartDate"`
+}
 
-		if !util.CompareDates(*startDate, *endDate) {
-			c.JSON(400, gin.H{logKeyError: "Start date cannot be less than end date"})
+func GetScheduleForDateRange(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query DateRangeQuery
+		if !bindQuery(c, &query) {
 			return
 		}
 
-		schedule, err := dao.GetScheduleForDateRange(db, *startDate, *endDate)
+		schedule, err := svc.Schedules.ListForDateRange(mustDate(query.StartDate), mustDate(query.EndDate))
 		if err != nil {
-			c.JSON(500, gin.H{logKeyError: err.Error()})
+			writeServiceError(c, err)
 			return
 		}
 		c.JSON(200, schedule)
@@ -149,83 +189,37 @@ func GetScheduleForDateRange(db *gorm.DB) gin.HandlerFunc {
 }
 
 type AddVisitRequest struct {
-	ProfileID     string  `json:"profile_id"`
-	Email         string  `json:"email"`
-	ArrivalDate   string  `json:"arrival_date"`
-	DepartureDate *string `json:"departure_date,omitempty"`
-	StayAreaID    string  `json:"stay_area_id"`
-	ProfileStatus *string `json:"profile_status,omitempty"`
+	ProfileID     string  `json:"profile_id" binding:"required,uuid"`
+	ArrivalDate   string  `json:"arrival_date" binding:"required,date"`
+	DepartureDate *string `json:"departure_date,omitempty" binding:"omitempty,date,date_gtefield=ArrivalDate"`
+	StayAreaID    string  `json:"stay_area_id" binding:"required,uuid"`
+
+	// confirmed with the person at the desk, each replaces what the profile
+	// has when sent. Medical info needs one of model.MedicalRoles.
+	EmergencyContacts *[]EmergencyContactInput `json:"emergency_contacts,omitempty" binding:"omitempty,max=5,dive"`
+	Medical           *MedicalRequest          `json:"medical,omitempty"`
 }
 
-func AddVisit(db *gorm.DB) gin.HandlerFunc {
+func AddVisit(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req AddVisitRequest
-		err := c.ShouldBindBodyWithJSON(&req)
-		if err != nil {
-			c.JSON(400, gin.H{logKeyError: "Invalid request body"})
+		if !bindJSON(c, &req) {
 			return
 		}
-		profile, err := dao.GetProfileByID(db, req.ProfileID)
-		if err != nil {
-			c.JSON(400, gin.H{logKeyError: "Profile not found"})
+		if req.Medical != nil && !authorize(c, model.MedicalRoles...) {
 			return
 		}
 
-		visitsWithStatusCheckedIn, err := dao.GetVisitsByProfileID(db, profile.ID.String())
-		if err != nil {
-			c.JSON(400, gin.H{logKeyError: "Unable to fetch visit for profile"})
-			return
-		}
-		for _, visit := range visitsWithStatusCheckedIn {
-			if visit.Status != "checked-in" {
-				continue
-			}
-			checkedOutStatus := model.StatusCheckedOut
-			_, err = dao.UpdateVisit(db, visit.ID.String(), dao.UpdateVisitRequest{
-				Status: &checkedOutStatus,
-			})
-			if err != nil {
-				fmt.Printf("unable to update status for the visit: %s, %v", visit.ID, err)
-				continue
-			}
-		}
-
-		arrivalDate, err := util.FormatDateToISO(req.ArrivalDate)
-		if err != nil {
-			c.JSON(400, gin.H{logKeyError: err})
-			return
-		}
-
-		var departureDate *time.Time
-		if req.DepartureDate != nil {
-			departureDate, err = util.FormatDateToISO(*req.DepartureDate)
-			if err != nil {
-				c.JSON(400, gin.H{logKeyError: err})
-				return
-			}
-		}
-
-		profileUUID, err := uuid.Parse(req.ProfileID)
-		if err != nil {
-			c.JSON(400, gin.H{logKeyError: "Invalid profile ID format"})
-			return
-		}
-
-		stayAreaUUID, err := uuid.Parse(req.StayAreaID)
-		if err != nil {
-			c.JSON(400, gin.H{logKeyError: "Invalid stay area ID format"})
-			return
-		}
-
-		visit, err := dao.AddVisit(db, dao.AddVisitRequest{
-			ProfileID:     profileUUID,
-			ArrivalDate:   *arrivalDate,
-			DepartureDate: departureDate,
-			StayAreaID:    stayAreaUUID,
-			Status:        model.StatusCheckedIn,
+		visit, err := svc.Visits.CheckIn(service.CheckInRequest{
+			ProfileID:         uuid.MustParse(req.ProfileID),
+			ArrivalDate:       mustDate(req.ArrivalDate),
+			DepartureDate:     optionalDate(req.DepartureDate),
+			StayAreaID:        uuid.MustParse(req.StayAreaID),
+			EmergencyContacts: optionalEmergencyContacts(req.EmergencyContacts),
+			Medical:           req.Medical.toModel(),
 		})
 		if err != nil {
-			c.JSON(500, gin.H{logKeyError: err})
+			writeServiceError(c, err)
 			return
 		}
 		c.JSON(200, visit)
@@ -233,72 +227,41 @@ func AddVisit(db *gorm.DB) gin.HandlerFunc {
 }
 
 type UpdateVisitRequest struct {
-	DepartureDate *string `json:"departure_date,omitempty"`
-	StayAreaID    *string `json:"stay_area_id,omitempty"`
-	LockerID      *string `json:"locker_id,omitempty"`
+	DepartureDate *string `json:"departure_date,omitempty" binding:"omitempty,date"`
+	StayAreaID    *string `json:"stay_area_id,omitempty" binding:"omitempty,uuid"`
+	LockerID      *string `json:"locker_id,omitempty" binding:"omitempty,uuid"`
 	Remarks       *string `json:"remarks,omitempty"`
-	Status        *string `json:"status,omitempty"`
+	Status        *string `json:"status,omitempty" binding:"omitempty,visit_status"`
 }
 
-func UpdateVisit(db *gorm.DB) gin.HandlerFunc {
+func UpdateVisit(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		visitID := c.Param("id")
 		if visitID == "" {
-			c.JSON(400, gin.H{logKeyError: "Visit ID is required"})
+			badRequest(c, "Visit ID is required")
 			return
 		}
 
 		var req UpdateVisitRequest
-		err := c.ShouldBindBodyWithJSON(&req)
-		if err != nil {
-			c.JSON(400, gin.H{logKeyError: "Invalid request body"})
+		if !bindJSON(c, &req) {
 			return
 		}
 
-		var departureDate *time.Time
-		if req.DepartureDate != nil {
-			departureDate, err = util.FormatDateToISO(*req.DepartureDate)
-			if err != nil {
-				c.JSON(400, gin.H{logKeyError: "Invalid departure date format"})
-				return
-			}
-		}
-
-		var stayAreaUUID *uuid.UUID
-		if req.StayAreaID != nil {
-			parsedStayAreaID, err := uuid.Parse(*req.StayAreaID)
-			if err != nil {
-				c.JSON(400, gin.H{logKeyError: "Invalid stay area ID format"})
-				return
-			}
-			stayAreaUUID = &parsedStayAreaID
-		}
-
-		var lockerUUID *uuid.UUID
-		if req.LockerID != nil {
-			parsedLockerID, err := uuid.Parse(*req.LockerID)
-			if err != nil {
-				c.JSON(400, gin.H{logKeyError: "Invalid locker ID format"})
-				return
-			}
-			lockerUUID = &parsedLockerID
-		}
-
 		var status *model.ProfileStatus
 		if req.Status != nil {
 			profileStatus := model.ProfileStatus(*req.Status)
 			status = &profileStatus
 		}
 
-		updatedVisit, err := dao.UpdateVisit(db, visitID, dao.UpdateVisitRequest{
-			DepartureDate: departureDate,
-			StayAreaID:    stayAreaUUID,
-			LockerID:      lockerUUID,
+		updatedVisit, err := svc.Visits.Update(visitID, model.UpdateVisitRequest{
+			DepartureDate: optionalDate(req.DepartureDate),
+			StayAreaID:    optionalUUID(req.StayAreaID),
+			LockerID:      optionalUUID(req.LockerID),
 			Remarks:       req.Remarks,
 			Status:        status,
 		})
 		if err != nil {
-			c.JSON(500, gin.H{logKeyError: err.Error()})
+			writeServiceError(c, err)
 			return
 		}
 
@@ -307,76 +270,29 @@ func UpdateVisit(db *gorm.DB) gin.HandlerFunc {
 }
 
 type AddScheduleRequest struct {
-	ProfileID string  `json:"profile_id"`
-	VisitID   string  `json:"visit_id"`
-	SevaType  string  `json:"seva_type"`
-	Location  *string `json:"location,omitempty"`
-	Date      string  `json:"date"`
+	ProfileID string  `json:"profile_id" binding:"required,uuid"`
+	VisitID   string  `json:"visit_id" binding:"required,uuid"`
+	SevaType  string  `json:"seva_type" binding:"required"`
+	Location  *string `json:"location,omitempty" binding:"omitempty,max=255"`
+	Date      string  `json:"date" binding:"required,date"`
 }
 
-func AddSchedule(db *gorm.DB) gin.HandlerFunc {
+func AddSchedule(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req AddScheduleRequest
-		err := c.ShouldBindBodyWithJSON(&req)
-		if err != nil {
-			c.JSON(400, gin.H{"error": "Invalid request body"})
-			return
-		}
-
-		// 1. Parse date
-		scheduleDate, err := util.FormatDateToISO(req.Date)
-		if err != nil {
-			c.JSON(400, gin.H{"error": "Invalid date format"})
-			return
-		}
-
-		// 2. Validate visit exists and is active
-		visit, err := dao.GetVisitByID(db, req.VisitID)
-		if err != nil {
-			c.JSON(404, gin.H{"error": "Visit not found"})
-			return
-		}
-		if visit.Status != model.StatusCheckedIn {
-			c.JSON(400, gin.H{"error": "Visit is not active"})
+		if !bindJSON(c, &req) {
 			return
 		}
 
-		// 3. Check for existing schedule (duplicate prevention)
-		existingSchedule, err := dao.GetScheduleByProfileAndDate(db, req.ProfileID, *scheduleDate)
-		if err == nil && existingSchedule != nil {
-			c.JSON(409, gin.H{"error": "Schedule already exists for this date"})
-			return
-		}
-
-		// 4. Parse UUIDs
-		profileUUID, err := uuid.Parse(req.ProfileID)
-		if err != nil {
-			c.JSON(400, gin.H{"error": "Invalid profile ID format"})
-			return
-		}
-
-		visitUUID, err := uuid.Parse(req.VisitID)
-		if err != nil {
-			c.JSON(400, gin.H{"error": "Invalid visit ID format"})
-			return
-		}
-
-		sevaType, err := dao.GetSevaTypeByName(db, req.SevaType)
-		if err != nil {
-			c.JSON(400, gin.H{logKeyError: "Invalid seva type or seva type not found"})
-			return
-		}
-
-		// 5. Create schedule
-		schedule, err := dao.AddSchedule(db, dao.AddScheduleRequest{
-			ProfileID:  profileUUID,
-			VisitID:    visitUUID,
-			SevaTypeID: sevaType.ID,
-			Location:   req.Location,
-			Date:       *scheduleDate,
+		schedule, err := svc.Schedules.Assign(service.AssignRequest{
+			ProfileID: uuid.MustParse(req.ProfileID),
+			VisitID:   uuid.MustParse(req.VisitID),
+			SevaType:  req.SevaType,
+			Location:  req.Location,
+			Date:      mustDate(req.Date),
 		})
 		if err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
+			writeServiceError(c, err)
 			return
 		}
 
@@ -384,23 +300,23 @@ func AddSchedule(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-func GetAllFeedbacks(db *gorm.DB) gin.HandlerFunc {
+func GetAllFeedbacks(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		feedbacks, err := dao.GetAllFeedbacks(db)
+		feedbacks, err := svc.Feedbacks.List()
 		if err != nil {
-			c.JSON(500, gin.H{logKeyError: err})
+			writeServiceError(c, err)
 			return
 		}
 		c.JSON(200, feedbacks)
 	}
 }
 
-func GetFeedbackForProfile(db *gorm.DB) gin.HandlerFunc {
+func GetFeedbackForProfile(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		profileID := c.Param("id")
-		feedbacks, err := dao.GetFeedbacksForProfile(db, profileID)
+		feedbacks, err := svc.Feedbacks.ListForProfile(profileID)
 		if err != nil {
-			c.JSON(500, gin.H{logKeyError: err})
+			writeServiceError(c, err)
 			return
 		}
 		c.JSON(200, feedbacks)
@@ -408,48 +324,29 @@ func GetFeedbackForProfile(db *gorm.DB) gin.HandlerFunc {
 }
 
 type AddFeedbackRequest struct {
-	ProfileID string  `json:"profile_id"`
-	VisitID   *string `json:"visit_id,omitempty"`
-	Content   string  `json:"content"`
-	Type      string  `json:"type"`
-	CreatedBy *string `json:"created_by,omitempty"`
+	ProfileID string  `json:"profile_id" binding:"required,uuid"`
+	VisitID   *string `json:"visit_id,omitempty" binding:"omitempty,uuid"`
+	Content   string  `json:"content" binding:"required"`
+	Type      string  `json:"type" binding:"required,feedback_type"`
+	CreatedBy *string `json:"created_by,omitempty" binding:"omitempty,max=255"`
 }
 
-func AddFeedback(db *gorm.DB) gin.HandlerFunc {
+func AddFeedback(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req AddFeedbackRequest
-		err := c.ShouldBindBodyWithJSON(&req)
-		if err != nil {
-			c.JSON(400, gin.H{logKeyError: "Invalid request body"})
-			return
-		}
-
-		profileUUID, err := uuid.Parse(req.ProfileID)
-		if err != nil {
-			c.JSON(400, gin.H{logKeyError: "Invalid profile ID format"})
+		if !bindJSON(c, &req) {
 			return
 		}
 
-		var visitUUID *uuid.UUID
-		if req.VisitID != nil {
-			parsedVisitID, err := uuid.Parse(*req.VisitID)
-			if err != nil {
-				c.JSON(400, gin.H{logKeyError: "Invalid visit ID format"})
-				return
-			}
-			visitUUID = &parsedVisitID
-		}
-
-		feedback, err := dao.AddFeedback(db, dao.AddFeedbackRequest{
-			ProfileID: profileUUID,
-			VisitID:   visitUUID,
+		feedback, err := svc.Feedbacks.Add(model.AddFeedbackRequest{
+			ProfileID: uuid.MustParse(req.ProfileID),
+			VisitID:   optionalUUID(req.VisitID),
 			Content:   req.Content,
 			Type:      model.FeedbackType(req.Type),
 			CreatedBy: req.CreatedBy,
 		})
-
 		if err != nil {
-			c.JSON(500, gin.H{logKeyError: err.Error()})
+			writeServiceError(c, err)
 			return
 		}
 
@@ -457,11 +354,11 @@ func AddFeedback(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-func GetAllSevaTypes(db *gorm.DB) gin.HandlerFunc {
+func GetAllSevaTypes(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		sevaTypes, err := dao.GetAllSevaTypes(db)
+		sevaTypes, err := svc.SevaTypes.List()
 		if err != nil {
-			c.JSON(500, gin.H{logKeyError: err.Error()})
+			writeServiceError(c, err)
 			return
 		}
 		c.JSON(200, sevaTypes)
@@ -469,25 +366,23 @@ func GetAllSevaTypes(db *gorm.DB) gin.HandlerFunc {
 }
 
 type AddSevaTypeRequest struct {
-	Name        string  `json:"name"`
+	Name        string  `json:"name" binding:"required,max=255"`
 	Description *string `json:"description,omitempty"`
 }
 
-func AddSevaType(db *gorm.DB) gin.HandlerFunc {
+func AddSevaType(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req AddSevaTypeRequest
-		err := c.ShouldBindBodyWithJSON(&req)
-		if err != nil {
-			c.JSON(400, gin.H{logKeyError: "Invalid request body"})
+		if !bindJSON(c, &req) {
 			return
 		}
 
-		sevaType, err := dao.AddSevaType(db, dao.AddSevaTypeRequest{
+		sevaType, err := svc.SevaTypes.Add(model.AddSevaTypeRequest{
 			Name:        req.Name,
 			Description: req.Description,
 		})
 		if err != nil {
-			c.JSON(500, gin.H{logKeyError: err.Error()})
+			writeServiceError(c, err)
 			return
 		}
 
@@ -495,11 +390,11 @@ func AddSevaType(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-func GetAllStayAreas(db *gorm.DB) gin.HandlerFunc {
+func GetAllStayAreas(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		stayAreas, err := dao.GetAllStayAreas(db)
+		stayAreas, err := svc.StayAreas.List()
 		if err != nil {
-			c.JSON(500, gin.H{logKeyError: err.Error()})
+			writeServiceError(c, err)
 			return
 		}
 		c.JSON(200, stayAreas)
@@ -507,25 +402,23 @@ func GetAllStayAreas(db *gorm.DB) gin.HandlerFunc {
 }
 
 type AddStayAreaRequest struct {
-	Name     string `json:"name"`
-	Capacity int    `json:"capacity"`
+	Name     string `json:"name" binding:"required,max=255"`
+	Capacity int    `json:"capacity" binding:"gt=0"`
 }
 
-func AddStayArea(db *gorm.DB) gin.HandlerFunc {
+func AddStayArea(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req AddStayAreaRequest
-		err := c.ShouldBindBodyWithJSON(&req)
-		if err != nil {
-			c.JSON(400, gin.H{logKeyError: "Invalid request body"})
+		if !bindJSON(c, &req) {
 			return
 		}
 
-		stayArea, err := dao.AddStayArea(db, dao.AddStayAreaRequest{
+		stayArea, err := svc.StayAreas.Add(model.AddStayAreaRequest{
 			Name:     req.Name,
 			Capacity: req.Capacity,
 		})
 		if err != nil {
-			c.JSON(500, gin.H{logKeyError: err.Error()})
+			writeServiceError(c, err)
 			return
 		}
 
@@ -541,11 +434,11 @@ type StayAreaOccupancyResponse struct {
 	Available            int    `json:"available"`
 }
 
-func GetStayAreaDetailsAndOccupancy(db *gorm.DB) gin.HandlerFunc {
+func GetStayAreaDetailsAndOccupancy(svc *service.Services) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		stayAreasWithOccupancy, err := dao.GetAllStayAreasWithOccupancy(db)
+		stayAreasWithOccupancy, err := svc.StayAreas.Occupancy()
 		if err != nil {
-			c.JSON(500, gin.H{logKeyError: err.Error()})
+			writeServiceError(c, err)
 			return
 		}
 
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"counterapp/internal/service"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// the time readiness checks get in total, well under the probe timeout
+const readyTimeout = 3 * time.Second
+
+// probe routes are logged at debug level when they succeed, they are hit
+// every few seconds
+var probeRoutes = map[string]bool{"/healthz": true, "/readyz": true}
+
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// liveness: the process is up and serving, nothing else is checked so a
+// database outage does not get the machine restarted
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// readiness: the database answers and its schema has every migration this
+// build knows. Failures are logged, the response only names the failing check.
+func Readyz(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readyTimeout)
+		defer cancel()
+
+		checks := map[string]string{"database": "ok", "migrations": "ok"}
+		ready := true
+		fail := func(check string, err error) {
+			requestLogger(c).Warn("readiness check failed", "check", check, "error", err)
+			checks[check] = "failing"
+			ready = false
+		}
+
+		if err := svc.Health.Database(ctx); err != nil {
+			fail("database", err)
+			checks["migrations"] = "unknown"
+		} else if err := svc.Health.Schema(ctx); err != nil {
+			fail("migrations", err)
+		}
+
+		if !ready {
+			c.JSON(http.StatusServiceUnavailable, HealthResponse{Status: "unavailable", Checks: checks})
+			return
+		}
+		c.JSON(http.StatusOK, HealthResponse{Status: "ok", Checks: checks})
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"counterapp/internal/importer"
+	"counterapp/internal/service"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxImportFileSize = 10 << 20
+
+// accepts the CSV either as a multipart "file" field or as the raw request body
+func ImportProfiles(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+		if err != nil {
+			invalidField(c, "dry_run", "Invalid dry_run value")
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+
+		var body io.Reader = c.Request.Body
+		if strings.HasPrefix(c.ContentType(), "multipart/") {
+			fileHeader, err := c.FormFile("file")
+			if err != nil {
+				invalidField(c, "file", "Missing import file")
+				return
+			}
+			file, err := fileHeader.Open()
+			if err != nil {
+				invalidField(c, "file", "Unable to read import file")
+				return
+			}
+			defer file.Close()
+			body = file
+		}
+
+		rows, err := importer.ParseProfiles(body)
+		if err != nil {
+			badRequest(c, err.Error())
+			return
+		}
+
+		report, err := importer.ImportProfiles(svc, rows, dryRun)
+		if err != nil {
+			internalError(c, err)
+			return
+		}
+		if !dryRun && !report.Committed {
+			writeError(c, 422, CodeValidation, "Import rejected, nothing was saved", importErrorDetails(report)...)
+			return
+		}
+		c.JSON(200, report)
+	}
+}
+
+// one detail per message of every rejected row, the field is the CSV row
+// number so clients can point at the offending line
+func importErrorDetails(report *importer.Report) []service.FieldError {
+	var details []service.FieldError
+	for _, row := range report.Rows {
+		if row.Status != importer.RowError {
+			continue
+		}
+		for _, msg := range row.Messages {
+			details = append(details, service.FieldError{Field: fmt.Sprintf("rows[%d]", row.Row), Message: msg})
+		}
+	}
+	return details
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// blank fields clear what was noted
+type MedicalRequest struct {
+	Notes        *string `json:"notes" binding:"omitempty,max=2000"`
+	DietaryNeeds *string `json:"dietary_needs" binding:"omitempty,max=500"`
+}
+
+func (in *MedicalRequest) toModel() *model.MedicalInfo {
+	if in == nil {
+		return nil
+	}
+	return &model.MedicalInfo{Notes: in.Notes, DietaryNeeds: in.DietaryNeeds}
+}
+
+// an empty object when nothing is noted
+func medicalResponse(medical *model.MedicalInfo) *model.MedicalInfo {
+	if medical == nil {
+		return &model.MedicalInfo{}
+	}
+	return medical
+}
+
+func GetMedical(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		medical, err := svc.Profiles.Medical(c.Param("id"))
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(200, medicalResponse(medical))
+	}
+}
+
+func SetMedical(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req MedicalRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+
+		medical, err := svc.Profiles.SetMedical(c.Param("id"), req.toModel())
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(200, medicalResponse(medical))
+	}
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"counterapp/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// limit is capped at service.MaxNotificationsListed and
+// service.MaxWebhookDeliveriesListed, both 500
+type LimitQuery struct {
+	Limit int `form:"limit,default=100" binding:"min=1,max=500"`
+}
+
+type NotificationsQuery struct {
+	Status string `form:"status" binding:"omitempty,delivery_status"`
+	Limit  int    `form:"limit,default=100" binding:"min=1,max=500"`
+}
+
+func GetNotifications(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query NotificationsQuery
+		if !bindQuery(c, &query) {
+			return
+		}
+
+		messages, err := svc.Notifications.List(query.Status, query.Limit)
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(200, messages)
+	}
+}
+
+func RetryNotification(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		msg, err := svc.Notifications.Retry(c.Param("id"))
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(200, msg)
+	}
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"counterapp/internal/service"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// the bundle is offered as a download, e.g. to pass on to the volunteer
+func GetPersonalData(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data, err := svc.Profiles.ExportPersonalData(c.Param("id"), actor(c))
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="personal_data_%s.json"`, data.Profile.ID))
+		c.JSON(200, data)
+	}
+}
+
+type EraseProfileRequest struct {
+	// the email of the profile, typed again to confirm
+	Confirm string `json:"confirm" binding:"required"`
+}
+
+func EraseProfile(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req EraseProfileRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+
+		profile, err := svc.Profiles.Erase(c.Param("id"), req.Confirm, actor(c))
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(200, profile)
+	}
+}
+
+// limit is capped at service.MaxAuditRecordsListed
+type AuditQuery struct {
+	Action   string `form:"action"`
+	EntityID string `form:"entity_id" binding:"omitempty,uuid"`
+	Limit    int    `form:"limit,default=100" binding:"min=1,max=500"`
+}
+
+func GetAuditRecords(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query AuditQuery
+		if !bindQuery(c, &query) {
+			return
+		}
+
+		records, err := svc.Audit.List(query.Action, query.EntityID, query.Limit)
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(200, records)
+	}
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"counterapp/internal/config"
+	"counterapp/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// what the retention rules would change if they ran now, whether or not the
+// background job is enabled
+func GetRetentionReport(svc *service.Services, cfg config.Retention) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, err := svc.Retention.Report(cfg, time.Now())
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(200, report)
+	}
+}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"bytes"
+	"counterapp/internal/model"
+	"counterapp/internal/report"
+	"counterapp/internal/service"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RollCallGroup struct {
+	StayArea  model.StayArea      `json:"stay_area"`
+	Total     int                 `json:"total"`
+	Accounted int                 `json:"accounted"`
+	Entries   []RollCallEntryView `json:"entries"`
+}
+
+// an entry with the medical info of the person, which the profile leaves
+// out. Only filled in for model.MedicalRoles.
+type RollCallEntryView struct {
+	model.RollCallEntry
+	Medical *model.MedicalInfo `json:",omitempty"`
+}
+
+// everyone to account for grouped by stay area. RollCall is nil for the
+// live listing, which is not stored.
+type RollCallResponse struct {
+	RollCall  *model.RollCall `json:"roll_call,omitempty"`
+	At        time.Time       `json:"at"`
+	Total     int             `json:"total"`
+	Accounted int             `json:"accounted"`
+	StayAreas []RollCallGroup `json:"stay_areas"`
+}
+
+// the entries with the medical info left out unless the caller may see it
+func entriesFor(c *gin.Context, entries []model.RollCallEntry) []model.RollCallEntry {
+	if mayReadMedical(c) {
+		return entries
+	}
+	stripped := make([]model.RollCallEntry, len(entries))
+	for i, e := range entries {
+		e.Visit.Profile.Medical = nil
+		stripped[i] = e
+	}
+	return stripped
+}
+
+// groups entries ordered by stay area, the roll call is returned without
+// its entries since they are in the groups
+func newRollCallResponse(c *gin.Context, rollCall *model.RollCall, at time.Time, entries []model.RollCallEntry) RollCallResponse {
+	entries = entriesFor(c, entries)
+	response := RollCallResponse{At: at, Total: len(entries), StayAreas: []RollCallGroup{}}
+	if rollCall != nil {
+		bare := *rollCall
+		bare.Entries = nil
+		response.RollCall = &bare
+	}
+
+	for _, e := range entries {
+		last := len(response.StayAreas) - 1
+		if last < 0 || response.StayAreas[last].StayArea.ID != e.StayAreaID {
+			response.StayAreas = append(response.StayAreas, RollCallGroup{StayArea: e.StayArea})
+			last++
+		}
+		group := &response.StayAreas[last]
+		group.Total++
+		group.Entries = append(group.Entries, RollCallEntryView{RollCallEntry: e, Medical: e.Visit.Profile.Medical})
+		if e.AccountedAt != nil {
+			group.Accounted++
+			response.Accounted++
+		}
+	}
+	return response
+}
+
+type RollCallQuery struct {
+	Format string `form:"format,default=json" binding:"oneof=json pdf"`
+}
+
+// writes the roll call as JSON or as a printable PDF
+func writeRollCall(c *gin.Context, format string, title string, rollCall *model.RollCall, at time.Time, entries []model.RollCallEntry) {
+	if format == "json" {
+		c.JSON(200, newRollCallResponse(c, rollCall, at, entries))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := report.RollCall(&buf, title, at, entriesFor(c, entries)); err != nil {
+		internalError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="roll_call_%s.pdf"`, at.Format("2006-01-02_1504")))
+	c.Data(200, "application/pdf", buf.Bytes())
+}
+
+// who is checked in right now, to print or call out without starting a
+// roll call
+func GetOnSite(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query RollCallQuery
+		if !bindQuery(c, &query) {
+			return
+		}
+
+		now := time.Now()
+		entries, err := svc.RollCalls.OnSite(now)
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		writeRollCall(c, query.Format, "On Site", nil, now, entries)
+	}
+}
+
+type StartRollCallRequest struct {
+	// e.g. "Fire drill"
+	Note *string `json:"note" binding:"omitempty,max=500"`
+}
+
+func StartRollCall(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req StartRollCallRequest
+		if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+			return
+		}
+
+		rollCall, err := svc.RollCalls.Start(req.Note, actor(c), time.Now())
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(201, newRollCallResponse(c, rollCall, rollCall.StartedAt, rollCall.Entries))
+	}
+}
+
+// limit is capped at service.MaxRollCallsListed
+type RollCallsQuery struct {
+	Limit int `form:"limit,default=50" binding:"min=1,max=500"`
+}
+
+func GetRollCalls(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query RollCallsQuery
+		if !bindQuery(c, &query) {
+			return
+		}
+
+		rollCalls, err := svc.RollCalls.List(query.Limit)
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(200, rollCalls)
+	}
+}
+
+func GetRollCall(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query RollCallQuery
+		if !bindQuery(c, &query) {
+			return
+		}
+
+		rollCall, err := svc.RollCalls.Get(c.Param("id"))
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		title := "Roll Call"
+		if rollCall.Note != nil && *rollCall.Note != "" {
+			title += ": " + *rollCall.Note
+		}
+		writeRollCall(c, query.Format, title, rollCall, rollCall.StartedAt, rollCall.Entries)
+	}
+}
+
+type MarkRollCallEntryRequest struct {
+	Accounted *bool `json:"accounted" binding:"required"`
+}
+
+func MarkRollCallEntry(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req MarkRollCallEntryRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+
+		entry, err := svc.RollCalls.Mark(c.Param("id"), c.Param("entry_id"), *req.Accounted, actor(c))
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(200, entry)
+	}
+}
+
+func CloseRollCall(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rollCall, err := svc.RollCalls.Close(c.Param("id"))
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(200, newRollCallResponse(c, rollCall, rollCall.StartedAt, rollCall.Entries))
+	}
+}
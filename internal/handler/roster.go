@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"bytes"
+	"counterapp/internal/report"
+	"counterapp/internal/service"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RosterQuery struct {
+	Date            string `form:"date" binding:"required,date"`
+	PagePerLocation bool   `form:"page_per_location"`
+}
+
+func GetRosterPDF(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query RosterQuery
+		if !bindQuery(c, &query) {
+			return
+		}
+		date := mustDate(query.Date)
+
+		schedules, err := svc.Schedules.ListForDateRange(date, date)
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+
+		var buf bytes.Buffer
+		err = report.Roster(&buf, date, schedules, report.RosterOptions{PagePerLocation: query.PagePerLocation})
+		if err != nil {
+			internalError(c, err)
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="roster_%s.pdf"`, query.Date))
+		c.Data(200, "application/pdf", buf.Bytes())
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"bytes"
+	"counterapp/internal/report"
+	"counterapp/internal/service"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SlipQuery struct {
+	Format string `form:"format,default=pdf" binding:"oneof=pdf png"`
+}
+
+func GetVisitSlip(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		visitID := c.Param("id")
+		if _, err := uuid.Parse(visitID); err != nil {
+			invalidField(c, "id", "Invalid visit ID format")
+			return
+		}
+
+		var query SlipQuery
+		if !bindQuery(c, &query) {
+			return
+		}
+		format := query.Format
+
+		visit, err := svc.Visits.GetDetails(visitID)
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+
+		var buf bytes.Buffer
+		contentType := "application/pdf"
+		if format == "png" {
+			contentType = "image/png"
+			err = report.SlipPNG(&buf, report.NewSlip(visit))
+		} else {
+			err = report.SlipPDF(&buf, report.NewSlip(visit))
+		}
+		if err != nil {
+			internalError(c, err)
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="slip_%s.%s"`, visit.ID, format))
+		c.Data(200, contentType, buf.Bytes())
+	}
+}
+
+// resolves a scanned slip QR code back to its visit
+func LookupVisitByCode(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		visitID, err := report.ParseQRPayload(c.Query("code"))
+		if err != nil {
+			invalidField(c, "code", "Invalid or missing code")
+			return
+		}
+
+		visit, err := svc.Visits.GetDetails(visitID.String())
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(200, visit)
+	}
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"counterapp/internal/dao"
+	"counterapp/internal/events"
+	"counterapp/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	streamHeartbeat  = 20 * time.Second
+	streamRetryMilli = 3000
+
+	// sent when a client cannot be resumed and has to reload its state
+	streamEventReset = "reset"
+)
+
+// streams domain events as Server-Sent Events. New clients, and clients that
+// cannot be resumed, first get a reset (when resuming) and an occupancy
+// snapshot. Reconnecting clients send Last-Event-ID and get what they missed.
+func StreamEvents(svc *service.Services, bus *events.Bus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := requestLogger(c)
+		filter, err := parseStreamFilter(c.Query("types"))
+		if err != nil {
+			invalidField(c, "types", err.Error())
+			return
+		}
+
+		lastEventID := c.GetHeader("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = c.Query("last_event_id")
+		}
+
+		sub, replay, resumed := bus.Subscribe(lastEventID)
+		defer sub.Close()
+
+		// the stream outlives the server write timeout by design
+		clearWriteDeadline(c)
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(200)
+
+		w := c.Writer
+		fmt.Fprintf(w, "retry: %d\n\n", streamRetryMilli)
+
+		if !resumed {
+			writeStreamEvent(logger, w, "", streamEventReset, gin.H{})
+		}
+		if !resumed || lastEventID == "" {
+			if filter.allows(events.OccupancyUpdated) {
+				stayAreas, err := svc.StayAreas.Occupancy()
+				if err != nil {
+					logger.Error("unable to load occupancy snapshot", "error", err)
+					return
+				}
+				occupancy := dao.OccupancyEventData(stayAreas)
+				writeStreamEvent(logger, w, "", string(events.OccupancyUpdated), events.New(events.OccupancyUpdated, occupancy))
+			}
+		}
+		for _, env := range replay {
+			if filter.allows(env.Event.Type) {
+				writeStreamEvent(logger, w, env.ID, string(env.Event.Type), env.Event)
+			}
+		}
+		w.Flush()
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case env, ok := <-sub.C:
+				if !ok {
+					// dropped for falling behind or closed on shutdown, the
+					// client reconnects and resumes
+					return
+				}
+				if !filter.allows(env.Event.Type) {
+					continue
+				}
+				writeStreamEvent(logger, w, env.ID, string(env.Event.Type), env.Event)
+				w.Flush()
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+				w.Flush()
+			}
+		}
+	}
+}
+
+type streamFilter map[events.Type]bool
+
+func (f streamFilter) allows(t events.Type) bool {
+	return len(f) == 0 || f[t]
+}
+
+func parseStreamFilter(types string) (streamFilter, error) {
+	filter := streamFilter{}
+	for _, t := range strings.Split(types, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		eventType := events.Type(t)
+		if !eventType.IsValid() && eventType != events.OccupancyUpdated {
+			return nil, fmt.Errorf("unknown event type: %s", t)
+		}
+		filter[eventType] = true
+	}
+	return filter, nil
+}
+
+// lifts the server write timeout for responses that stream for as long as
+// they need to
+func clearWriteDeadline(c *gin.Context) {
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		requestLogger(c).Warn("unable to clear write deadline", "error", err)
+	}
+}
+
+func writeStreamEvent(logger *slog.Logger, w io.Writer, id string, name string, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		logger.Error("unable to encode stream event", "event", name, "error", err)
+		return
+	}
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload)
+}
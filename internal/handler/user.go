@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AddUserRequest struct {
+	Name  string     `json:"name" binding:"required"`
+	Email string     `json:"email" binding:"required"`
+	Role  model.Role `json:"role" binding:"required"`
+}
+
+// the API token is only returned when the user is created
+type AddUserResponse struct {
+	model.User
+	Token string `json:"Token"`
+}
+
+func AddUser(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AddUserRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+
+		user, token, err := svc.Users.Create(req.Name, req.Email, req.Role)
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+
+		c.JSON(201, AddUserResponse{User: *user, Token: token})
+	}
+}
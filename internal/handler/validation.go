@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"counterapp/internal/events"
+	"counterapp/internal/model"
+	"counterapp/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// layout of every date accepted by the API
+const dateLayout = "2006-01-02"
+
+// allowed values of the enum tags, the tag names are used in binding tags
+// and the values come straight from internal/model
+var enumTags = map[string][]string{
+	"gender":               {string(model.GenderMale), string(model.GenderFemale), string(model.GenderOther)},
+	"category":             {string(model.CategorySTV), string(model.CategoryLTV), string(model.CategoryOverseas)},
+	"notification_channel": {string(model.ChannelEmail), string(model.ChannelSMS), string(model.ChannelWhatsApp)},
+	"visit_status":         {string(model.StatusCheckedIn), string(model.StatusPending), string(model.StatusCheckedOut)},
+	"feedback_type":        {string(model.TypePositive), string(model.TypeNegative), string(model.TypeNeutral)},
+	"delivery_status":      {string(model.DeliveryPending), string(model.DeliverySending), string(model.DeliverySent), string(model.DeliveryFailed)},
+	"event_type":           eventTypeNames(),
+}
+
+func eventTypeNames() []string {
+	names := make([]string, 0, len(events.Types))
+	for _, t := range events.Types {
+		names = append(names, string(t))
+	}
+	return names
+}
+
+var registerValidators sync.Once
+
+// adds the custom tags to gin's validator and makes it report fields by
+// their json (or query) name
+func setupValidator() {
+	registerValidators.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return f.Name
+		})
+
+		_ = v.RegisterValidation("date", func(fl validator.FieldLevel) bool {
+			_, err := time.Parse(dateLayout, fl.Field().String())
+			return err == nil
+		})
+		_ = v.RegisterValidation("date_gtefield", validateDateGTEField)
+
+		for tag, values := range enumTags {
+			_ = v.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+				value := fl.Field().String()
+				for _, allowed := range values {
+					if value == allowed {
+						return true
+					}
+				}
+				return false
+			})
+		}
+	})
+}
+
+// date_gtefield=Other passes when the date is on or after the date in the
+// sibling field Other, or when Other is empty or not a date (its own tags
+// report that)
+func validateDateGTEField(fl validator.FieldLevel) bool {
+	date, err := time.Parse(dateLayout, fl.Field().String())
+	if err != nil {
+		return false
+	}
+
+	other := fl.Parent()
+	if other.Kind() == reflect.Ptr {
+		other = other.Elem()
+	}
+	field := other.FieldByName(fl.Param())
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return true
+		}
+		field = field.Elem()
+	}
+	otherDate, err := time.Parse(dateLayout, field.String())
+	if err != nil {
+		return true
+	}
+	return !date.Before(otherDate)
+}
+
+// binds and validates the JSON body, writing a 400 with every invalid field
+// when it fails
+func bindJSON(c *gin.Context, obj any) bool {
+	setupValidator()
+	err := c.ShouldBindBodyWithJSON(obj)
+	if err == nil {
+		return true
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		invalidField(c, typeErr.Field, "must be "+jsonTypeName(typeErr.Type.Kind()))
+		return false
+	}
+	if !writeValidationErrors(c, err) {
+		invalidBody(c)
+	}
+	return false
+}
+
+// binds and validates the query string like bindJSON
+func bindQuery(c *gin.Context, obj any) bool {
+	setupValidator()
+	err := c.ShouldBindQuery(obj)
+	if err == nil {
+		return true
+	}
+	if !writeValidationErrors(c, err) {
+		badRequest(c, "Invalid query parameters")
+	}
+	return false
+}
+
+func writeValidationErrors(c *gin.Context, err error) bool {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return false
+	}
+
+	details := make([]service.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		details = append(details, service.FieldError{Field: fieldPath(fe), Message: validationMessage(fe)})
+	}
+	message := "Request has an invalid field"
+	if len(details) > 1 {
+		message = fmt.Sprintf("Request has %d invalid fields", len(details))
+	}
+	writeError(c, 400, CodeValidation, message, details...)
+	return true
+}
+
+// drops the struct name from the namespace, CreateProfileRequest.profile.email
+// becomes profile.email
+func fieldPath(fe validator.FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
+
+func validationMessage(fe validator.FieldError) string {
+	if values, ok := enumTags[fe.Tag()]; ok {
+		return "must be one of " + strings.Join(values, ", ")
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "uuid":
+		return "must be a valid UUID"
+	case "url", "http_url":
+		return "must be an http or https URL"
+	case "date":
+		return "must be a date in YYYY-MM-DD format"
+	case "date_gtefield":
+		return fmt.Sprintf("must not be before %s", jsonName(fe))
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters", fe.Param())
+		}
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at least %s items", fe.Param())
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters", fe.Param())
+		}
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at most %s items", fe.Param())
+		}
+		return "must be at most " + fe.Param()
+	}
+	return "is invalid"
+}
+
+func jsonTypeName(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	}
+	return "of a different type"
+}
+
+// the reported name of the field a cross-field tag points at, the DTOs name
+// fields after their snake_case keys so ArrivalDate becomes arrival_date
+func jsonName(fe validator.FieldError) string {
+	var name strings.Builder
+	for i, r := range fe.Param() {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				name.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		name.WriteRune(r)
+	}
+	return name.String()
+}
+
+// parses a date the binding tags already validated
+func mustDate(value string) time.Time {
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		panic(fmt.Sprintf("unvalidated date %q: %v", value, err))
+	}
+	return date
+}
+
+func optionalDate(value *string) *time.Time {
+	if value == nil {
+		return nil
+	}
+	date := mustDate(*value)
+	return &date
+}
+
+func optionalUUID(value *string) *uuid.UUID {
+	if value == nil {
+		return nil
+	}
+	id := uuid.MustParse(*value)
+	return &id
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AddWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,http_url"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,dive,event_type"`
+	Secret     *string  `json:"secret,omitempty" binding:"omitempty,min=16"`
+}
+
+// the secret is only returned when the subscription is created
+type AddWebhookResponse struct {
+	model.WebhookSubscription
+	Secret string `json:"Secret"`
+}
+
+func GetAllWebhooks(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscriptions, err := svc.Webhooks.List()
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(200, subscriptions)
+	}
+}
+
+func AddWebhook(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req AddWebhookRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+
+		subscribe := service.SubscribeRequest{URL: req.URL, EventTypes: req.EventTypes}
+		if req.Secret != nil {
+			subscribe.Secret = *req.Secret
+		}
+
+		subscription, secret, err := svc.Webhooks.Subscribe(subscribe)
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+
+		c.JSON(201, AddWebhookResponse{WebhookSubscription: *subscription, Secret: secret})
+	}
+}
+
+func DeleteWebhook(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := uuid.Parse(c.Param("id")); err != nil {
+			invalidField(c, "id", "Invalid webhook ID format")
+			return
+		}
+
+		if err := svc.Webhooks.Unsubscribe(c.Param("id")); err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.Status(204)
+	}
+}
+
+func GetWebhookDeliveries(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := uuid.Parse(c.Param("id")); err != nil {
+			invalidField(c, "id", "Invalid webhook ID format")
+			return
+		}
+
+		var query LimitQuery
+		if !bindQuery(c, &query) {
+			return
+		}
+
+		deliveries, err := svc.Webhooks.Deliveries(c.Param("id"), query.Limit)
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(200, deliveries)
+	}
+}
+
+func RedeliverWebhook(svc *service.Services) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := uuid.Parse(c.Param("id")); err != nil {
+			invalidField(c, "id", "Invalid delivery ID format")
+			return
+		}
+
+		delivery, err := svc.Webhooks.Redeliver(c.Param("id"))
+		if err != nil {
+			writeServiceError(c, err)
+			return
+		}
+		c.JSON(202, delivery)
+	}
+}
@@ -0,0 +1,410 @@
+package importer
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/service"
+	"counterapp/internal/util"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+type RowStatus string
+
+const (
+	RowCreated RowStatus = "created"
+	RowUpdated RowStatus = "updated"
+	RowSkipped RowStatus = "skipped"
+	RowError   RowStatus = "error"
+)
+
+type RowResult struct {
+	Row       int       `json:"row"`
+	Email     string    `json:"email,omitempty"`
+	Status    RowStatus `json:"status"`
+	ProfileID *string   `json:"profile_id,omitempty"`
+	VisitID   *string   `json:"visit_id,omitempty"`
+	Messages  []string  `json:"messages,omitempty"`
+}
+
+type Report struct {
+	DryRun    bool        `json:"dry_run"`
+	Committed bool        `json:"committed"`
+	Created   int         `json:"created"`
+	Updated   int         `json:"updated"`
+	Skipped   int         `json:"skipped"`
+	Errors    int         `json:"errors"`
+	Rows      []RowResult `json:"rows"`
+}
+
+// errDryRun rolls back the import transaction once the report is built
+var errDryRun = errors.New("dry run")
+
+// errRowsInvalid rolls back the import transaction when any row failed
+var errRowsInvalid = errors.New("import has invalid rows")
+
+var headerAliases = map[string]string{
+	"phone":          "phone_number",
+	"mobile":         "phone_number",
+	"full_name":      "name",
+	"arrival":        "arrival_date",
+	"departure":      "departure_date",
+	"stay_area_name": "stay_area",
+}
+
+// ProfileRow is one parsed line of the import file. Row numbers count the
+// header as row 1 so they match what the registration desk sees in a
+// spreadsheet.
+type ProfileRow struct {
+	row           int
+	name          string
+	email         string
+	phoneNumber   string
+	gender        model.Gender
+	category      model.Category
+	remarks       *string
+	arrivalDate   *time.Time
+	departureDate *time.Time
+	stayArea      string
+	errors        []string
+}
+
+func (r *ProfileRow) hasVisit() bool {
+	return r.arrivalDate != nil
+}
+
+// ParseProfiles reads a CSV with a header row. Required columns are name,
+// email and gender; category, phone_number and remarks are optional. A planned
+// visit is added when arrival_date and stay_area are present.
+func ParseProfiles(r io.Reader) ([]*ProfileRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("import file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		key = strings.ReplaceAll(key, " ", "_")
+		if alias, ok := headerAliases[key]; ok {
+			key = alias
+		}
+		columns[key] = i
+	}
+	for _, required := range []string{"name", "email", "gender"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing required column: %s", required)
+		}
+	}
+
+	var rows []*ProfileRow
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", line, err)
+		}
+		if isBlank(record) {
+			continue
+		}
+		rows = append(rows, parseProfileRow(line, record, columns))
+	}
+	return rows, nil
+}
+
+func parseProfileRow(line int, record []string, columns map[string]int) *ProfileRow {
+	get := func(key string) string {
+		i, ok := columns[key]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row := &ProfileRow{
+		row:         line,
+		name:        get("name"),
+		email:       strings.ToLower(get("email")),
+		phoneNumber: get("phone_number"),
+		stayArea:    get("stay_area"),
+	}
+
+	if row.name == "" {
+		row.errors = append(row.errors, "name is required")
+	}
+	if row.email == "" {
+		row.errors = append(row.errors, "email is required")
+	} else if _, err := mail.ParseAddress(row.email); err != nil {
+		row.errors = append(row.errors, fmt.Sprintf("invalid email: %s", row.email))
+	}
+
+	row.gender = model.Gender(matchEnum(get("gender"), model.GenderMale, model.GenderFemale, model.GenderOther))
+	if !row.gender.IsValid() {
+		row.errors = append(row.errors, fmt.Sprintf("invalid gender: %q", get("gender")))
+	}
+
+	if category := get("category"); category != "" {
+		row.category = model.Category(matchEnum(category, model.CategorySTV, model.CategoryLTV, model.CategoryOverseas))
+		if !row.category.IsValid() {
+			row.errors = append(row.errors, fmt.Sprintf("invalid category: %q", category))
+		}
+	}
+
+	if remarks := get("remarks"); remarks != "" {
+		row.remarks = &remarks
+	}
+
+	if arrival := get("arrival_date"); arrival != "" {
+		date, err := util.FormatDateToISO(arrival)
+		if err != nil {
+			row.errors = append(row.errors, fmt.Sprintf("invalid arrival_date: %q", arrival))
+		}
+		row.arrivalDate = date
+		if row.stayArea == "" {
+			row.errors = append(row.errors, "stay_area is required with arrival_date")
+		}
+	}
+	if departure := get("departure_date"); departure != "" {
+		date, err := util.FormatDateToISO(departure)
+		if err != nil {
+			row.errors = append(row.errors, fmt.Sprintf("invalid departure_date: %q", departure))
+		} else if row.arrivalDate == nil {
+			row.errors = append(row.errors, "departure_date requires arrival_date")
+		} else if !util.CompareDates(*row.arrivalDate, *date) {
+			row.errors = append(row.errors, "departure_date cannot be before arrival_date")
+		}
+		row.departureDate = date
+	}
+
+	return row
+}
+
+// ImportProfiles applies the parsed rows through the services in a single
+// transaction, so every row is held to the rules of the API. Existing
+// profiles are matched by email and updated. When dryRun is set, or any row
+// fails, nothing is committed but the report still describes every row.
+func ImportProfiles(svc *service.Services, rows []*ProfileRow, dryRun bool) (*Report, error) {
+	report := &Report{DryRun: dryRun}
+
+	err := svc.Transaction(func(tx *service.Services) error {
+		seen := make(map[string]int)
+		stayAreas := make(map[string]*model.StayArea)
+
+		for _, row := range rows {
+			result := RowResult{Row: row.row, Email: row.email, Messages: row.errors}
+			if firstRow, ok := seen[row.email]; ok && row.email != "" {
+				result.Messages = append(result.Messages, fmt.Sprintf("duplicate email, already on row %d", firstRow))
+			}
+			seen[row.email] = row.row
+
+			if len(result.Messages) == 0 {
+				if err := importProfileRow(tx, row, stayAreas, &result); err != nil {
+					return err
+				}
+			}
+			if len(result.Messages) > 0 && result.Status == "" {
+				result.Status = RowError
+			}
+
+			switch result.Status {
+			case RowCreated:
+				report.Created++
+			case RowUpdated:
+				report.Updated++
+			case RowSkipped:
+				report.Skipped++
+			case RowError:
+				report.Errors++
+			}
+			report.Rows = append(report.Rows, result)
+		}
+
+		if report.Errors > 0 {
+			return errRowsInvalid
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errDryRun) && !errors.Is(err, errRowsInvalid) {
+		return nil, err
+	}
+	report.Committed = err == nil
+	return report, nil
+}
+
+// records a rule the services refused on the row, which fails it. Other
+// errors are failures of the storage and abort the import.
+func rejectRow(result *RowResult, err error) error {
+	var serr *service.Error
+	if !errors.As(err, &serr) {
+		return err
+	}
+	result.Status = RowError
+	result.Messages = append(result.Messages, serr.Message)
+	return nil
+}
+
+// returns an error only for storage failures, validation problems are
+// recorded on the result
+func importProfileRow(tx *service.Services, row *ProfileRow, stayAreas map[string]*model.StayArea, result *RowResult) error {
+	var stayArea *model.StayArea
+	if row.hasVisit() {
+		var err error
+		stayArea, err = findStayArea(tx, row.stayArea, stayAreas)
+		if err != nil {
+			return err
+		}
+		if stayArea == nil {
+			result.Messages = append(result.Messages, fmt.Sprintf("stay area not found: %s", row.stayArea))
+			return nil
+		}
+	}
+
+	existing, err := tx.Profiles.FindByEmail(row.email)
+	if err != nil {
+		return err
+	}
+
+	var profile *model.Profile
+	switch {
+	case existing == nil:
+		profile, err = tx.Profiles.Create(&model.Profile{
+			Name:        row.name,
+			Email:       row.email,
+			PhoneNumber: row.phoneNumber,
+			Gender:      row.gender,
+			Category:    row.category,
+			Remarks:     row.remarks,
+		})
+		if err != nil {
+			return rejectRow(result, err)
+		}
+		result.Status = RowCreated
+	case profileChanged(existing, row):
+		update := &model.ProfileUpdate{
+			Name:   &row.name,
+			Gender: &row.gender,
+		}
+		if row.phoneNumber != "" {
+			update.PhoneNumber = &row.phoneNumber
+		}
+		if row.category != "" {
+			update.Category = &row.category
+		}
+		if row.remarks != nil {
+			update.Remarks = row.remarks
+		}
+		profile, err = tx.Profiles.Update(existing.ID.String(), update)
+		if err != nil {
+			return rejectRow(result, err)
+		}
+		result.Status = RowUpdated
+	default:
+		profile = existing
+		result.Status = RowSkipped
+		result.Messages = append(result.Messages, "profile unchanged")
+	}
+
+	profileID := profile.ID.String()
+	result.ProfileID = &profileID
+
+	if !row.hasVisit() {
+		return nil
+	}
+
+	visit, err := tx.Visits.GetByArrival(profileID, *row.arrivalDate)
+	if err != nil {
+		return err
+	}
+	if visit != nil {
+		result.Messages = append(result.Messages, "visit for arrival date already exists")
+	} else {
+		visit, err = tx.Visits.Plan(service.PlanVisitRequest{
+			ProfileID:     profile.ID,
+			ArrivalDate:   *row.arrivalDate,
+			DepartureDate: row.departureDate,
+			StayAreaID:    stayArea.ID,
+		})
+		if err != nil {
+			return rejectRow(result, err)
+		}
+		if result.Status == RowSkipped {
+			result.Status = RowUpdated
+			result.Messages = nil
+		}
+		result.Messages = append(result.Messages, "planned visit added")
+	}
+	visitID := visit.ID.String()
+	result.VisitID = &visitID
+	return nil
+}
+
+func findStayArea(tx *service.Services, name string, cache map[string]*model.StayArea) (*model.StayArea, error) {
+	if stayArea, ok := cache[name]; ok {
+		return stayArea, nil
+	}
+	stayArea, err := tx.StayAreas.GetByName(name)
+	var serr *service.Error
+	if errors.As(err, &serr) && serr.Kind == service.KindNotFound {
+		stayArea, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cache[name] = stayArea
+	return stayArea, nil
+}
+
+func profileChanged(p *model.Profile, row *ProfileRow) bool {
+	if p.Name != row.name || p.Gender != row.gender {
+		return true
+	}
+	if row.phoneNumber != "" && p.PhoneNumber != row.phoneNumber {
+		return true
+	}
+	if row.category != "" && p.Category != row.category {
+		return true
+	}
+	if row.remarks != nil && (p.Remarks == nil || *p.Remarks != *row.remarks) {
+		return true
+	}
+	return false
+}
+
+// matches value case-insensitively against the allowed values and returns
+// the canonical spelling, or value unchanged when nothing matches
+func matchEnum[T ~string](value string, allowed ...T) string {
+	for _, a := range allowed {
+		if strings.EqualFold(value, string(a)) {
+			return string(a)
+		}
+	}
+	return value
+}
+
+func isBlank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package logging
+
+import (
+	"context"
+	"counterapp/internal/config"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger writes GORM's statements and messages to a slog logger. Failed
+// statements are logged at sql level error and up, slow ones at warn and
+// up and every statement at info.
+type GormLogger struct {
+	logger *slog.Logger
+	level  gormlogger.LogLevel
+	slow   time.Duration
+	params bool
+}
+
+func NewGormLogger(logger *slog.Logger, cfg config.Log) *GormLogger {
+	return &GormLogger{
+		logger: logger.With("component", "sql"),
+		level:  sqlLevel(cfg.SQLLevel),
+		slow:   cfg.SlowQuery,
+		params: cfg.SQLParams,
+	}
+}
+
+func sqlLevel(name string) gormlogger.LogLevel {
+	switch name {
+	case "silent":
+		return gormlogger.Silent
+	case "error":
+		return gormlogger.Error
+	case "info":
+		return gormlogger.Info
+	}
+	return gormlogger.Warn
+}
+
+func (g *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *g
+	copied.level = level
+	return &copied
+}
+
+func (g *GormLogger) Info(ctx context.Context, msg string, args ...any) {
+	if g.level >= gormlogger.Info {
+		g.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (g *GormLogger) Warn(ctx context.Context, msg string, args ...any) {
+	if g.level >= gormlogger.Warn {
+		g.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (g *GormLogger) Error(ctx context.Context, msg string, args ...any) {
+	if g.level >= gormlogger.Error {
+		g.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (g *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if g.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && g.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		// broken constraints are answered with 409 or 400, they are expected
+		level := slog.LevelError
+		if errors.Is(err, gorm.ErrDuplicatedKey) || errors.Is(err, gorm.ErrForeignKeyViolated) || errors.Is(err, gorm.ErrCheckConstraintViolated) {
+			level = slog.LevelWarn
+		}
+		g.log(ctx, level, "sql failed", elapsed, fc, slog.String("error", err.Error()))
+	case g.slow > 0 && elapsed > g.slow && g.level >= gormlogger.Warn:
+		g.log(ctx, slog.LevelWarn, "slow sql", elapsed, fc, slog.Duration("threshold", g.slow))
+	case g.level >= gormlogger.Info:
+		g.log(ctx, slog.LevelInfo, "sql", elapsed, fc)
+	}
+}
+
+func (g *GormLogger) log(ctx context.Context, level slog.Level, msg string, elapsed time.Duration, fc func() (string, int64), attrs ...slog.Attr) {
+	if !g.logger.Enabled(ctx, level) {
+		return
+	}
+	sql, rows := fc()
+	attrs = append(attrs,
+		slog.String("sql", sql),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	)
+	if rows >= 0 {
+		attrs = append(attrs, slog.Int64("rows", rows))
+	}
+	g.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// keeps parameters, which hold personal data, out of logged statements
+// unless log.sql_params is on
+func (g *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any) {
+	if g.params {
+		return sql, params
+	}
+	return sql, nil
+}
@@ -0,0 +1,35 @@
+// Package logging builds the structured logger every part of the app writes
+// to, and adapts it for GORM so SQL statements end up in the same stream.
+package logging
+
+import (
+	"counterapp/internal/config"
+	"io"
+	"log/slog"
+)
+
+// a logger writing JSON or text lines at the configured level
+func New(cfg config.Log, w io.Writer) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level(cfg.Level)}
+	if cfg.Format == "text" {
+		return slog.New(slog.NewTextHandler(w, opts))
+	}
+	return slog.New(slog.NewJSONHandler(w, opts))
+}
+
+// drops everything, for services built without a logger
+func Discard() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+func level(name string) slog.Level {
+	switch name {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"context"
+	"counterapp/internal/dao"
+	"counterapp/internal/model"
+	"counterapp/internal/util"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// bounds the queries of one scrape so a slow database does not pile up
+// scrapes
+const scrapeTimeout = 5 * time.Second
+
+var genders = []model.Gender{model.GenderMale, model.GenderFemale, model.GenderOther}
+
+// reads the domain numbers from the database when scraped, so every
+// instance reports the same values and nothing is lost on restarts
+type domainCollector struct {
+	db     *gorm.DB
+	logger *slog.Logger
+
+	checkedIn      *prometheus.Desc
+	capacity       *prometheus.Desc
+	lockersFree    *prometheus.Desc
+	lockers        *prometheus.Desc
+	overdue        *prometheus.Desc
+	checkInsTotal  *prometheus.Desc
+	checkOutsTotal *prometheus.Desc
+	schedulesTotal *prometheus.Desc
+	checkInsToday  *prometheus.Desc
+	checkOutsToday *prometheus.Desc
+	schedulesToday *prometheus.Desc
+}
+
+func newDomainCollector(db *gorm.DB, logger *slog.Logger) *domainCollector {
+	desc := func(name string, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc("counterapp_"+name, help, labels, nil)
+	}
+	return &domainCollector{
+		db:     db,
+		logger: logger,
+
+		checkedIn:      desc("checked_in_visits", "Visits currently checked in, by stay area and gender.", "stay_area", "gender"),
+		capacity:       desc("stay_area_capacity", "Beds in the stay area.", "stay_area"),
+		lockersFree:    desc("lockers_free", "Unoccupied lockers, by section.", "section"),
+		lockers:        desc("lockers", "Lockers, by section.", "section"),
+		overdue:        desc("overdue_visits", "Visits still checked in after their departure date."),
+		checkInsTotal:  desc("check_ins", "Visits checked in that are still stored; purged visits drop out."),
+		checkOutsTotal: desc("check_outs", "Visits checked out that are still stored; purged visits drop out."),
+		schedulesTotal: desc("schedules_created", "Schedules that are still stored; purged schedules drop out."),
+		checkInsToday:  desc("check_ins_today", "Visits checked in since midnight in the configured time zone."),
+		checkOutsToday: desc("check_outs_today", "Visits checked out since midnight in the configured time zone."),
+		schedulesToday: desc("schedules_created_today", "Schedules created since midnight in the configured time zone."),
+	}
+}
+
+func (d *domainCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		d.checkedIn, d.capacity, d.lockersFree, d.lockers, d.overdue,
+		d.checkInsTotal, d.checkOutsTotal, d.schedulesTotal,
+		d.checkInsToday, d.checkOutsToday, d.schedulesToday,
+	} {
+		ch <- desc
+	}
+}
+
+func (d *domainCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+
+	now := time.Now()
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	snapshot, err := dao.GetMetricsSnapshot(d.db.WithContext(ctx), util.Day(now), startOfToday)
+	if err != nil {
+		d.logger.Error("unable to read domain metrics", "error", err)
+		ch <- prometheus.NewInvalidMetric(d.checkedIn, err)
+		return
+	}
+
+	// every stay area and gender is reported, empty ones as 0, so series do
+	// not vanish when an area empties
+	checkedIn := map[[2]string]int{}
+	for _, c := range snapshot.CheckedIn {
+		checkedIn[[2]string{c.StayArea, c.Gender}] = c.Count
+	}
+	for _, sa := range snapshot.StayAreas {
+		ch <- prometheus.MustNewConstMetric(d.capacity, prometheus.GaugeValue, float64(sa.Capacity), sa.Name)
+		for _, gender := range genders {
+			count := checkedIn[[2]string{sa.Name, string(gender)}]
+			ch <- prometheus.MustNewConstMetric(d.checkedIn, prometheus.GaugeValue, float64(count), sa.Name, string(gender))
+		}
+	}
+	for _, section := range snapshot.Lockers {
+		ch <- prometheus.MustNewConstMetric(d.lockersFree, prometheus.GaugeValue, float64(section.Free), section.Section)
+		ch <- prometheus.MustNewConstMetric(d.lockers, prometheus.GaugeValue, float64(section.Total), section.Section)
+	}
+
+	ch <- prometheus.MustNewConstMetric(d.overdue, prometheus.GaugeValue, float64(snapshot.Overdue))
+	ch <- prometheus.MustNewConstMetric(d.checkInsTotal, prometheus.GaugeValue, float64(snapshot.CheckInsTotal))
+	ch <- prometheus.MustNewConstMetric(d.checkOutsTotal, prometheus.GaugeValue, float64(snapshot.CheckOutsTotal))
+	ch <- prometheus.MustNewConstMetric(d.schedulesTotal, prometheus.GaugeValue, float64(snapshot.SchedulesTotal))
+	ch <- prometheus.MustNewConstMetric(d.checkInsToday, prometheus.GaugeValue, float64(snapshot.CheckInsToday))
+	ch <- prometheus.MustNewConstMetric(d.checkOutsToday, prometheus.GaugeValue, float64(snapshot.CheckOutsToday))
+	ch <- prometheus.MustNewConstMetric(d.schedulesToday, prometheus.GaugeValue, float64(snapshot.SchedulesToday))
+}
@@ -0,0 +1,285 @@
+// Package migrations applies the versioned SQL migrations embedded in the
+// binary and records them in the schema_migrations table.
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// serializes migrations between app machines starting at the same time
+const advisoryLockID = 727_001
+
+// returned when the database has migrations this binary does not know,
+// usually because a newer release already migrated it
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary")
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// one row of schema_migrations, the name is empty and Known false for
+// versions applied by a newer binary
+type Status struct {
+	Version   int
+	Name      string
+	Known     bool
+	AppliedAt *time.Time
+}
+
+type schemaMigration struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"not null"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// the embedded migrations in version order
+func All() ([]Migration, error) {
+	dir, err := fs.Sub(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return load(dir)
+}
+
+// pairs the up and down files at the root of fsys by version
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names, %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// applies every pending migration, each in its own transaction, and returns
+// the ones applied. Refuses with ErrSchemaTooNew when the database has
+// versions this binary does not know.
+func Up(db *gorm.DB) ([]Migration, error) {
+	migrations, err := prepare(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, m := range migrations {
+		done, err := apply(db, m.Version, func(tx *gorm.DB, isApplied bool) (bool, error) {
+			if isApplied {
+				return false, nil
+			}
+			if err := tx.Exec(m.Up).Error; err != nil {
+				return false, fmt.Errorf("migration %d_%s up: %w", m.Version, m.Name, err)
+			}
+			return true, tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return applied, err
+		}
+		if done {
+			applied = append(applied, m)
+		}
+	}
+	return applied, nil
+}
+
+// reverts the latest steps applied migrations, newest first, and returns the
+// ones reverted
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	migrations, err := prepare(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		m := migrations[i]
+		done, err := apply(db, m.Version, func(tx *gorm.DB, isApplied bool) (bool, error) {
+			if !isApplied {
+				return false, nil
+			}
+			if err := tx.Exec(m.Down).Error; err != nil {
+				return false, fmt.Errorf("migration %d_%s down: %w", m.Version, m.Name, err)
+			}
+			return true, tx.Delete(&schemaMigration{}, "version = ?", m.Version).Error
+		})
+		if err != nil {
+			return reverted, err
+		}
+		if done {
+			reverted = append(reverted, m)
+		}
+	}
+	return reverted, nil
+}
+
+// every known migration and every applied one, in version order. Nothing is
+// written, so readiness probes can call it through Check.
+func StatusOf(db *gorm.DB) ([]Status, error) {
+	migrations, err := All()
+	if err != nil {
+		return nil, err
+	}
+
+	// a database that was never migrated has no table yet, every migration
+	// is pending
+	var exists bool
+	if err := db.Raw("SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists).Error; err != nil {
+		return nil, err
+	}
+	var rows []schemaMigration
+	if exists {
+		if err := db.Order("version").Find(&rows).Error; err != nil {
+			return nil, err
+		}
+	}
+	return statusesOf(migrations, rows), nil
+}
+
+func statusesOf(migrations []Migration, rows []schemaMigration) []Status {
+	applied := map[int]schemaMigration{}
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	var statuses []Status
+	for _, m := range migrations {
+		status := Status{Version: m.Version, Name: m.Name, Known: true}
+		if row, ok := applied[m.Version]; ok {
+			status.AppliedAt = &row.AppliedAt
+			delete(applied, m.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, row := range applied {
+		statuses = append(statuses, Status{Version: row.Version, Name: row.Name, AppliedAt: &row.AppliedAt})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses
+}
+
+// returns nil when every known migration is applied and no unknown one is,
+// without changing the database
+func Check(db *gorm.DB) error {
+	statuses, err := StatusOf(db)
+	if err != nil {
+		return err
+	}
+	return checkApplied(statuses)
+}
+
+func checkApplied(statuses []Status) error {
+	if err := checkKnown(statuses); err != nil {
+		return err
+	}
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			return fmt.Errorf("migration %d_%s is pending", s.Version, s.Name)
+		}
+	}
+	return nil
+}
+
+func prepare(db *gorm.DB) ([]Migration, error) {
+	if err := ensureTable(db); err != nil {
+		return nil, err
+	}
+	statuses, err := StatusOf(db)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkKnown(statuses); err != nil {
+		return nil, err
+	}
+	return All()
+}
+
+func checkKnown(statuses []Status) error {
+	for _, s := range statuses {
+		if !s.Known {
+			return fmt.Errorf("%w: version %d is applied but unknown", ErrSchemaTooNew, s.Version)
+		}
+	}
+	return nil
+}
+
+// runs fn in a transaction holding the migration lock, telling it whether
+// version is applied as seen under the lock
+func apply(db *gorm.DB, version int, fn func(tx *gorm.DB, isApplied bool) (bool, error)) (bool, error) {
+	var done bool
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", advisoryLockID).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&schemaMigration{}).Where("version = ?", version).Count(&count).Error; err != nil {
+			return err
+		}
+		var err error
+		done, err = fn(tx, count > 0)
+		return err
+	})
+	return done, err
+}
+
+func ensureTable(db *gorm.DB) error {
+	return db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    bigint PRIMARY KEY,
+		name       text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`).Error
+}
@@ -0,0 +1,135 @@
+package migrations
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// the embedded migrations are numbered from 1 without gaps, so a release
+// never skips a version another release may have applied
+func TestEmbeddedMigrations(t *testing.T) {
+	all, err := All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, m := range all {
+		if m.Version != i+1 {
+			t.Fatalf("migration %d_%s is number %d in order, want versions 1 to %d", m.Version, m.Name, i+1, len(all))
+		}
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			t.Errorf("migration %d_%s has an empty up or down file", m.Version, m.Name)
+		}
+	}
+}
+
+func file(body string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(body)}
+}
+
+func TestLoadPairsFilesInVersionOrder(t *testing.T) {
+	all, err := load(fstest.MapFS{
+		"0010_lockers.up.sql":    file("CREATE TABLE lockers ();"),
+		"0010_lockers.down.sql":  file("DROP TABLE lockers;"),
+		"0002_visits.down.sql":   file("DROP TABLE visits;"),
+		"0002_visits.up.sql":     file("CREATE TABLE visits ();"),
+		"0001_profiles.up.sql":   file("CREATE TABLE profiles ();"),
+		"0001_profiles.down.sql": file("DROP TABLE profiles;"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Migration{
+		{Version: 1, Name: "profiles", Up: "CREATE TABLE profiles ();", Down: "DROP TABLE profiles;"},
+		{Version: 2, Name: "visits", Up: "CREATE TABLE visits ();", Down: "DROP TABLE visits;"},
+		{Version: 10, Name: "lockers", Up: "CREATE TABLE lockers ();", Down: "DROP TABLE lockers;"},
+	}
+	if len(all) != len(want) {
+		t.Fatalf("loaded %d migrations, want %d", len(all), len(want))
+	}
+	for i := range want {
+		if all[i] != want[i] {
+			t.Errorf("migration %d is %+v, want %+v", i, all[i], want[i])
+		}
+	}
+}
+
+func TestLoadRejectsBrokenSets(t *testing.T) {
+	cases := []struct {
+		name    string
+		files   fstest.MapFS
+		wantErr string
+	}{
+		{
+			"missing down",
+			fstest.MapFS{"0001_profiles.up.sql": file("CREATE TABLE profiles ();")},
+			"migration 1_profiles needs both an up and a down file",
+		},
+		{
+			"missing up",
+			fstest.MapFS{"0001_profiles.down.sql": file("DROP TABLE profiles;")},
+			"migration 1_profiles needs both an up and a down file",
+		},
+		{
+			"two names for a version",
+			fstest.MapFS{
+				"0001_profiles.up.sql": file("CREATE TABLE profiles ();"),
+				"0001_people.down.sql": file("DROP TABLE profiles;"),
+			},
+			"migration 1 has two names",
+		},
+		{
+			"unexpected file",
+			fstest.MapFS{"0001_profiles.sql": file("CREATE TABLE profiles ();")},
+			"unexpected migration file 0001_profiles.sql",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := load(tc.files)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("load returned %v, want an error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckApplied(t *testing.T) {
+	known := []Migration{{Version: 1, Name: "profiles"}, {Version: 2, Name: "visits"}}
+	applied := func(versions ...int) []schemaMigration {
+		var rows []schemaMigration
+		for _, v := range versions {
+			rows = append(rows, schemaMigration{Version: v, Name: fmt.Sprint("m", v), AppliedAt: time.Now()})
+		}
+		return rows
+	}
+
+	cases := []struct {
+		name    string
+		rows    []schemaMigration
+		wantErr string
+	}{
+		// no schema_migrations table reads as no rows
+		{"never migrated", nil, "migration 1_profiles is pending"},
+		{"one pending", applied(1), "migration 2_visits is pending"},
+		{"all applied", applied(1, 2), ""},
+		{"newer schema", applied(1, 2, 3), "version 3 is applied but unknown"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkApplied(statusesOf(known, tc.rows))
+			switch {
+			case tc.wantErr == "" && err != nil:
+				t.Fatalf("got %v, want no error", err)
+			case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+				t.Fatalf("got %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
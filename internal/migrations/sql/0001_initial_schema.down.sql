@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
+DROP TABLE IF EXISTS outbox_messages;
+DROP TABLE IF EXISTS feedbacks;
+DROP TABLE IF EXISTS schedules;
+DROP TABLE IF EXISTS visits;
+DROP TABLE IF EXISTS seva_types;
+DROP TABLE IF EXISTS stay_areas;
+DROP TABLE IF EXISTS lockers;
+DROP TABLE IF EXISTS profiles;
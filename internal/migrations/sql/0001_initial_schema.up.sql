@@ -0,0 +1,144 @@
+-- Tables as AutoMigrate created them before versioned migrations. Every
+-- statement is IF NOT EXISTS so databases created by AutoMigrate adopt this
+-- version without changes.
+
+CREATE TABLE IF NOT EXISTS profiles (
+    id                    uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name                  text NOT NULL,
+    email                 text NOT NULL,
+    phone_number          text,
+    gender                varchar(10) NOT NULL,
+    category              varchar(300),
+    is_blocked            boolean DEFAULT false,
+    remarks               text,
+    created_at            timestamptz,
+    updated_at            timestamptz,
+    notification_channel  varchar(20) NOT NULL DEFAULT 'email',
+    notifications_opt_out boolean DEFAULT false,
+    CONSTRAINT uni_profiles_email UNIQUE (email)
+);
+
+CREATE TABLE IF NOT EXISTS lockers (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    locker_number text NOT NULL,
+    section       text NOT NULL,
+    is_occupied   boolean DEFAULT false,
+    created_at    timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS stay_areas (
+    id       uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name     text NOT NULL,
+    capacity bigint NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS seva_types (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        text NOT NULL,
+    description text,
+    is_active   boolean DEFAULT true,
+    created_at  timestamptz,
+    CONSTRAINT uni_seva_types_name UNIQUE (name)
+);
+
+CREATE TABLE IF NOT EXISTS visits (
+    id             uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    profile_id     uuid NOT NULL,
+    arrival_date   timestamptz NOT NULL,
+    departure_date timestamptz DEFAULT NULL,
+    stay_area_id   uuid NOT NULL,
+    status         varchar(20) NOT NULL DEFAULT 'pending',
+    locker_id      uuid,
+    remarks        text,
+    created_at     timestamptz,
+    CONSTRAINT fk_visits_profile FOREIGN KEY (profile_id) REFERENCES profiles (id),
+    CONSTRAINT fk_visits_stay_area FOREIGN KEY (stay_area_id) REFERENCES stay_areas (id),
+    CONSTRAINT fk_visits_locker FOREIGN KEY (locker_id) REFERENCES lockers (id)
+);
+CREATE INDEX IF NOT EXISTS idx_visits_profile_id ON visits (profile_id);
+CREATE INDEX IF NOT EXISTS idx_visits_stay_area_id ON visits (stay_area_id);
+CREATE INDEX IF NOT EXISTS idx_visits_locker_id ON visits (locker_id);
+
+CREATE TABLE IF NOT EXISTS schedules (
+    id           uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    profile_id   uuid NOT NULL,
+    visit_id     uuid NOT NULL,
+    date         timestamptz NOT NULL,
+    seva_type_id uuid NOT NULL,
+    location     varchar(200),
+    notes        text,
+    created_at   timestamptz,
+    CONSTRAINT fk_schedules_profile FOREIGN KEY (profile_id) REFERENCES profiles (id),
+    CONSTRAINT fk_schedules_visit FOREIGN KEY (visit_id) REFERENCES visits (id),
+    CONSTRAINT fk_schedules_seva_type FOREIGN KEY (seva_type_id) REFERENCES seva_types (id)
+);
+CREATE INDEX IF NOT EXISTS idx_schedules_profile_id ON schedules (profile_id);
+CREATE INDEX IF NOT EXISTS idx_schedules_visit_id ON schedules (visit_id);
+CREATE INDEX IF NOT EXISTS idx_schedules_seva_type_id ON schedules (seva_type_id);
+
+CREATE TABLE IF NOT EXISTS feedbacks (
+    id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    profile_id uuid NOT NULL,
+    visit_id   uuid,
+    content    text NOT NULL,
+    type       varchar(20) NOT NULL,
+    created_by text,
+    created_at timestamptz,
+    CONSTRAINT fk_feedbacks_profile FOREIGN KEY (profile_id) REFERENCES profiles (id),
+    CONSTRAINT fk_feedbacks_visit FOREIGN KEY (visit_id) REFERENCES visits (id)
+);
+CREATE INDEX IF NOT EXISTS idx_feedbacks_profile_id ON feedbacks (profile_id);
+CREATE INDEX IF NOT EXISTS idx_feedbacks_visit_id ON feedbacks (visit_id);
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id              uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    profile_id      uuid,
+    channel         varchar(20) NOT NULL DEFAULT 'email',
+    template        varchar(50) NOT NULL,
+    recipient       text NOT NULL,
+    subject         text NOT NULL DEFAULT '',
+    body            text NOT NULL,
+    dedup_key       text,
+    status          varchar(20) NOT NULL DEFAULT 'pending',
+    attempts        bigint NOT NULL DEFAULT 0,
+    last_error      text,
+    next_attempt_at timestamptz NOT NULL,
+    sent_at         timestamptz,
+    created_at      timestamptz,
+    updated_at      timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_profile_id ON outbox_messages (profile_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_messages_dedup_key ON outbox_messages (dedup_key);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_status ON outbox_messages (status);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_next_attempt_at ON outbox_messages (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    url         text NOT NULL,
+    secret      text NOT NULL,
+    event_types jsonb NOT NULL,
+    is_active   boolean DEFAULT true,
+    created_at  timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id               uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id  uuid NOT NULL,
+    event_id         uuid NOT NULL,
+    event_type       varchar(50) NOT NULL,
+    payload          jsonb NOT NULL,
+    status           varchar(20) NOT NULL DEFAULT 'pending',
+    attempts         bigint NOT NULL DEFAULT 0,
+    last_status_code bigint,
+    last_error       text,
+    next_attempt_at  timestamptz NOT NULL,
+    delivered_at     timestamptz,
+    redelivery_of    uuid,
+    created_at       timestamptz,
+    updated_at       timestamptz,
+    CONSTRAINT fk_webhook_deliveries_subscription FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions (id)
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_id ON webhook_deliveries (subscription_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_event_id ON webhook_deliveries (event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries (status);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at);
@@ -0,0 +1,14 @@
+ALTER TABLE feedbacks DROP CONSTRAINT IF EXISTS chk_feedbacks_type;
+ALTER TABLE stay_areas DROP CONSTRAINT IF EXISTS chk_stay_areas_capacity;
+ALTER TABLE visits
+    DROP CONSTRAINT IF EXISTS chk_visits_departure_date,
+    DROP CONSTRAINT IF EXISTS chk_visits_status;
+
+DROP INDEX IF EXISTS idx_schedules_profile_date;
+DROP INDEX IF EXISTS idx_visits_one_checked_in;
+
+-- the rows fixed on the way up stay as they are
+DROP TABLE IF EXISTS migration_conflicts;
+
+-- the old column comes back empty, its values were never read
+ALTER TABLE visits ADD COLUMN IF NOT EXISTS stay_area text;
@@ -0,0 +1,102 @@
+-- visits.stay_area was replaced by stay_area_id but AutoMigrate never drops
+-- columns
+ALTER TABLE visits DROP COLUMN IF EXISTS stay_area;
+
+-- rows this migration changed or could not check are recorded here, with
+-- the row as it was, so they can be reviewed and fixed by hand
+CREATE TABLE migration_conflicts (
+    migration  text NOT NULL,
+    table_name text NOT NULL,
+    row_id     uuid NOT NULL,
+    problem    text NOT NULL,
+    original   jsonb NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- a person is only checked in once at a time. Older duplicate check-ins are
+-- checked out, keeping the latest arrival, before the index is built.
+WITH ranked AS (
+    SELECT id, row_number() OVER (
+        PARTITION BY profile_id
+        ORDER BY deleted_at IS NULL DESC, arrival_date DESC, created_at DESC, id DESC
+    ) AS n
+    FROM visits
+    WHERE status = 'checked-in'
+), duplicates AS (
+    INSERT INTO migration_conflicts (migration, table_name, row_id, problem, original)
+    SELECT '0002', 'visits', v.id, 'profile was checked in more than once; checked out', to_jsonb(v)
+    FROM visits v JOIN ranked r ON r.id = v.id
+    WHERE r.n > 1
+    RETURNING row_id
+)
+UPDATE visits
+SET status = 'checked-out',
+    departure_date = COALESCE(departure_date, GREATEST(arrival_date, now()))
+WHERE id IN (SELECT row_id FROM duplicates);
+
+CREATE UNIQUE INDEX idx_visits_one_checked_in ON visits (profile_id) WHERE status = 'checked-in';
+
+-- one seva per profile and day. Duplicates are removed, keeping the live
+-- schedule created last, and kept in migration_conflicts.
+WITH ranked AS (
+    SELECT id, row_number() OVER (
+        PARTITION BY profile_id, date
+        ORDER BY deleted_at IS NULL DESC, created_at DESC, id DESC
+    ) AS n
+    FROM schedules
+), duplicates AS (
+    INSERT INTO migration_conflicts (migration, table_name, row_id, problem, original)
+    SELECT '0002', 'schedules', s.id, 'profile had more than one schedule on the date; removed', to_jsonb(s)
+    FROM schedules s JOIN ranked r ON r.id = s.id
+    WHERE r.n > 1
+    RETURNING row_id
+)
+DELETE FROM schedules WHERE id IN (SELECT row_id FROM duplicates);
+
+CREATE UNIQUE INDEX idx_schedules_profile_date ON schedules (profile_id, date);
+
+-- the checks hold for new rows right away. Existing rows that break them
+-- cannot be fixed without guessing, so they are recorded and the check is
+-- only validated when there are none.
+ALTER TABLE visits
+    ADD CONSTRAINT chk_visits_status CHECK (status IN ('checked-in', 'pending', 'checked-out')) NOT VALID,
+    ADD CONSTRAINT chk_visits_departure_date CHECK (departure_date IS NULL OR departure_date >= arrival_date) NOT VALID;
+
+ALTER TABLE stay_areas
+    ADD CONSTRAINT chk_stay_areas_capacity CHECK (capacity > 0) NOT VALID;
+
+ALTER TABLE feedbacks
+    ADD CONSTRAINT chk_feedbacks_type CHECK (type IN ('Positive', 'Negative', 'Neutral')) NOT VALID;
+
+INSERT INTO migration_conflicts (migration, table_name, row_id, problem, original)
+SELECT '0002', 'visits', id, 'status is not checked-in, pending or checked-out', to_jsonb(v)
+FROM visits v WHERE status IS NULL OR status NOT IN ('checked-in', 'pending', 'checked-out')
+UNION ALL
+SELECT '0002', 'visits', id, 'departure date is before arrival date', to_jsonb(v)
+FROM visits v WHERE departure_date < arrival_date
+UNION ALL
+SELECT '0002', 'stay_areas', id, 'capacity is not positive', to_jsonb(sa)
+FROM stay_areas sa WHERE capacity IS NULL OR capacity <= 0
+UNION ALL
+SELECT '0002', 'feedbacks', id, 'type is not Positive, Negative or Neutral', to_jsonb(f)
+FROM feedbacks f WHERE type IS NULL OR type NOT IN ('Positive', 'Negative', 'Neutral');
+
+DO $$
+DECLARE
+    checks CONSTANT text[][] := ARRAY[
+        ['visits', 'chk_visits_status', 'status is not checked-in, pending or checked-out'],
+        ['visits', 'chk_visits_departure_date', 'departure date is before arrival date'],
+        ['stay_areas', 'chk_stay_areas_capacity', 'capacity is not positive'],
+        ['feedbacks', 'chk_feedbacks_type', 'type is not Positive, Negative or Neutral']
+    ];
+    i int;
+BEGIN
+    FOR i IN 1 .. array_length(checks, 1) LOOP
+        IF EXISTS (SELECT 1 FROM migration_conflicts WHERE migration = '0002' AND problem = checks[i][3]) THEN
+            RAISE WARNING 'not validating % on %: violating rows are listed in migration_conflicts', checks[i][2], checks[i][1];
+        ELSE
+            EXECUTE format('ALTER TABLE %I VALIDATE CONSTRAINT %I', checks[i][1], checks[i][2]);
+        END IF;
+    END LOOP;
+END
+$$;
@@ -0,0 +1,11 @@
+CREATE TABLE users (
+    id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name       text NOT NULL,
+    email      text NOT NULL,
+    role       varchar(20) NOT NULL,
+    token_hash text NOT NULL,
+    created_at timestamptz,
+    CONSTRAINT uni_users_email UNIQUE (email),
+    CONSTRAINT chk_users_role CHECK (role IN ('admin', 'staff'))
+);
+CREATE UNIQUE INDEX idx_users_token_hash ON users (token_hash);
@@ -0,0 +1,7 @@
+-- when the visit was checked out, for check-outs per day. Visits checked out
+-- before this column existed get their departure date.
+ALTER TABLE visits ADD COLUMN checked_out_at timestamptz;
+
+UPDATE visits
+SET checked_out_at = departure_date
+WHERE status = 'checked-out' AND departure_date IS NOT NULL;
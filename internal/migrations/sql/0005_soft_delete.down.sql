@@ -0,0 +1,19 @@
+-- deleted rows are purged, the unique rules would not hold with them
+DELETE FROM schedules WHERE deleted_at IS NOT NULL;
+DELETE FROM feedbacks WHERE deleted_at IS NOT NULL;
+DELETE FROM visits WHERE deleted_at IS NOT NULL;
+DELETE FROM profiles WHERE deleted_at IS NOT NULL;
+
+DROP INDEX idx_schedules_profile_date;
+CREATE UNIQUE INDEX idx_schedules_profile_date ON schedules (profile_id, date);
+
+DROP INDEX idx_visits_one_checked_in;
+CREATE UNIQUE INDEX idx_visits_one_checked_in ON visits (profile_id) WHERE status = 'checked-in';
+
+DROP INDEX idx_profiles_email;
+ALTER TABLE profiles ADD CONSTRAINT uni_profiles_email UNIQUE (email);
+
+ALTER TABLE feedbacks DROP COLUMN deleted_at;
+ALTER TABLE schedules DROP COLUMN deleted_at;
+ALTER TABLE visits DROP COLUMN deleted_at;
+ALTER TABLE profiles DROP COLUMN deleted_at;
@@ -0,0 +1,22 @@
+-- deleted profiles, visits, schedules and feedback keep their rows with
+-- deleted_at set until they are purged
+ALTER TABLE profiles ADD COLUMN deleted_at timestamptz;
+ALTER TABLE visits ADD COLUMN deleted_at timestamptz;
+ALTER TABLE schedules ADD COLUMN deleted_at timestamptz;
+ALTER TABLE feedbacks ADD COLUMN deleted_at timestamptz;
+
+CREATE INDEX idx_profiles_deleted_at ON profiles (deleted_at);
+CREATE INDEX idx_visits_deleted_at ON visits (deleted_at);
+CREATE INDEX idx_schedules_deleted_at ON schedules (deleted_at);
+CREATE INDEX idx_feedbacks_deleted_at ON feedbacks (deleted_at);
+
+-- the unique rules only hold among rows that are not deleted, so a deleted
+-- profile does not block its email, check-ins or seva days
+ALTER TABLE profiles DROP CONSTRAINT uni_profiles_email;
+CREATE UNIQUE INDEX idx_profiles_email ON profiles (email) WHERE deleted_at IS NULL;
+
+DROP INDEX idx_visits_one_checked_in;
+CREATE UNIQUE INDEX idx_visits_one_checked_in ON visits (profile_id) WHERE status = 'checked-in' AND deleted_at IS NULL;
+
+DROP INDEX idx_schedules_profile_date;
+CREATE UNIQUE INDEX idx_schedules_profile_date ON schedules (profile_id, date) WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS audit_records;
+ALTER TABLE profiles DROP COLUMN erased_at;
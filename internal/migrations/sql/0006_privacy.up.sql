@@ -0,0 +1,16 @@
+-- erased profiles keep their row, anonymized, so visit and seva statistics
+-- still add up
+ALTER TABLE profiles ADD COLUMN erased_at timestamptz;
+
+CREATE TABLE audit_records (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    action      varchar(50) NOT NULL,
+    entity_type varchar(50) NOT NULL,
+    entity_id   uuid NOT NULL,
+    actor_id    uuid REFERENCES users (id),
+    details     jsonb NOT NULL,
+    created_at  timestamptz
+);
+CREATE INDEX idx_audit_records_action ON audit_records (action);
+CREATE INDEX idx_audit_records_entity_id ON audit_records (entity_id);
+CREATE INDEX idx_audit_records_created_at ON audit_records (created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS roll_call_entries;
+DROP TABLE IF EXISTS roll_calls;
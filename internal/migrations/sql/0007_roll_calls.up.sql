@@ -0,0 +1,21 @@
+-- entries point at the visits instead of copying names and phone numbers, so
+-- erasure reaches them and purging a visit removes its entries
+CREATE TABLE roll_calls (
+    id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    note       text,
+    started_by uuid REFERENCES users (id),
+    started_at timestamptz NOT NULL,
+    closed_at  timestamptz
+);
+CREATE INDEX idx_roll_calls_started_at ON roll_calls (started_at);
+
+CREATE TABLE roll_call_entries (
+    id           uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    roll_call_id uuid NOT NULL REFERENCES roll_calls (id) ON DELETE CASCADE,
+    visit_id     uuid NOT NULL REFERENCES visits (id) ON DELETE CASCADE,
+    stay_area_id uuid NOT NULL REFERENCES stay_areas (id),
+    schedule_id  uuid REFERENCES schedules (id) ON DELETE SET NULL,
+    accounted_at timestamptz,
+    accounted_by uuid REFERENCES users (id)
+);
+CREATE UNIQUE INDEX idx_roll_call_entries_visit ON roll_call_entries (roll_call_id, visit_id);
@@ -0,0 +1,2 @@
+ALTER TABLE profiles DROP COLUMN medical;
+ALTER TABLE profiles DROP COLUMN emergency_contacts;
@@ -0,0 +1,5 @@
+-- a list of {"Name", "Relation", "PhoneNumber"}
+ALTER TABLE profiles ADD COLUMN emergency_contacts jsonb NOT NULL DEFAULT '[]';
+-- notes and dietary needs as JSON sealed by the application with
+-- database.encryption_key, the database only ever sees ciphertext
+ALTER TABLE profiles ADD COLUMN medical text;
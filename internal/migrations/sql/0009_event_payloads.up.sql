@@ -0,0 +1,9 @@
+-- events shared through NOTIFY are stored here and only their id is sent,
+-- since NOTIFY payloads must stay under 8000 bytes. Rows are only read by
+-- the listeners right after the notification and are pruned soon after.
+CREATE TABLE event_payloads (
+    id         uuid PRIMARY KEY,
+    payload    jsonb NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_event_payloads_created_at ON event_payloads (created_at);
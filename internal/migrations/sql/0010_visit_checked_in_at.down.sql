@@ -0,0 +1 @@
+ALTER TABLE visits DROP COLUMN checked_in_at;
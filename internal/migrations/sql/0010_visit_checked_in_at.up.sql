@@ -0,0 +1,8 @@
+-- when the visit was first checked in, so planned visits that are still
+-- pending are not counted as check-ins. Visits checked in before this
+-- column existed get the time they were added.
+ALTER TABLE visits ADD COLUMN checked_in_at timestamptz;
+
+UPDATE visits
+SET checked_in_at = created_at
+WHERE status IN ('checked-in', 'checked-out');
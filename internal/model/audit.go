@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditRecord notes a change to personal data or access to it, who did it
+// and what it touched. Records are never updated or deleted by the app.
+type AuditRecord struct {
+	ID         uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Action     AuditAction `gorm:"type:varchar(50);not null;index"`
+	EntityType string      `gorm:"type:varchar(50);not null"`
+	EntityID   uuid.UUID   `gorm:"type:uuid;not null;index"`
+	// the user who asked for it, nil for background jobs
+	ActorID *uuid.UUID `gorm:"type:uuid"`
+	// what was done, e.g. how many rows were anonymized
+	Details   map[string]any `gorm:"type:jsonb;serializer:json;not null"`
+	CreatedAt time.Time      `gorm:"autoCreateTime;index"`
+}
+
+type AuditAction string
+
+const (
+	AuditPersonalDataExported AuditAction = "personal_data.exported"
+	AuditProfileErased        AuditAction = "profile.erased"
+
+	// applied by the retention rules
+	AuditRecordPurged    AuditAction = "record.purged"
+	AuditRemarksCleared  AuditAction = "remarks.cleared"
+	AuditFeedbackCleared AuditAction = "feedback.cleared"
+)
+
+type AuditFilter struct {
+	// zero values match every record
+	Action   AuditAction
+	EntityID *uuid.UUID
+	Limit    int
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"context"
+	"counterapp/internal/crypt"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm/schema"
+)
+
+func init() {
+	schema.RegisterSerializer("encrypted", encryptedSerializer{})
+}
+
+// stores a field as JSON sealed with crypt, nil is stored as null so the
+// column tells which rows hold anything without the key
+type encryptedSerializer struct{}
+
+func (encryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	fieldValue := reflect.New(field.FieldType)
+	if dbValue != nil {
+		var sealed string
+		switch v := dbValue.(type) {
+		case string:
+			sealed = v
+		case []byte:
+			sealed = string(v)
+		default:
+			return fmt.Errorf("%s: unexpected encrypted value of type %T", field.DBName, dbValue)
+		}
+		plaintext, err := crypt.Open(sealed)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.DBName, err)
+		}
+		if err := json.Unmarshal(plaintext, fieldValue.Interface()); err != nil {
+			return fmt.Errorf("%s: %w", field.DBName, err)
+		}
+	}
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+func (encryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue any) (any, error) {
+	if v := reflect.ValueOf(fieldValue); !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return nil, nil
+	}
+	plaintext, err := json.Marshal(fieldValue)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := crypt.Seal(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", field.DBName, err)
+	}
+	return sealed, nil
+}
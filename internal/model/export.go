@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// ScheduleExportRow is a schedule with its profile, seva type and visit
+// flattened into one row
+type ScheduleExportRow struct {
+	ID            string
+	Date          time.Time
+	ProfileName   string
+	Email         string
+	PhoneNumber   string
+	Gender        string
+	Category      string
+	SevaType      string
+	Location      *string
+	Notes         *string
+	StayArea      *string
+	LockerNumber  *string
+	LockerSection *string
+	ArrivalDate   time.Time
+	DepartureDate *time.Time
+}
+
+// ResidentExportRow is a checked-in visit with its profile, stay area and
+// locker flattened into one row
+type ResidentExportRow struct {
+	VisitID       string
+	ProfileID     string
+	Name          string
+	Email         string
+	PhoneNumber   string
+	Gender        string
+	Category      string
+	StayAreaID    string
+	StayArea      string
+	LockerNumber  *string
+	LockerSection *string
+	ArrivalDate   time.Time
+	DepartureDate *time.Time
+	Remarks       *string
+}
@@ -4,18 +4,20 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Feedback struct {
-	ID        uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	ProfileID uuid.UUID    `gorm:"type:uuid;not null;index"`
-	Profile   Profile      `gorm:"foreignKey:ProfileID;references:ID"`
-	VisitID   *uuid.UUID   `gorm:"type:uuid;index"`
-	Visit     *Visit       `gorm:"foreignKey:VisitID;references:ID"`
-	Content   string       `gorm:"type:text;not null"`
-	Type      FeedbackType `gorm:"type:varchar(20);not null"`
-	CreatedBy *string      `gorm:"type:text"`
-	CreatedAt time.Time    `gorm:"autoCreateTime"`
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ProfileID uuid.UUID      `gorm:"type:uuid;not null;index"`
+	Profile   Profile        `gorm:"foreignKey:ProfileID;references:ID"`
+	VisitID   *uuid.UUID     `gorm:"type:uuid;index"`
+	Visit     *Visit         `gorm:"foreignKey:VisitID;references:ID"`
+	Content   string         `gorm:"type:text;not null"`
+	Type      FeedbackType   `gorm:"type:varchar(20);not null"`
+	CreatedBy *string        `gorm:"type:text"`
+	CreatedAt time.Time      `gorm:"autoCreateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
 type FeedbackType string
@@ -25,3 +27,19 @@ const (
 	TypeNegative FeedbackType = "Negative"
 	TypeNeutral  FeedbackType = "Neutral"
 )
+
+func (t FeedbackType) IsValid() bool {
+	switch t {
+	case TypePositive, TypeNegative, TypeNeutral:
+		return true
+	}
+	return false
+}
+
+type AddFeedbackRequest struct {
+	ProfileID uuid.UUID
+	VisitID   *uuid.UUID
+	Content   string
+	Type      FeedbackType
+	CreatedBy *string
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxMessage struct {
+	ID            uuid.UUID            `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ProfileID     *uuid.UUID           `gorm:"type:uuid;index"`
+	Channel       NotificationChannel  `gorm:"type:varchar(20);not null;default:'email'"`
+	Template      NotificationTemplate `gorm:"type:varchar(50);not null"`
+	Recipient     string               `gorm:"not null"`
+	Subject       string               `gorm:"not null;default:''"`
+	Body          string               `gorm:"type:text;not null"`
+	DedupKey      *string              `gorm:"uniqueIndex"`
+	Status        DeliveryStatus       `gorm:"type:varchar(20);not null;default:'pending';index"`
+	Attempts      int                  `gorm:"not null;default:0"`
+	LastError     *string              `gorm:"type:text"`
+	NextAttemptAt time.Time            `gorm:"not null;index"`
+	SentAt        *time.Time
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+type NotificationTemplate string
+
+const (
+	TemplateVisitConfirmation  NotificationTemplate = "visit_confirmation"
+	TemplateScheduleAssignment NotificationTemplate = "schedule_assignment"
+	TemplateDepartureReminder  NotificationTemplate = "departure_reminder"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending DeliveryStatus = "pending"
+	// claimed by a sender until next_attempt_at, pending again after that
+	DeliverySending DeliveryStatus = "sending"
+	DeliverySent    DeliveryStatus = "sent"
+	DeliveryFailed  DeliveryStatus = "failed"
+)
+
+type NotificationChannel string
+
+const (
+	ChannelEmail    NotificationChannel = "email"
+	ChannelSMS      NotificationChannel = "sms"
+	ChannelWhatsApp NotificationChannel = "whatsapp"
+)
+
+func (c NotificationChannel) IsValid() bool {
+	switch c {
+	case ChannelEmail, ChannelSMS, ChannelWhatsApp:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// PersonalData is everything stored about one profile
+type PersonalData struct {
+	ExportedAt time.Time `json:"exported_at"`
+	Profile    Profile   `json:"profile"`
+	// the medical info of the profile, which it leaves out
+	Medical       *MedicalInfo    `json:"medical"`
+	Visits        []Visit         `json:"visits"`
+	Schedules     []Schedule      `json:"schedules"`
+	Feedbacks     []Feedback      `json:"feedbacks"`
+	Notifications []OutboxMessage `json:"notifications"`
+}
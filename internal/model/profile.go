@@ -4,12 +4,13 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Profile struct {
 	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	Name        string    `gorm:"not null"`
-	Email       string    `gorm:"unique;not null"`
+	Email       string    `gorm:"not null;uniqueIndex:idx_profiles_email,where:deleted_at IS NULL"`
 	PhoneNumber string    `gorm:"column:phone_number"`
 	Gender      Gender    `gorm:"type:varchar(10);not null"`
 	Category    Category  `gorm:"type:varchar(300)"`
@@ -17,6 +18,48 @@ type Profile struct {
 	Remarks     *string   `gorm:"type:text"`
 	CreatedAt   time.Time `gorm:"autoCreateTime"`
 	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+	// set when the profile is deleted, the row is kept until it is purged
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	// set when the personal data was erased, the profile is then anonymous
+	ErasedAt *time.Time `gorm:"default:null"`
+
+	NotificationChannel NotificationChannel `gorm:"type:varchar(20);not null;default:'email'"`
+	NotificationsOptOut bool                `gorm:"default:false"`
+
+	EmergencyContacts []EmergencyContact `gorm:"type:jsonb;serializer:json;not null;default:'[]'"`
+	// encrypted at rest and left out of every response, it is only shown to
+	// the MedicalRoles where they ask for it
+	Medical *MedicalInfo `gorm:"type:text;serializer:encrypted" json:"-"`
+}
+
+// EmergencyContact is someone to call when the volunteer falls ill
+type EmergencyContact struct {
+	Name string
+	// e.g. "Mother"
+	Relation    string
+	PhoneNumber string
+}
+
+// MedicalInfo is what the desk needs to know to look after a volunteer
+type MedicalInfo struct {
+	Notes        *string `json:",omitempty"`
+	DietaryNeeds *string `json:",omitempty"`
+}
+
+// reports whether nothing is noted, an empty MedicalInfo is stored as null
+func (m *MedicalInfo) IsEmpty() bool {
+	return m == nil || (m.Notes == nil && m.DietaryNeeds == nil)
+}
+
+// what erasure leaves of a profile's personal data. The email stays unique
+// per profile so the unique index holds.
+const (
+	ErasedName     = "Erased volunteer"
+	ErasedFeedback = "[erased]"
+)
+
+func ErasedEmail(profileID uuid.UUID) string {
+	return "erased-" + profileID.String() + "@erased.invalid"
 }
 
 type Gender string
@@ -27,6 +70,14 @@ const (
 	GenderOther  Gender = "Other"
 )
 
+func (g Gender) IsValid() bool {
+	switch g {
+	case GenderMale, GenderFemale, GenderOther:
+		return true
+	}
+	return false
+}
+
 type Category string
 
 const (
@@ -34,3 +85,44 @@ const (
 	CategoryLTV      Category = "Long Term Volunteer"
 	CategoryOverseas Category = "Overseas Volunteer"
 )
+
+func (c Category) IsValid() bool {
+	switch c {
+	case CategorySTV, CategoryLTV, CategoryOverseas:
+		return true
+	}
+	return false
+}
+
+// ProfileOverview is a profile as listed, with its active visit if any. The
+// departure date is formatted as YYYY-MM-DD.
+type ProfileOverview struct {
+	ID            string
+	Name          string
+	PhoneNumber   string
+	Email         string
+	Gender        string
+	Category      string
+	IsBlocked     bool
+	Remarks       string
+	ActiveVisitID *string
+	DepartureDate *string
+	StayArea      *string
+	Status        *string
+}
+
+// ProfileUpdate holds the fields to change, nil fields are left as they are
+type ProfileUpdate struct {
+	Name        *string
+	PhoneNumber *string
+	Gender      *Gender
+	Category    *Category
+	IsBlocked   *bool
+	Remarks     *string
+
+	NotificationChannel *NotificationChannel
+	NotificationsOptOut *bool
+
+	// replaces the whole list, written apart since it is a JSON column
+	EmergencyContacts *[]EmergencyContact `gorm:"-"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDs listed per rule in a retention report, the count covers all of them
+const RetentionReportIDs = 100
+
+// RetentionPolicy is how long personal data is kept, a zero period turns
+// its rules off
+type RetentionPolicy struct {
+	// profiles without a visit in this many years are anonymized
+	InactiveProfileYears int
+	// remarks on profiles, visits and schedules are cleared this many months
+	// after the last visit
+	RemarksMonths int
+	// feedback text is replaced this many months after it was written
+	FeedbackMonths int
+	// deleted profiles and visits are purged this many days after deletion
+	PurgeDeletedDays int
+}
+
+type RetentionReport struct {
+	DryRun bool                  `json:"dry_run"`
+	RanAt  time.Time             `json:"ran_at"`
+	Rules  []RetentionRuleReport `json:"rules"`
+}
+
+type RetentionRuleReport struct {
+	Rule   string      `json:"rule"`
+	Entity string      `json:"entity"`
+	Action AuditAction `json:"action"`
+	// rows older than this are due
+	Cutoff time.Time   `json:"cutoff"`
+	Count  int         `json:"count"`
+	IDs    []uuid.UUID `json:"ids"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RollCall is a headcount of everyone on site, e.g. during a fire drill. The
+// checked-in visits become its entries when it starts, so people checking in
+// or out afterwards do not change what is being counted.
+type RollCall struct {
+	ID   uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Note *string   `gorm:"type:text"`
+	// the user who started it, nil when anonymous
+	StartedBy *uuid.UUID `gorm:"type:uuid"`
+	StartedAt time.Time  `gorm:"not null;index"`
+	// set when the roll call is closed, its entries cannot be marked after
+	ClosedAt *time.Time
+	Entries  []RollCallEntry `gorm:"foreignKey:RollCallID" json:",omitempty"`
+}
+
+// RollCallEntry is one person to account for. The stay area and seva are
+// the ones the person had when the roll call started.
+type RollCallEntry struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	RollCallID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_roll_call_entries_visit"`
+	VisitID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_roll_call_entries_visit"`
+	Visit      Visit      `gorm:"foreignKey:VisitID;references:ID"`
+	StayAreaID uuid.UUID  `gorm:"type:uuid;not null"`
+	StayArea   StayArea   `gorm:"foreignKey:StayAreaID;references:ID"`
+	ScheduleID *uuid.UUID `gorm:"type:uuid"`
+	Schedule   *Schedule  `gorm:"foreignKey:ScheduleID;references:ID"`
+	// set when the person is accounted for, cleared when marked by mistake
+	AccountedAt *time.Time
+	AccountedBy *uuid.UUID `gorm:"type:uuid"`
+}
+
+// RollCallSummary is a roll call without its entries, with how many people
+// it counts and how many of them were accounted for
+type RollCallSummary struct {
+	RollCall
+	Total     int
+	Accounted int
+}
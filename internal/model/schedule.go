@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type SevaType struct {
@@ -15,15 +16,29 @@ type SevaType struct {
 }
 
 type Schedule struct {
-	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	ProfileID  uuid.UUID `gorm:"type:uuid;not null;index"`
-	Profile    Profile   `gorm:"foreignKey:ProfileID;references:ID"`
-	VisitID    uuid.UUID `gorm:"type:uuid;not null;index"`
-	Visit      Visit     `gorm:"foreignKey:VisitID;references:ID"`
-	Date       time.Time `gorm:"not null"`
-	SevaTypeID uuid.UUID `gorm:"type:uuid;not null;index"`
-	SevaType   SevaType  `gorm:"foreignKey:SevaTypeID"`
-	Location   *string   `gorm:"type:varchar(200)"`
-	Notes      *string   `gorm:"type:text"`
-	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	ID         uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ProfileID  uuid.UUID      `gorm:"type:uuid;not null;index"`
+	Profile    Profile        `gorm:"foreignKey:ProfileID;references:ID"`
+	VisitID    uuid.UUID      `gorm:"type:uuid;not null;index"`
+	Visit      Visit          `gorm:"foreignKey:VisitID;references:ID"`
+	Date       time.Time      `gorm:"not null"`
+	SevaTypeID uuid.UUID      `gorm:"type:uuid;not null;index"`
+	SevaType   SevaType       `gorm:"foreignKey:SevaTypeID"`
+	Location   *string        `gorm:"type:varchar(200)"`
+	Notes      *string        `gorm:"type:text"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime"`
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+}
+
+type AddScheduleRequest struct {
+	ProfileID  uuid.UUID
+	VisitID    uuid.UUID
+	SevaTypeID uuid.UUID
+	Location   *string
+	Date       time.Time
+}
+
+type AddSevaTypeRequest struct {
+	Name        string
+	Description *string
 }
@@ -7,3 +7,16 @@ type StayArea struct {
 	Name     string    `gorm:"not null"`
 	Capacity int       `gorm:"not null"`
 }
+
+type AddStayAreaRequest struct {
+	Name     string
+	Capacity int
+}
+
+// StayAreaOccupancy is a stay area with how many checked-in visits it has
+type StayAreaOccupancy struct {
+	StayAreaID           string
+	StayName             string
+	StayCapacity         int
+	CurrentOccupiedCount int
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// User is a member of staff calling the API with a bearer token. Only the
+// SHA-256 of the token is stored.
+type User struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name      string    `gorm:"not null"`
+	Email     string    `gorm:"unique;not null"`
+	Role      Role      `gorm:"type:varchar(20);not null"`
+	TokenHash string    `gorm:"not null;uniqueIndex" json:"-"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+type Role string
+
+const (
+	RoleAdmin Role = "admin"
+	RoleStaff Role = "staff"
+)
+
+// the roles that may read and change medical info
+var MedicalRoles = []Role{RoleAdmin, RoleStaff}
+
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleAdmin, RoleStaff:
+		return true
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Visit struct {
@@ -19,6 +20,11 @@ type Visit struct {
 	Locker        *Locker       `gorm:"foreignKey:LockerID;references:ID"`
 	Remarks       *string       `gorm:"type:text"`
 	CreatedAt     time.Time     `gorm:"autoCreateTime"`
+	// set when the visit is first checked in and kept after that
+	CheckedInAt *time.Time `gorm:"default:null"`
+	// set when the status changes to checked-out, cleared when it changes back
+	CheckedOutAt *time.Time     `gorm:"default:null"`
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
 }
 
 type ProfileStatus string
@@ -28,3 +34,29 @@ const (
 	StatusPending    ProfileStatus = "pending"
 	StatusCheckedOut ProfileStatus = "checked-out"
 )
+
+func (s ProfileStatus) IsValid() bool {
+	switch s {
+	case StatusCheckedIn, StatusPending, StatusCheckedOut:
+		return true
+	}
+	return false
+}
+
+type UpdateVisitRequest struct {
+	DepartureDate *time.Time
+	StayAreaID    *uuid.UUID
+	Status        *ProfileStatus
+	LockerID      *uuid.UUID
+	Remarks       *string
+}
+
+type AddVisitRequest struct {
+	ProfileID     uuid.UUID
+	ArrivalDate   time.Time
+	DepartureDate *time.Time
+	StayAreaID    uuid.UUID
+	Status        ProfileStatus
+	LockerID      *uuid.UUID
+	Remarks       *string
+}
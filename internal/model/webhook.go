@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookSubscription struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	URL        string    `gorm:"not null"`
+	Secret     string    `gorm:"not null" json:"-"`
+	EventTypes []string  `gorm:"type:jsonb;serializer:json;not null"`
+	IsActive   bool      `gorm:"default:true"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID           `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	SubscriptionID uuid.UUID           `gorm:"type:uuid;not null;index"`
+	Subscription   WebhookSubscription `gorm:"foreignKey:SubscriptionID;references:ID" json:"-"`
+	EventID        uuid.UUID           `gorm:"type:uuid;not null;index"`
+	EventType      string              `gorm:"type:varchar(50);not null"`
+	Payload        string              `gorm:"type:jsonb;not null"`
+	Status         DeliveryStatus      `gorm:"type:varchar(20);not null;default:'pending';index"`
+	Attempts       int                 `gorm:"not null;default:0"`
+	LastStatusCode *int
+	LastError      *string   `gorm:"type:text"`
+	NextAttemptAt  time.Time `gorm:"not null;index"`
+	DeliveredAt    *time.Time
+	RedeliveryOf   *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+}
+
+type AddWebhookSubscriptionRequest struct {
+	URL        string
+	Secret     string
+	EventTypes []string
+}
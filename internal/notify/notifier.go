@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"counterapp/internal/config"
+	"counterapp/internal/model"
+)
+
+// Message is a rendered notification ready to go out on one channel.
+// Subject is only used by channels that have one.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages on a single channel
+type Notifier interface {
+	Channel() model.NotificationChannel
+	Send(ctx context.Context, msg Message) error
+}
+
+// builds a notifier for every channel that is configured, keyed by channel
+func NewNotifiers(cfg config.Notify) (map[model.NotificationChannel]Notifier, error) {
+	notifiers := make(map[model.NotificationChannel]Notifier)
+
+	if cfg.SMTP.Enabled() {
+		email, err := NewEmailNotifier(cfg.SMTP)
+		if err != nil {
+			return nil, err
+		}
+		notifiers[email.Channel()] = email
+	}
+	if cfg.SMSWebhookURL != "" {
+		notifiers[model.ChannelSMS] = NewWebhookNotifier(model.ChannelSMS, cfg.SMSWebhookURL, cfg.WebhookToken, cfg.DefaultCountryCode)
+	}
+	if cfg.WhatsAppWebhookURL != "" {
+		notifiers[model.ChannelWhatsApp] = NewWebhookNotifier(model.ChannelWhatsApp, cfg.WhatsAppWebhookURL, cfg.WebhookToken, cfg.DefaultCountryCode)
+	}
+	return notifiers, nil
+}
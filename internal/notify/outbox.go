@@ -0,0 +1,199 @@
+package notify
+
+import (
+	"counterapp/internal/dao"
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+	"counterapp/internal/util"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VisitConfirmation struct {
+	Name          string
+	StayArea      string
+	Locker        string
+	ArrivalDate   time.Time
+	DepartureDate *time.Time
+}
+
+type ScheduleAssignment struct {
+	Name     string
+	SevaType string
+	Location string
+	Notes    string
+	Date     time.Time
+}
+
+type DepartureReminder struct {
+	Name          string
+	StayArea      string
+	DepartureDate time.Time
+}
+
+var (
+	channelsMu sync.RWMutex
+	// channels that have a notifier, nil when not known
+	channels map[model.NotificationChannel]bool
+	// given to phone numbers without a country code, without the +
+	defaultCountryCode string
+)
+
+// records the channels messages can be sent on, a profile preferring any
+// other channel is sent email instead. Until it is called every channel is
+// taken to be available.
+func SetChannels(notifiers map[model.NotificationChannel]Notifier) {
+	var available map[model.NotificationChannel]bool
+	if notifiers != nil {
+		available = make(map[model.NotificationChannel]bool, len(notifiers))
+		for channel := range notifiers {
+			available[channel] = true
+		}
+	}
+
+	channelsMu.Lock()
+	defer channelsMu.Unlock()
+	channels = available
+}
+
+// sets the country code given to phone numbers written without one
+func SetDefaultCountryCode(code string) {
+	channelsMu.Lock()
+	defer channelsMu.Unlock()
+	defaultCountryCode = code
+}
+
+// the phone number in E.164 form, numbers without a country code get the
+// default one
+func NormalizePhone(phone string) (string, error) {
+	channelsMu.RLock()
+	code := defaultCountryCode
+	channelsMu.RUnlock()
+	return util.NormalizePhoneE164(phone, code)
+}
+
+func channelAvailable(channel model.NotificationChannel) bool {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+	return channels == nil || channels[channel]
+}
+
+// picks the channel the profile prefers, falling back to email when no valid
+// phone number is on file or the channel has no notifier. A number that
+// cannot be normalized would fail every attempt to send. Returns false when the
+// profile opted out or cannot be reached at all.
+func recipientFor(profile *model.Profile) (model.NotificationChannel, string, bool) {
+	if profile.NotificationsOptOut {
+		return "", "", false
+	}
+
+	switch profile.NotificationChannel {
+	case model.ChannelSMS, model.ChannelWhatsApp:
+		if phone, err := NormalizePhone(profile.PhoneNumber); err == nil && channelAvailable(profile.NotificationChannel) {
+			return profile.NotificationChannel, phone, true
+		}
+	}
+	if profile.Email != "" {
+		return model.ChannelEmail, profile.Email, true
+	}
+	return "", "", false
+}
+
+// renders the template and stores the message in the outbox. Sending happens
+// later in the Sender so callers on the request path only pay for an insert.
+// Messages with a dedupKey that is already queued are dropped.
+func enqueue(outbox repository.NotificationRepository, profile *model.Profile, name model.NotificationTemplate, dedupKey string, data any) error {
+	channel, recipient, ok := recipientFor(profile)
+	if !ok {
+		return nil
+	}
+
+	subject, body, err := render(name, channel, data)
+	if err != nil {
+		return err
+	}
+
+	msg := &model.OutboxMessage{
+		ProfileID: &profile.ID,
+		Channel:   channel,
+		Template:  name,
+		Recipient: recipient,
+		Subject:   subject,
+		Body:      body,
+	}
+	if dedupKey != "" {
+		msg.DedupKey = &dedupKey
+	}
+	return outbox.Enqueue(msg)
+}
+
+// queues the confirmation mail for a visit with StayArea and Locker preloaded
+func EnqueueVisitConfirmation(outbox repository.NotificationRepository, profile *model.Profile, visit *model.Visit) error {
+	data := VisitConfirmation{
+		Name:          profile.Name,
+		StayArea:      visit.StayArea.Name,
+		ArrivalDate:   visit.ArrivalDate,
+		DepartureDate: visit.DepartureDate,
+	}
+	if visit.Locker != nil {
+		data.Locker = fmt.Sprintf("%s %s", visit.Locker.Section, visit.Locker.LockerNumber)
+	}
+	return enqueue(outbox, profile, model.TemplateVisitConfirmation, dedupKey(model.TemplateVisitConfirmation, visit.ID), data)
+}
+
+func EnqueueScheduleAssignment(outbox repository.NotificationRepository, profile *model.Profile, schedule *model.Schedule, sevaType *model.SevaType) error {
+	data := ScheduleAssignment{
+		Name:     profile.Name,
+		SevaType: sevaType.Name,
+		Date:     schedule.Date,
+	}
+	if schedule.Location != nil {
+		data.Location = *schedule.Location
+	}
+	if schedule.Notes != nil {
+		data.Notes = *schedule.Notes
+	}
+	return enqueue(outbox, profile, model.TemplateScheduleAssignment, dedupKey(model.TemplateScheduleAssignment, schedule.ID), data)
+}
+
+// queues a reminder for every checked-in visit departing on date. Safe to run
+// repeatedly, each visit gets at most one reminder.
+func EnqueueDepartureReminders(db *gorm.DB, date time.Time) (int, error) {
+	visits, err := dao.GetCheckedInVisitsDepartingOn(db, date)
+	if err != nil {
+		return 0, err
+	}
+	outbox := repository.NewGormRepositories(db).Notifications
+
+	for _, v := range visits {
+		profileID, err := uuid.Parse(v.ProfileID)
+		if err != nil {
+			return 0, err
+		}
+		visitID, err := uuid.Parse(v.VisitID)
+		if err != nil {
+			return 0, err
+		}
+		profile := &model.Profile{
+			ID:                  profileID,
+			Name:                v.Name,
+			Email:               v.Email,
+			PhoneNumber:         v.PhoneNumber,
+			NotificationChannel: v.NotificationChannel,
+			NotificationsOptOut: v.NotificationsOptOut,
+		}
+		data := DepartureReminder{Name: v.Name, StayArea: v.StayArea, DepartureDate: v.DepartureDate}
+		if err := enqueue(outbox, profile, model.TemplateDepartureReminder, dedupKey(model.TemplateDepartureReminder, visitID), data); err != nil {
+			return 0, err
+		}
+	}
+	return len(visits), nil
+}
+
+func dedupKey(name model.NotificationTemplate, id uuid.UUID) string {
+	return fmt.Sprintf("%s:%s", name, id)
+}
@@ -0,0 +1,67 @@
+package notify_test
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/notify"
+	"counterapp/internal/repository/memory"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRecipientFallsBackToEmail(t *testing.T) {
+	t.Cleanup(func() {
+		notify.SetChannels(nil)
+		notify.SetDefaultCountryCode("")
+	})
+	notify.SetDefaultCountryCode("91")
+
+	cases := []struct {
+		name        string
+		preferred   model.NotificationChannel
+		phone       string
+		configured  []model.NotificationChannel
+		wantChannel model.NotificationChannel
+		wantTo      string
+	}{
+		{"sms configured", model.ChannelSMS, "+919845012345", []model.NotificationChannel{model.ChannelEmail, model.ChannelSMS}, model.ChannelSMS, "+919845012345"},
+		{"sms not configured", model.ChannelSMS, "+919845012345", []model.NotificationChannel{model.ChannelEmail}, model.ChannelEmail, "asha@example.com"},
+		{"whatsapp not configured", model.ChannelWhatsApp, "+919845012345", []model.NotificationChannel{model.ChannelEmail, model.ChannelSMS}, model.ChannelEmail, "asha@example.com"},
+		{"no phone number", model.ChannelWhatsApp, "", []model.NotificationChannel{model.ChannelEmail, model.ChannelWhatsApp}, model.ChannelEmail, "asha@example.com"},
+		{"phone number normalized", model.ChannelSMS, "098450-12345", []model.NotificationChannel{model.ChannelEmail, model.ChannelSMS}, model.ChannelSMS, "+919845012345"},
+		{"invalid phone number", model.ChannelWhatsApp, "call the office", []model.NotificationChannel{model.ChannelEmail, model.ChannelWhatsApp}, model.ChannelEmail, "asha@example.com"},
+		{"phone number too short", model.ChannelSMS, "+91 123", []model.NotificationChannel{model.ChannelEmail, model.ChannelSMS}, model.ChannelEmail, "asha@example.com"},
+		{"channels not known", model.ChannelSMS, "+919845012345", nil, model.ChannelSMS, "+919845012345"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var notifiers map[model.NotificationChannel]notify.Notifier
+			if tc.configured != nil {
+				notifiers = make(map[model.NotificationChannel]notify.Notifier)
+				for _, channel := range tc.configured {
+					notifiers[channel] = nil
+				}
+			}
+			notify.SetChannels(notifiers)
+
+			repos := memory.NewRepositories()
+			profile := &model.Profile{
+				ID: uuid.New(), Name: "Asha", Email: "asha@example.com",
+				PhoneNumber: tc.phone, NotificationChannel: tc.preferred,
+			}
+			visit := &model.Visit{ID: uuid.New(), ArrivalDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), StayArea: model.StayArea{Name: "North Dorm"}}
+			if err := notify.EnqueueVisitConfirmation(repos.Notifications, profile, visit); err != nil {
+				t.Fatal(err)
+			}
+
+			messages, err := repos.Notifications.List("", 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(messages) != 1 || messages[0].Channel != tc.wantChannel || messages[0].Recipient != tc.wantTo {
+				t.Fatalf("queued %+v, want one %s message to %s", messages, tc.wantChannel, tc.wantTo)
+			}
+		})
+	}
+}
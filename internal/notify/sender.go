@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"context"
+	"counterapp/internal/dao"
+	"counterapp/internal/model"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SenderOptions struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	// first retry delay, doubled after every failed attempt
+	RetryBackoff time.Duration
+	SendTimeout  time.Duration
+	// how long a claimed batch is kept from other senders, after that a
+	// sender is assumed to have stopped and the messages are claimed again
+	Lease  time.Duration
+	Logger *slog.Logger
+}
+
+func (o SenderOptions) withDefaults() SenderOptions {
+	if o.PollInterval <= 0 {
+		o.PollInterval = 10 * time.Second
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 20
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 6
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = time.Minute
+	}
+	if o.SendTimeout <= 0 {
+		o.SendTimeout = 30 * time.Second
+	}
+	if o.Lease <= 0 {
+		o.Lease = time.Duration(o.BatchSize)*o.SendTimeout + time.Minute
+	}
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	return o
+}
+
+// Sender drains the outbox in the background, handing each message to the
+// notifier of its channel
+type Sender struct {
+	db        *gorm.DB
+	notifiers map[model.NotificationChannel]Notifier
+	opts      SenderOptions
+
+	lastReminderDay string
+}
+
+func NewSender(db *gorm.DB, notifiers map[model.NotificationChannel]Notifier, opts SenderOptions) *Sender {
+	return &Sender{db: db, notifiers: notifiers, opts: opts.withDefaults()}
+}
+
+// Run polls the outbox until ctx is cancelled. Departure reminders for the
+// next day are queued once per day from the same loop.
+func (s *Sender) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.queueDepartureReminders(time.Now())
+		if err := s.SendDue(ctx); err != nil {
+			s.opts.Logger.Error("error while sending notifications", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue sends every message that is due, one batch at a time
+func (s *Sender) SendDue(ctx context.Context) error {
+	for {
+		sent, err := s.sendBatch(ctx)
+		if err != nil || sent < s.opts.BatchSize || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// claims a batch and sends it outside of any transaction, so a slow notifier
+// holds no locks or connections. Each result is recorded on its own.
+func (s *Sender) sendBatch(ctx context.Context) (int, error) {
+	now := time.Now()
+	messages, err := dao.ClaimDueOutboxMessages(s.db, now, now.Add(s.opts.Lease), s.opts.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, msg := range messages {
+		sendErr := s.send(ctx, msg)
+
+		if sendErr == nil {
+			err = dao.MarkOutboxMessageSent(s.db, msg.ID.String(), time.Now())
+		} else {
+			attempt := msg.Attempts + 1
+			failed := attempt >= s.opts.MaxAttempts
+			next := time.Now().Add(s.backoff(attempt))
+			err = dao.MarkOutboxMessageAttemptFailed(s.db, msg.ID.String(), sendErr.Error(), next, failed)
+		}
+		if err != nil {
+			return len(messages), err
+		}
+	}
+	return len(messages), nil
+}
+
+func (s *Sender) send(ctx context.Context, msg model.OutboxMessage) error {
+	notifier, ok := s.notifiers[msg.Channel]
+	if !ok {
+		return fmt.Errorf("no notifier configured for channel %s", msg.Channel)
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, s.opts.SendTimeout)
+	defer cancel()
+	return notifier.Send(sendCtx, Message{To: msg.Recipient, Subject: msg.Subject, Body: msg.Body})
+}
+
+func (s *Sender) backoff(attempt int) time.Duration {
+	return s.opts.RetryBackoff * time.Duration(1<<(attempt-1))
+}
+
+func (s *Sender) queueDepartureReminders(now time.Time) {
+	day := now.Format("2006-01-02")
+	if s.lastReminderDay == day {
+		return
+	}
+
+	tomorrow := now.AddDate(0, 0, 1)
+	if _, err := EnqueueDepartureReminders(s.db, tomorrow); err != nil {
+		s.opts.Logger.Error("unable to queue departure reminders", "error", err)
+		return
+	}
+	s.lastReminderDay = day
+}
@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"context"
+	"counterapp/internal/config"
+	"counterapp/internal/model"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EmailNotifier sends plain text email over SMTP
+type EmailNotifier struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     *mail.Address
+	// verifies the server certificate against host after STARTTLS
+	tlsConfig *tls.Config
+}
+
+func NewEmailNotifier(cfg config.SMTP) (*EmailNotifier, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+	return &EmailNotifier{
+		addr:      net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		host:      cfg.Host,
+		username:  cfg.Username,
+		password:  cfg.Password,
+		from:      from,
+		tlsConfig: &tls.Config{ServerName: cfg.Host},
+	}, nil
+}
+
+func (m *EmailNotifier) Channel() model.NotificationChannel {
+	return model.ChannelEmail
+}
+
+func (m *EmailNotifier) Send(ctx context.Context, msg Message) error {
+	recipient, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	// net/smtp has no context support, so the deadline is applied to the
+	// connection instead
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(m.tlsConfig); err != nil {
+			return err
+		}
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(m.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(recipient.Address); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(buildMessage(m.from, recipient, msg.Subject, msg.Body)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func buildMessage(from *mail.Address, to *mail.Address, subject string, body string) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from.String() + "\r\n")
+	b.WriteString("To: " + to.String() + "\r\n")
+	b.WriteString("Subject: " + mimeEncode(subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+func mimeEncode(s string) string {
+	return mime.QEncoding.Encode("utf-8", s)
+}
@@ -0,0 +1,210 @@
+package notify
+
+import (
+	"context"
+	"counterapp/internal/config"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// what the stub server saw of one session
+type smtpSession struct {
+	tls    bool
+	authed bool
+	from   string
+	to     string
+	data   string
+	err    error
+}
+
+// serves a single SMTP session that offers STARTTLS and only accepts the
+// login and the message once the connection is encrypted
+func serveSTARTTLS(t *testing.T, cert tls.Certificate) (net.Listener, <-chan smtpSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	done := make(chan smtpSession, 1)
+	go func() {
+		var s smtpSession
+		defer func() { done <- s }()
+
+		conn, err := ln.Accept()
+		if err != nil {
+			s.err = err
+			return
+		}
+		defer func() { conn.Close() }()
+		text := textproto.NewConn(conn)
+		reply := func(lines ...string) {
+			for i, line := range lines {
+				sep := "-"
+				if i == len(lines)-1 {
+					sep = " "
+				}
+				text.PrintfLine("%s%s", line[:3]+sep, line[4:])
+			}
+		}
+
+		reply("220 stub ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				s.err = err
+				return
+			}
+			verb, _, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO":
+				if s.tls {
+					reply("250 stub", "250 AUTH PLAIN")
+				} else {
+					reply("250 stub", "250 STARTTLS")
+				}
+			case "STARTTLS":
+				reply("220 ready")
+				tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}})
+				if err := tlsConn.Handshake(); err != nil {
+					s.err = err
+					return
+				}
+				conn = tlsConn
+				text = textproto.NewConn(conn)
+				s.tls = true
+			case "AUTH":
+				if !s.tls {
+					reply("530 must issue STARTTLS first")
+					continue
+				}
+				s.authed = true
+				reply("235 ok")
+			case "MAIL":
+				s.from = line
+				reply("250 ok")
+			case "RCPT":
+				s.to = line
+				reply("250 ok")
+			case "DATA":
+				reply("354 go ahead")
+				lines, err := text.ReadDotLines()
+				if err != nil {
+					s.err = err
+					return
+				}
+				s.data = strings.Join(lines, "\n")
+				reply("250 ok")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 unknown command")
+			}
+		}
+	}()
+	return ln, done
+}
+
+// a self-signed certificate for 127.0.0.1
+func selfSigned(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "stub smtp"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, roots
+}
+
+func TestEmailNotifierSTARTTLS(t *testing.T) {
+	cert, roots := selfSigned(t)
+	ln, done := serveSTARTTLS(t, cert)
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	m, err := NewEmailNotifier(config.SMTP{
+		Host:     "127.0.0.1",
+		Port:     port,
+		Username: "ashram",
+		Password: "secret",
+		From:     "Ashram Connect <no-reply@example.com>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the certificate is still checked against the host, only the stub's
+	// own root is trusted on top
+	m.tlsConfig.RootCAs = roots
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = m.Send(ctx, Message{To: "asha@example.com", Subject: "Welcome", Body: "See you soon"})
+	if err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+
+	s := <-done
+	if s.err != nil {
+		t.Fatalf("stub server: %v", s.err)
+	}
+	if !s.tls || !s.authed {
+		t.Errorf("session used TLS %v and logged in %v, want both", s.tls, s.authed)
+	}
+	if s.from != "MAIL FROM:<no-reply@example.com>" || s.to != "RCPT TO:<asha@example.com>" {
+		t.Errorf("envelope is %q, %q", s.from, s.to)
+	}
+	if !strings.Contains(s.data, "Subject: Welcome") || !strings.Contains(s.data, "See you soon") {
+		t.Errorf("message is %q", s.data)
+	}
+}
+
+func TestEmailNotifierVerifiesServerName(t *testing.T) {
+	cert, _ := selfSigned(t)
+	ln, done := serveSTARTTLS(t, cert)
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	m, err := NewEmailNotifier(config.SMTP{Host: "127.0.0.1", Port: port, From: "no-reply@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = m.Send(ctx, Message{To: "asha@example.com", Subject: "Welcome", Body: "See you soon"})
+	if err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Fatalf("send with an untrusted certificate returned %v, want a certificate error", err)
+	}
+	if s := <-done; s.from != "" {
+		t.Errorf("message was sent without TLS: %+v", s)
+	}
+}
@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"bytes"
+	"counterapp/internal/model"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+const dateLayout = "Monday, 02 Jan 2006"
+
+// notificationTemplate has a subject and body for email and a short text
+// for SMS and WhatsApp
+type notificationTemplate struct {
+	subject *template.Template
+	body    *template.Template
+	short   *template.Template
+}
+
+var templateFuncs = template.FuncMap{
+	"date": func(v any) string {
+		switch d := v.(type) {
+		case time.Time:
+			return d.Format(dateLayout)
+		case *time.Time:
+			if d == nil {
+				return ""
+			}
+			return d.Format(dateLayout)
+		}
+		return fmt.Sprint(v)
+	},
+}
+
+func mustTemplate(subject string, body string, short string) notificationTemplate {
+	return notificationTemplate{
+		subject: template.Must(template.New("subject").Funcs(templateFuncs).Parse(subject)),
+		body:    template.Must(template.New("body").Funcs(templateFuncs).Parse(body)),
+		short:   template.Must(template.New("short").Funcs(templateFuncs).Parse(short)),
+	}
+}
+
+var templates = map[model.NotificationTemplate]notificationTemplate{
+	model.TemplateVisitConfirmation: mustTemplate(
+		`Your stay is confirmed from {{date .ArrivalDate}}`,
+		`Namaskaram {{.Name}},
+
+You are checked in at {{.StayArea}} from {{date .ArrivalDate}}{{if .DepartureDate}} until {{date .DepartureDate}}{{end}}.
+{{if .Locker}}Your locker is {{.Locker}}.
+{{end}}
+Please carry your check-in slip and reach out at the counter if anything changes.
+
+Ashram Connect
+`,
+		`Namaskaram {{.Name}}, you are checked in at {{.StayArea}} from {{date .ArrivalDate}}{{if .Locker}}, locker {{.Locker}}{{end}}. - Ashram Connect`),
+
+	model.TemplateScheduleAssignment: mustTemplate(
+		`Seva assignment for {{date .Date}}: {{.SevaType}}`,
+		`Namaskaram {{.Name}},
+
+You have been assigned to {{.SevaType}} on {{date .Date}}{{if .Location}} at {{.Location}}{{end}}.
+{{if .Notes}}
+Notes: {{.Notes}}
+{{end}}
+Ashram Connect
+`,
+		`Namaskaram {{.Name}}, your seva on {{date .Date}} is {{.SevaType}}{{if .Location}} at {{.Location}}{{end}}. - Ashram Connect`),
+
+	model.TemplateDepartureReminder: mustTemplate(
+		`Reminder: your departure on {{date .DepartureDate}}`,
+		`Namaskaram {{.Name}},
+
+This is a reminder that your stay at {{.StayArea}} ends on {{date .DepartureDate}}.
+Please return your locker key and complete checkout at the counter before leaving.
+
+Ashram Connect
+`,
+		`Namaskaram {{.Name}}, your stay ends on {{date .DepartureDate}}. Please return your locker key and check out at the counter. - Ashram Connect`),
+}
+
+// renders the template for a channel, subject is empty for channels other
+// than email
+func render(name model.NotificationTemplate, channel model.NotificationChannel, data any) (subject string, body string, err error) {
+	tmpl, ok := templates[name]
+	if !ok {
+		return "", "", fmt.Errorf("unknown notification template: %s", name)
+	}
+
+	if channel != model.ChannelEmail {
+		var b bytes.Buffer
+		if err := tmpl.short.Execute(&b, data); err != nil {
+			return "", "", err
+		}
+		return "", b.String(), nil
+	}
+
+	var s, b bytes.Buffer
+	if err := tmpl.subject.Execute(&s, data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.body.Execute(&b, data); err != nil {
+		return "", "", err
+	}
+	return s.String(), b.String(), nil
+}
@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"counterapp/internal/model"
+	"counterapp/internal/util"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifier hands messages to an SMS or WhatsApp gateway over HTTP.
+// Phone numbers are normalized to E.164 before sending. The gateway receives
+// a JSON POST and must answer with a 2xx status:
+//
+//	{"channel": "sms", "to": "+919845012345", "body": "..."}
+type WebhookNotifier struct {
+	channel model.NotificationChannel
+	url     string
+	token   string
+	client  *http.Client
+
+	defaultCountryCode string
+}
+
+type webhookPayload struct {
+	Channel model.NotificationChannel `json:"channel"`
+	To      string                    `json:"to"`
+	Body    string                    `json:"body"`
+}
+
+func NewWebhookNotifier(channel model.NotificationChannel, url string, token string, defaultCountryCode string) *WebhookNotifier {
+	return &WebhookNotifier{
+		channel: channel,
+		url:     url,
+		token:   token,
+		client:  &http.Client{Timeout: 15 * time.Second},
+
+		defaultCountryCode: defaultCountryCode,
+	}
+}
+
+func (n *WebhookNotifier) Channel() model.NotificationChannel {
+	return n.channel
+}
+
+func (n *WebhookNotifier) Send(ctx context.Context, msg Message) error {
+	to, err := util.NormalizePhoneE164(msg.To, n.defaultCountryCode)
+	if err != nil {
+		return fmt.Errorf("invalid phone number %q: %w", msg.To, err)
+	}
+
+	payload, err := json.Marshal(webhookPayload{Channel: n.channel, To: to, Body: msg.Body})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s gateway returned %d: %s", n.channel, resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	rowHeight    = 7.0
+	bottomMargin = 15.0
+)
+
+// returns an A4 portrait document with page numbers and a generated-at
+// footer shared by all printouts
+func newDocument(title string) *fpdf.Fpdf {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(title, true)
+	pdf.SetCreator("Ashram Connect", true)
+	pdf.SetAutoPageBreak(false, bottomMargin)
+	pdf.AliasNbPages("")
+
+	generatedAt := time.Now().Format("02 Jan 2006 15:04")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-bottomMargin + 3)
+		pdf.SetFont("Helvetica", "I", 8)
+		width, _ := pdf.GetPageSize()
+		left, _, right, _ := pdf.GetMargins()
+		half := (width - left - right) / 2
+		pdf.CellFormat(half, 5, fmt.Sprintf("Generated %s", generatedAt), "", 0, "L", false, 0, "")
+		pdf.CellFormat(half, 5, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	return pdf
+}
+
+func writeTitle(pdf *fpdf.Fpdf, title string) {
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, title, "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+}
+
+// reports whether a block of the given height would run into the footer
+func pageBreakNeeded(pdf *fpdf.Fpdf, height float64) bool {
+	_, pageHeight := pdf.GetPageSize()
+	return pdf.GetY()+height > pageHeight-bottomMargin
+}
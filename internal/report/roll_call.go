@@ -0,0 +1,199 @@
+package report
+
+import (
+	"counterapp/internal/model"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+var rollCallColumns = []struct {
+	header string
+	width  float64
+}{
+	{"#", 8},
+	{"Volunteer", 50},
+	{"Phone", 32},
+	{"Locker", 22},
+	{"Seva Today", 53},
+	{"Accounted", 25},
+}
+
+// the width of the table, the details of a person span all its columns
+const rollCallWidth = 190
+
+// line height of the details under a person
+const detailHeight = 4.5
+
+// RollCall writes a roll call as a PDF, one group per stay area. Entries
+// have to be ordered by stay area like dao.SortRollCallEntries. Accounted
+// for people show the time they were marked, the others an empty box to
+// tick on paper. Emergency contacts and the medical info loaded with the
+// profile are printed under the person, leave the medical info out of
+// entries for readers who may not see it.
+func RollCall(w io.Writer, title string, at time.Time, entries []model.RollCallEntry) error {
+	pdf := newDocument(title)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	title = fmt.Sprintf("%s - %s", title, at.Format("02 Jan 2006 15:04"))
+
+	pdf.AddPage()
+	writeTitle(pdf, tr(title))
+	if len(entries) == 0 {
+		pdf.SetFont("Helvetica", "I", 11)
+		pdf.CellFormat(0, 8, "Nobody is checked in.", "", 1, "L", false, 0, "")
+		return pdf.Output(w)
+	}
+
+	accounted := 0
+	for _, e := range entries {
+		if e.AccountedAt != nil {
+			accounted++
+		}
+	}
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 7, fmt.Sprintf("%d on site, %d accounted for", len(entries), accounted), "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	for start := 0; start < len(entries); {
+		end := start
+		for end < len(entries) && entries[end].StayAreaID == entries[start].StayAreaID {
+			end++
+		}
+		if start > 0 {
+			pdf.Ln(6)
+		}
+		writeRollCallGroup(pdf, tr, title, entries[start:end])
+		start = end
+	}
+	return pdf.Output(w)
+}
+
+func writeRollCallGroup(pdf *fpdf.Fpdf, tr func(string) string, title string, entries []model.RollCallEntry) {
+	heading := tr(fmt.Sprintf("%s (%d)", entries[0].StayArea.Name, len(entries)))
+
+	// keep the heading together with at least a couple of rows
+	if pageBreakNeeded(pdf, 3*rowHeight) {
+		pdf.AddPage()
+		writeTitle(pdf, tr(title))
+	}
+	writeRollCallHeading(pdf, heading)
+
+	for i, e := range entries {
+		pdf.SetFont("Helvetica", "", 8)
+		details := wrapText(pdf, rollCallDetails(e), rollCallWidth)
+
+		// the details stay on the page of their row
+		if pageBreakNeeded(pdf, rowHeight+float64(len(details))*detailHeight) {
+			pdf.AddPage()
+			writeTitle(pdf, tr(title))
+			writeRollCallHeading(pdf, heading+" (cont.)")
+		}
+
+		var locker, seva, accounted string
+		if e.Visit.Locker != nil {
+			locker = fmt.Sprintf("%s %s", e.Visit.Locker.Section, e.Visit.Locker.LockerNumber)
+		}
+		if e.Schedule != nil {
+			seva = e.Schedule.SevaType.Name
+			if e.Schedule.Location != nil && *e.Schedule.Location != "" {
+				seva += " - " + *e.Schedule.Location
+			}
+		}
+		if e.AccountedAt != nil {
+			accounted = e.AccountedAt.Format("15:04")
+		}
+
+		values := []string{fmt.Sprint(i + 1), e.Visit.Profile.Name, e.Visit.Profile.PhoneNumber, locker, seva, accounted}
+		pdf.SetFont("Helvetica", "", 10)
+		for j, col := range rollCallColumns {
+			pdf.CellFormat(col.width, rowHeight, tr(fitText(pdf, values[j], col.width)), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+
+		pdf.SetFont("Helvetica", "", 8)
+		for j, line := range details {
+			border := "LR"
+			if j == len(details)-1 {
+				border = "LRB"
+			}
+			pdf.CellFormat(rollCallWidth, detailHeight, tr(line), border, 1, "L", false, 0, "")
+		}
+	}
+}
+
+// who to call and what to know about the person, empty when nothing is
+// stored
+func rollCallDetails(e model.RollCallEntry) string {
+	profile := e.Visit.Profile
+	var parts []string
+	if len(profile.EmergencyContacts) > 0 {
+		contacts := make([]string, len(profile.EmergencyContacts))
+		for i, contact := range profile.EmergencyContacts {
+			contacts[i] = contact.Name
+			if contact.Relation != "" {
+				contacts[i] += " (" + contact.Relation + ")"
+			}
+			contacts[i] += " " + contact.PhoneNumber
+		}
+		parts = append(parts, "Emergency: "+strings.Join(contacts, ", "))
+	}
+	if medical := profile.Medical; medical != nil {
+		if medical.Notes != nil {
+			parts = append(parts, "Medical: "+*medical.Notes)
+		}
+		if medical.DietaryNeeds != nil {
+			parts = append(parts, "Diet: "+*medical.DietaryNeeds)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+func writeRollCallHeading(pdf *fpdf.Fpdf, heading string) {
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(0, 8, heading, "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(230, 230, 230)
+	for _, col := range rollCallColumns {
+		pdf.CellFormat(col.width, rowHeight, col.header, "1", 0, "L", true, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+// shortens text with an ellipsis so it stays inside a cell of width
+func fitText(pdf *fpdf.Fpdf, text string, width float64) string {
+	const padding = 2
+	if pdf.GetStringWidth(text) <= width-padding {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width-padding {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// breaks text into lines that fit a cell of width at the current font, words
+// longer than a line are shortened
+func wrapText(pdf *fpdf.Fpdf, text string, width float64) []string {
+	const padding = 2
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && pdf.GetStringWidth(line+" "+word) <= width-padding {
+			line += " " + word
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = fitText(pdf, word, width)
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,146 @@
+package report
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/util"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const unassignedLocation = "Location not set"
+
+type RosterOptions struct {
+	// starts every seva type and location group on a new page so each one
+	// can be posted at its location
+	PagePerLocation bool
+}
+
+type rosterGroup struct {
+	sevaType string
+	location string
+	entries  []rosterEntry
+}
+
+type rosterEntry struct {
+	name     string
+	stayArea string
+	locker   string
+}
+
+var rosterColumns = []struct {
+	header string
+	width  float64
+}{
+	{"#", 10},
+	{"Volunteer", 75},
+	{"Stay Area", 60},
+	{"Locker", 45},
+}
+
+// Roster writes the seva roster for a single day as a PDF, grouped by seva
+// type and location
+func Roster(w io.Writer, date time.Time, schedules []model.Schedule, opts RosterOptions) error {
+	pdf := newDocument(fmt.Sprintf("Seva Roster %s", util.FormatDate(date)))
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	title := fmt.Sprintf("Seva Roster - %s", date.Format("Monday, 02 Jan 2006"))
+
+	groups := groupRoster(schedules)
+	if len(groups) == 0 {
+		pdf.AddPage()
+		writeTitle(pdf, tr(title))
+		pdf.SetFont("Helvetica", "I", 11)
+		pdf.CellFormat(0, 8, "No seva scheduled for this date.", "", 1, "L", false, 0, "")
+		return pdf.Output(w)
+	}
+
+	for i, group := range groups {
+		if i == 0 || opts.PagePerLocation {
+			pdf.AddPage()
+			writeTitle(pdf, tr(title))
+		} else {
+			pdf.Ln(6)
+		}
+		writeRosterGroup(pdf, tr, title, group)
+	}
+	return pdf.Output(w)
+}
+
+func writeRosterGroup(pdf *fpdf.Fpdf, tr func(string) string, title string, group rosterGroup) {
+	heading := tr(fmt.Sprintf("%s - %s (%d)", group.sevaType, group.location, len(group.entries)))
+
+	// keep the heading together with at least a couple of rows
+	if pageBreakNeeded(pdf, 3*rowHeight) {
+		pdf.AddPage()
+		writeTitle(pdf, tr(title))
+	}
+	writeGroupHeading(pdf, heading)
+
+	for i, entry := range group.entries {
+		if pageBreakNeeded(pdf, rowHeight) {
+			pdf.AddPage()
+			writeTitle(pdf, tr(title))
+			writeGroupHeading(pdf, heading+" (cont.)")
+		}
+		values := []string{fmt.Sprint(i + 1), entry.name, entry.stayArea, entry.locker}
+		pdf.SetFont("Helvetica", "", 10)
+		for j, col := range rosterColumns {
+			pdf.CellFormat(col.width, rowHeight, tr(values[j]), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+func writeGroupHeading(pdf *fpdf.Fpdf, heading string) {
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(0, 8, heading, "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(230, 230, 230)
+	for _, col := range rosterColumns {
+		pdf.CellFormat(col.width, rowHeight, col.header, "1", 0, "L", true, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+func groupRoster(schedules []model.Schedule) []rosterGroup {
+	byKey := make(map[[2]string]*rosterGroup)
+	var groups []*rosterGroup
+
+	for _, s := range schedules {
+		location := unassignedLocation
+		if s.Location != nil && *s.Location != "" {
+			location = *s.Location
+		}
+		key := [2]string{s.SevaType.Name, location}
+		group, ok := byKey[key]
+		if !ok {
+			group = &rosterGroup{sevaType: s.SevaType.Name, location: location}
+			byKey[key] = group
+			groups = append(groups, group)
+		}
+
+		entry := rosterEntry{name: s.Profile.Name, stayArea: s.Visit.StayArea.Name}
+		if s.Visit.Locker != nil {
+			entry.locker = fmt.Sprintf("%s %s", s.Visit.Locker.Section, s.Visit.Locker.LockerNumber)
+		}
+		group.entries = append(group.entries, entry)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].sevaType != groups[j].sevaType {
+			return groups[i].sevaType < groups[j].sevaType
+		}
+		return groups[i].location < groups[j].location
+	})
+
+	result := make([]rosterGroup, 0, len(groups))
+	for _, g := range groups {
+		sort.Slice(g.entries, func(i, j int) bool { return g.entries[i].name < g.entries[j].name })
+		result = append(result, *g)
+	}
+	return result
+}
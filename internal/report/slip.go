@@ -0,0 +1,193 @@
+package report
+
+import (
+	"bytes"
+	"counterapp/internal/model"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// qrPrefix marks QR codes printed by this app so scanners at any desk can
+// tell them apart from other codes
+const qrPrefix = "counterapp:visit:"
+
+const (
+	slipWidthMM  = 100.0
+	slipHeightMM = 150.0
+	slipQRSize   = 512
+	slipPNGWidth = 600
+	slipPNGScale = 2
+)
+
+// Slip holds what gets printed on a check-in slip for a visit
+type Slip struct {
+	VisitID       uuid.UUID
+	Name          string
+	Category      string
+	StayArea      string
+	Locker        string
+	ArrivalDate   time.Time
+	DepartureDate *time.Time
+}
+
+// builds a slip from a visit with Profile, StayArea and Locker preloaded
+func NewSlip(visit *model.Visit) Slip {
+	slip := Slip{
+		VisitID:       visit.ID,
+		Name:          visit.Profile.Name,
+		Category:      string(visit.Profile.Category),
+		StayArea:      visit.StayArea.Name,
+		ArrivalDate:   visit.ArrivalDate,
+		DepartureDate: visit.DepartureDate,
+	}
+	if visit.Locker != nil {
+		slip.Locker = fmt.Sprintf("%s %s", visit.Locker.Section, visit.Locker.LockerNumber)
+	}
+	return slip
+}
+
+func QRPayload(visitID uuid.UUID) string {
+	return qrPrefix + visitID.String()
+}
+
+// extracts the visit ID from a scanned QR code. A bare visit ID is accepted
+// too so the code can be typed in when a scanner is not at hand.
+func ParseQRPayload(code string) (uuid.UUID, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return uuid.Nil, errors.New("empty code")
+	}
+	return uuid.Parse(strings.TrimPrefix(code, qrPrefix))
+}
+
+func (s Slip) lines() [][2]string {
+	departure := "Not set"
+	if s.DepartureDate != nil {
+		departure = s.DepartureDate.Format("02 Jan 2006")
+	}
+	locker := s.Locker
+	if locker == "" {
+		locker = "Not assigned"
+	}
+	category := s.Category
+	if category == "" {
+		category = "-"
+	}
+	return [][2]string{
+		{"Category", category},
+		{"Stay Area", s.StayArea},
+		{"Locker", locker},
+		{"Arrival", s.ArrivalDate.Format("02 Jan 2006")},
+		{"Departure", departure},
+	}
+}
+
+func (s Slip) qrPNG() ([]byte, error) {
+	return qrcode.Encode(QRPayload(s.VisitID), qrcode.Medium, slipQRSize)
+}
+
+// SlipPDF writes a label sized check-in slip
+func SlipPDF(w io.Writer, s Slip) error {
+	qr, err := s.qrPNG()
+	if err != nil {
+		return err
+	}
+
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		UnitStr: "mm",
+		Size:    fpdf.SizeType{Wd: slipWidthMM, Ht: slipHeightMM},
+	})
+	pdf.SetTitle("Check-in Slip", true)
+	pdf.SetMargins(8, 8, 8)
+	pdf.SetAutoPageBreak(false, 0)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, "CHECK-IN SLIP", "B", 1, "C", false, 0, "")
+	pdf.Ln(3)
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.MultiCell(0, 7, tr(s.Name), "", "C", false)
+	pdf.Ln(2)
+
+	for _, line := range s.lines() {
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(28, 7, line[0], "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(0, 7, tr(line[1]), "", 1, "L", false, 0, "")
+	}
+
+	opts := fpdf.ImageOptions{ImageType: "PNG"}
+	pdf.RegisterImageOptionsReader("qr", opts, bytes.NewReader(qr))
+	qrSize := 55.0
+	pdf.ImageOptions("qr", (slipWidthMM-qrSize)/2, pdf.GetY()+3, qrSize, qrSize, false, opts, 0, "")
+
+	pdf.SetY(slipHeightMM - 14)
+	pdf.SetFont("Courier", "", 7)
+	pdf.CellFormat(0, 4, s.VisitID.String(), "", 1, "C", false, 0, "")
+
+	return pdf.Output(w)
+}
+
+// SlipPNG writes the check-in slip as an image for label printers that
+// only take raster input
+func SlipPNG(w io.Writer, s Slip) error {
+	qrImage, err := qrcode.New(QRPayload(s.VisitID), qrcode.Medium)
+	if err != nil {
+		return err
+	}
+
+	// text is drawn with the fixed 7x13 font on a half size canvas and scaled
+	// up so it stays legible on paper
+	const textWidth = slipPNGWidth / slipPNGScale
+	textLines := []string{"CHECK-IN SLIP", "", s.Name, ""}
+	for _, line := range s.lines() {
+		textLines = append(textLines, fmt.Sprintf("%-10s %s", line[0]+":", line[1]))
+	}
+	lineHeight := basicfont.Face7x13.Metrics().Height.Ceil() + 4
+	textHeight := (len(textLines) + 1) * lineHeight
+
+	text := image.NewRGBA(image.Rect(0, 0, textWidth, textHeight))
+	xdraw.Draw(text, text.Bounds(), image.White, image.Point{}, xdraw.Src)
+	drawer := &font.Drawer{Dst: text, Src: image.Black, Face: basicfont.Face7x13}
+	for i, line := range textLines {
+		drawer.Dot = fixed.P(10, (i+1)*lineHeight)
+		drawer.DrawString(line)
+	}
+
+	qrSize := slipPNGWidth - 120
+	idHeight := 2 * lineHeight
+	height := textHeight*slipPNGScale + qrSize + idHeight*slipPNGScale
+	img := image.NewRGBA(image.Rect(0, 0, slipPNGWidth, height))
+	xdraw.Draw(img, img.Bounds(), image.White, image.Point{}, xdraw.Src)
+	xdraw.NearestNeighbor.Scale(img, image.Rect(0, 0, slipPNGWidth, textHeight*slipPNGScale), text, text.Bounds(), xdraw.Src, nil)
+
+	qrTop := textHeight * slipPNGScale
+	qrRect := image.Rect((slipPNGWidth-qrSize)/2, qrTop, (slipPNGWidth+qrSize)/2, qrTop+qrSize)
+	xdraw.Draw(img, qrRect, qrImage.Image(qrSize), image.Point{}, xdraw.Src)
+
+	id := image.NewRGBA(image.Rect(0, 0, textWidth, idHeight))
+	xdraw.Draw(id, id.Bounds(), image.White, image.Point{}, xdraw.Src)
+	idDrawer := &font.Drawer{Dst: id, Src: image.NewUniform(color.Gray{Y: 80}), Face: basicfont.Face7x13}
+	idText := s.VisitID.String()
+	idDrawer.Dot = fixed.P((textWidth-idDrawer.MeasureString(idText).Ceil())/2, lineHeight)
+	idDrawer.DrawString(idText)
+	xdraw.NearestNeighbor.Scale(img, image.Rect(0, qrRect.Max.Y, slipPNGWidth, height), id, id.Bounds(), xdraw.Src, nil)
+
+	return png.Encode(w, img)
+}
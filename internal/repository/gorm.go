@@ -0,0 +1,473 @@
+package repository
+
+import (
+	"context"
+	"counterapp/internal/dao"
+	"counterapp/internal/migrations"
+	"counterapp/internal/model"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NewGormRepositories returns repositories backed by the dao package. The
+// connection should be opened with TranslateError so unique violations are
+// reported as ErrDuplicate.
+func NewGormRepositories(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Profiles:      &gormProfiles{db: db},
+		Visits:        &gormVisits{db: db},
+		Schedules:     &gormSchedules{db: db},
+		Feedbacks:     &gormFeedbacks{db: db},
+		SevaTypes:     &gormSevaTypes{db: db},
+		StayAreas:     &gormStayAreas{db: db},
+		Lockers:       &gormLockers{db: db},
+		Notifications: &gormNotifications{db: db},
+		Webhooks:      &gormWebhooks{db: db},
+		Users:         &gormUsers{db: db},
+		Audit:         &gormAudit{db: db},
+		RollCalls:     &gormRollCalls{db: db},
+		Retention:     &gormRetention{db: db},
+		Exports:       &gormExports{db: db},
+		Health:        &gormHealth{db: db},
+		Transactions:  &gormTransactions{db: db},
+	}
+}
+
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicate
+	}
+	return err
+}
+
+// PostgreSQL rejects malformed UUIDs with a syntax error, to callers such an
+// ID simply matches nothing
+func validID(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil
+}
+
+// wraps a dao call, translating gorm errors
+func result[T any](value T, err error) (T, error) {
+	if err != nil {
+		var zero T
+		return zero, translateError(err)
+	}
+	return value, nil
+}
+
+type gormProfiles struct {
+	db *gorm.DB
+}
+
+func (r *gormProfiles) GetProfilesData() ([]model.ProfileOverview, error) {
+	return result(dao.GetProfilesData(r.db))
+}
+
+func (r *gormProfiles) GetByID(profileID string) (*model.Profile, error) {
+	if !validID(profileID) {
+		return nil, ErrNotFound
+	}
+	return result(dao.GetProfileByID(r.db, profileID))
+}
+
+func (r *gormProfiles) GetByIDWithDeleted(profileID string) (*model.Profile, error) {
+	if !validID(profileID) {
+		return nil, ErrNotFound
+	}
+	return result(dao.GetProfileByIDWithDeleted(r.db, profileID))
+}
+
+func (r *gormProfiles) GetByEmail(email string) (*model.Profile, error) {
+	return result(dao.GetProfileByEmail(r.db, email))
+}
+
+func (r *gormProfiles) Create(profile *model.Profile) (*model.Profile, error) {
+	return result(dao.CreateProfile(r.db, profile))
+}
+
+func (r *gormProfiles) Update(profileID string, updates *model.ProfileUpdate) (*model.Profile, error) {
+	if !validID(profileID) {
+		return nil, ErrNotFound
+	}
+	return result(dao.UpdateProfile(r.db, profileID, updates))
+}
+
+func (r *gormProfiles) SetMedical(profileID string, medical *model.MedicalInfo) (*model.Profile, error) {
+	if !validID(profileID) {
+		return nil, ErrNotFound
+	}
+	return result(dao.SetProfileMedical(r.db, profileID, medical))
+}
+
+func (r *gormProfiles) Delete(profileID string) error {
+	if !validID(profileID) {
+		return ErrNotFound
+	}
+	return translateError(dao.DeleteProfile(r.db, profileID))
+}
+
+func (r *gormProfiles) Restore(profileID string) (*model.Profile, error) {
+	if !validID(profileID) {
+		return nil, ErrNotFound
+	}
+	return result(dao.RestoreProfile(r.db, profileID))
+}
+
+func (r *gormProfiles) Purge(profileID string) error {
+	if !validID(profileID) {
+		return ErrNotFound
+	}
+	return translateError(dao.PurgeProfile(r.db, profileID))
+}
+
+func (r *gormProfiles) ExportPersonalData(profileID string, actorID *uuid.UUID) (*model.PersonalData, error) {
+	if !validID(profileID) {
+		return nil, ErrNotFound
+	}
+	return result(dao.ExportPersonalData(r.db, profileID, actorID))
+}
+
+func (r *gormProfiles) Erase(profileID string, actorID *uuid.UUID) (*model.Profile, error) {
+	if !validID(profileID) {
+		return nil, ErrNotFound
+	}
+	return result(dao.EraseProfile(r.db, profileID, actorID, ""))
+}
+
+type gormVisits struct {
+	db *gorm.DB
+}
+
+func (r *gormVisits) GetByProfileID(profileID string) ([]model.Visit, error) {
+	if !validID(profileID) {
+		return nil, nil
+	}
+	return result(dao.GetVisitsByProfileID(r.db, profileID))
+}
+
+func (r *gormVisits) GetByID(visitID string) (*model.Visit, error) {
+	if !validID(visitID) {
+		return nil, ErrNotFound
+	}
+	return result(dao.GetVisitByID(r.db, visitID))
+}
+
+func (r *gormVisits) GetDetailsByID(visitID string) (*model.Visit, error) {
+	if !validID(visitID) {
+		return nil, ErrNotFound
+	}
+	return result(dao.GetVisitDetailsByID(r.db, visitID))
+}
+
+func (r *gormVisits) GetByProfileAndArrivalDate(profileID string, arrivalDate time.Time) (*model.Visit, error) {
+	if !validID(profileID) {
+		return nil, nil
+	}
+	return result(dao.GetVisitByProfileAndArrivalDate(r.db, profileID, arrivalDate))
+}
+
+func (r *gormVisits) GetOverdue(today time.Time) ([]model.Visit, error) {
+	return result(dao.GetOverdueVisits(r.db, today))
+}
+
+func (r *gormVisits) Add(req model.AddVisitRequest) (*model.Visit, error) {
+	return result(dao.AddVisit(r.db, req))
+}
+
+func (r *gormVisits) Update(visitID string, req model.UpdateVisitRequest) (*model.Visit, error) {
+	if !validID(visitID) {
+		return nil, ErrNotFound
+	}
+	return result(dao.UpdateVisit(r.db, visitID, req))
+}
+
+func (r *gormVisits) Delete(visitID string) error {
+	if !validID(visitID) {
+		return ErrNotFound
+	}
+	return translateError(dao.DeleteVisit(r.db, visitID))
+}
+
+func (r *gormVisits) Restore(visitID string) (*model.Visit, error) {
+	if !validID(visitID) {
+		return nil, ErrNotFound
+	}
+	return result(dao.RestoreVisit(r.db, visitID))
+}
+
+func (r *gormVisits) Purge(visitID string) error {
+	if !validID(visitID) {
+		return ErrNotFound
+	}
+	return translateError(dao.PurgeVisit(r.db, visitID))
+}
+
+type gormSchedules struct {
+	db *gorm.DB
+}
+
+func (r *gormSchedules) GetForDateRange(startDate time.Time, endDate time.Time) ([]model.Schedule, error) {
+	return result(dao.GetScheduleForDateRange(r.db, startDate, endDate))
+}
+
+func (r *gormSchedules) GetByProfileAndDate(profileID string, date time.Time) (*model.Schedule, error) {
+	if !validID(profileID) {
+		return nil, nil
+	}
+	return result(dao.GetScheduleByProfileAndDate(r.db, profileID, date))
+}
+
+func (r *gormSchedules) Add(req model.AddScheduleRequest) (*model.Schedule, error) {
+	return result(dao.AddSchedule(r.db, req))
+}
+
+type gormFeedbacks struct {
+	db *gorm.DB
+}
+
+func (r *gormFeedbacks) GetAll() ([]model.Feedback, error) {
+	return result(dao.GetAllFeedbacks(r.db))
+}
+
+func (r *gormFeedbacks) GetForProfile(profileID string) ([]model.Feedback, error) {
+	if !validID(profileID) {
+		return nil, nil
+	}
+	return result(dao.GetFeedbacksForProfile(r.db, profileID))
+}
+
+func (r *gormFeedbacks) Add(req model.AddFeedbackRequest) (*model.Feedback, error) {
+	return result(dao.AddFeedback(r.db, req))
+}
+
+type gormSevaTypes struct {
+	db *gorm.DB
+}
+
+func (r *gormSevaTypes) GetAll() ([]model.SevaType, error) {
+	return result(dao.GetAllSevaTypes(r.db))
+}
+
+func (r *gormSevaTypes) GetByID(id string) (*model.SevaType, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	return result(dao.GetSevaTypeByID(r.db, id))
+}
+
+func (r *gormSevaTypes) GetByName(name string) (*model.SevaType, error) {
+	return result(dao.GetSevaTypeByName(r.db, name))
+}
+
+func (r *gormSevaTypes) Add(req model.AddSevaTypeRequest) (*model.SevaType, error) {
+	return result(dao.AddSevaType(r.db, req))
+}
+
+type gormStayAreas struct {
+	db *gorm.DB
+}
+
+func (r *gormStayAreas) GetAll() ([]model.StayArea, error) {
+	return result(dao.GetAllStayAreas(r.db))
+}
+
+func (r *gormStayAreas) GetByID(id string) (*model.StayArea, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	return result(dao.GetStayAreaByID(r.db, id))
+}
+
+func (r *gormStayAreas) GetByName(name string) (*model.StayArea, error) {
+	return result(dao.GetStayAreaByName(r.db, name))
+}
+
+func (r *gormStayAreas) Add(req model.AddStayAreaRequest) (*model.StayArea, error) {
+	return result(dao.AddStayArea(r.db, req))
+}
+
+func (r *gormStayAreas) GetAllWithOccupancy() ([]model.StayAreaOccupancy, error) {
+	return result(dao.GetAllStayAreasWithOccupancy(r.db))
+}
+
+type gormLockers struct {
+	db *gorm.DB
+}
+
+func (r *gormLockers) GetAll() ([]model.Locker, error) {
+	return result(dao.GetAllLockers(r.db))
+}
+
+type gormNotifications struct {
+	db *gorm.DB
+}
+
+func (r *gormNotifications) Enqueue(msg *model.OutboxMessage) error {
+	return translateError(dao.CreateOutboxMessage(r.db, msg))
+}
+
+func (r *gormNotifications) List(status string, limit int) ([]model.OutboxMessage, error) {
+	return result(dao.GetOutboxMessages(r.db, status, limit))
+}
+
+func (r *gormNotifications) Retry(id string) (*model.OutboxMessage, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	return result(dao.RetryOutboxMessage(r.db, id))
+}
+
+type gormWebhooks struct {
+	db *gorm.DB
+}
+
+func (r *gormWebhooks) GetAllSubscriptions() ([]model.WebhookSubscription, error) {
+	return result(dao.GetAllWebhookSubscriptions(r.db))
+}
+
+func (r *gormWebhooks) AddSubscription(req model.AddWebhookSubscriptionRequest) (*model.WebhookSubscription, error) {
+	return result(dao.AddWebhookSubscription(r.db, req))
+}
+
+func (r *gormWebhooks) DeactivateSubscription(id string) error {
+	if !validID(id) {
+		return ErrNotFound
+	}
+	return translateError(dao.DeactivateWebhookSubscription(r.db, id))
+}
+
+func (r *gormWebhooks) GetDeliveries(subscriptionID string, limit int) ([]model.WebhookDelivery, error) {
+	if !validID(subscriptionID) {
+		return nil, nil
+	}
+	return result(dao.GetWebhookDeliveries(r.db, subscriptionID, limit))
+}
+
+func (r *gormWebhooks) Redeliver(deliveryID string) (*model.WebhookDelivery, error) {
+	if !validID(deliveryID) {
+		return nil, ErrNotFound
+	}
+	return result(dao.RedeliverWebhookDelivery(r.db, deliveryID))
+}
+
+type gormUsers struct {
+	db *gorm.DB
+}
+
+func (r *gormUsers) Create(user *model.User) (*model.User, error) {
+	return result(dao.CreateUser(r.db, user))
+}
+
+func (r *gormUsers) GetByTokenHash(tokenHash string) (*model.User, error) {
+	return result(dao.GetUserByTokenHash(r.db, tokenHash))
+}
+
+type gormAudit struct {
+	db *gorm.DB
+}
+
+func (r *gormAudit) List(filter model.AuditFilter) ([]model.AuditRecord, error) {
+	return result(dao.GetAuditRecords(r.db, filter))
+}
+
+type gormRollCalls struct {
+	db *gorm.DB
+}
+
+func (r *gormRollCalls) OnSite(today time.Time) ([]model.RollCallEntry, error) {
+	return result(dao.GetOnSite(r.db, today))
+}
+
+func (r *gormRollCalls) Start(note *string, startedBy *uuid.UUID, today time.Time) (*model.RollCall, error) {
+	return result(dao.StartRollCall(r.db, note, startedBy, today))
+}
+
+func (r *gormRollCalls) List(limit int) ([]model.RollCallSummary, error) {
+	return result(dao.GetRollCalls(r.db, limit))
+}
+
+func (r *gormRollCalls) GetByID(id string) (*model.RollCall, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	return result(dao.GetRollCall(r.db, id))
+}
+
+func (r *gormRollCalls) MarkEntry(rollCallID string, entryID string, accounted bool, by *uuid.UUID) (*model.RollCallEntry, error) {
+	if !validID(rollCallID) || !validID(entryID) {
+		return nil, ErrNotFound
+	}
+	return result(dao.MarkRollCallEntry(r.db, rollCallID, entryID, accounted, by))
+}
+
+func (r *gormRollCalls) Close(id string) (*model.RollCall, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	return result(dao.CloseRollCall(r.db, id))
+}
+
+type gormRetention struct {
+	db *gorm.DB
+}
+
+func (r *gormRetention) Plan(policy model.RetentionPolicy, now time.Time) (*model.RetentionReport, error) {
+	return dao.PlanRetention(r.db, policy, now)
+}
+
+func (r *gormRetention) Apply(policy model.RetentionPolicy, now time.Time) (*model.RetentionReport, error) {
+	return dao.ApplyRetention(r.db, policy, now)
+}
+
+type gormExports struct {
+	db *gorm.DB
+}
+
+func (r *gormExports) Schedules(startDate time.Time, endDate time.Time, fn func(model.ScheduleExportRow) error) error {
+	return dao.StreamSchedulesForDateRange(r.db, startDate, endDate, fn)
+}
+
+func (r *gormExports) Residents(stayAreaID string, fn func(model.ResidentExportRow) error) error {
+	if stayAreaID != "" && !validID(stayAreaID) {
+		return nil
+	}
+	return dao.StreamCheckedInResidents(r.db, stayAreaID, fn)
+}
+
+func (r *gormExports) Profiles(fn func(model.ProfileOverview) error) error {
+	return dao.StreamProfilesData(r.db, fn)
+}
+
+type gormHealth struct {
+	db *gorm.DB
+}
+
+func (r *gormHealth) Ping(ctx context.Context) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (r *gormHealth) CheckSchema(ctx context.Context) error {
+	return migrations.Check(r.db.WithContext(ctx))
+}
+
+type gormTransactions struct {
+	db *gorm.DB
+}
+
+// errors returned by fn are passed through as they are
+func (r *gormTransactions) Run(fn func(repos *Repositories) error) error {
+	return dao.Transaction(r.db, func(tx *gorm.DB) error {
+		return fn(NewGormRepositories(tx))
+	})
+}
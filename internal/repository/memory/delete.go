@@ -0,0 +1,214 @@
+package memory
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// the soft deleted rows, keyed like the live ones
+type trash struct {
+	profiles  map[uuid.UUID]model.Profile
+	visits    map[uuid.UUID]model.Visit
+	schedules map[uuid.UUID]model.Schedule
+	feedbacks map[uuid.UUID]model.Feedback
+}
+
+// moves the matching rows of live to deleted, stamped with at
+func discard[T any](live, deleted map[uuid.UUID]T, at time.Time, stamp func(*T, gorm.DeletedAt), match func(T) bool) {
+	for id, row := range live {
+		if match(row) {
+			stamp(&row, gorm.DeletedAt{Time: at, Valid: true})
+			deleted[id] = row
+			delete(live, id)
+		}
+	}
+}
+
+// moves the matching rows of deleted back to live
+func undelete[T any](deleted, live map[uuid.UUID]T, stamp func(*T, gorm.DeletedAt), match func(T) bool) {
+	for id, row := range deleted {
+		if match(row) {
+			stamp(&row, gorm.DeletedAt{})
+			live[id] = row
+			delete(deleted, id)
+		}
+	}
+}
+
+func drop[T any](rows map[uuid.UUID]T, match func(T) bool) {
+	for id, row := range rows {
+		if match(row) {
+			delete(rows, id)
+		}
+	}
+}
+
+func stampVisit(v *model.Visit, at gorm.DeletedAt)         { v.DeletedAt = at }
+func stampSchedule(sch *model.Schedule, at gorm.DeletedAt) { sch.DeletedAt = at }
+func stampFeedback(f *model.Feedback, at gorm.DeletedAt)   { f.DeletedAt = at }
+
+func (r *profiles) Delete(profileID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	id, _ := parseID(profileID)
+	profile, ok := r.s.profiles[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	now := r.s.now()
+	discard(r.s.schedules, r.s.deleted.schedules, now, stampSchedule, func(sch model.Schedule) bool { return sch.ProfileID == id })
+	discard(r.s.feedbacks, r.s.deleted.feedbacks, now, stampFeedback, func(f model.Feedback) bool { return f.ProfileID == id })
+	discard(r.s.visits, r.s.deleted.visits, now, stampVisit, func(v model.Visit) bool { return v.ProfileID == id })
+
+	profile.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	r.s.deleted.profiles[id] = profile
+	delete(r.s.profiles, id)
+	return nil
+}
+
+func (r *profiles) Restore(profileID string) (*model.Profile, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	id, _ := parseID(profileID)
+	profile, ok := r.s.deleted.profiles[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	for _, p := range r.s.profiles {
+		if p.Email == profile.Email {
+			return nil, repository.ErrDuplicate
+		}
+	}
+
+	deletedAt := profile.DeletedAt.Time
+	undelete(r.s.deleted.visits, r.s.visits, stampVisit, func(v model.Visit) bool {
+		return v.ProfileID == id && v.DeletedAt.Time.Equal(deletedAt)
+	})
+	undelete(r.s.deleted.schedules, r.s.schedules, stampSchedule, func(sch model.Schedule) bool {
+		return sch.ProfileID == id && sch.DeletedAt.Time.Equal(deletedAt)
+	})
+	undelete(r.s.deleted.feedbacks, r.s.feedbacks, stampFeedback, func(f model.Feedback) bool {
+		return f.ProfileID == id && f.DeletedAt.Time.Equal(deletedAt)
+	})
+
+	profile.DeletedAt = gorm.DeletedAt{}
+	r.s.profiles[id] = profile
+	delete(r.s.deleted.profiles, id)
+	return &profile, nil
+}
+
+func (r *profiles) Purge(profileID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	id, _ := parseID(profileID)
+	if _, ok := r.s.deleted.profiles[id]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.purgeProfile(id)
+	return nil
+}
+
+// callers hold the write lock and have checked the profile is deleted
+func (s *Store) purgeProfile(id uuid.UUID) {
+	drop(s.deleted.schedules, func(sch model.Schedule) bool { return sch.ProfileID == id })
+	drop(s.deleted.feedbacks, func(f model.Feedback) bool { return f.ProfileID == id })
+	s.dropRollCallEntries(func(visitID uuid.UUID) bool { return s.deleted.visits[visitID].ProfileID == id })
+	drop(s.deleted.visits, func(v model.Visit) bool { return v.ProfileID == id })
+	delete(s.deleted.profiles, id)
+}
+
+func (r *visits) Delete(visitID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	id, _ := parseID(visitID)
+	visit, ok := r.s.visits[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	now := r.s.now()
+	discard(r.s.schedules, r.s.deleted.schedules, now, stampSchedule, func(sch model.Schedule) bool { return sch.VisitID == id })
+	discard(r.s.feedbacks, r.s.deleted.feedbacks, now, stampFeedback, func(f model.Feedback) bool {
+		return f.VisitID != nil && *f.VisitID == id
+	})
+
+	visit.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	r.s.deleted.visits[id] = visit
+	delete(r.s.visits, id)
+	return nil
+}
+
+func (r *visits) Restore(visitID string) (*model.Visit, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	id, _ := parseID(visitID)
+	visit, ok := r.s.deleted.visits[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if _, ok := r.s.profiles[visit.ProfileID]; !ok {
+		return nil, repository.ErrParentDeleted
+	}
+
+	deletedAt := visit.DeletedAt.Time
+	ofVisit := func(sch model.Schedule) bool { return sch.VisitID == id && sch.DeletedAt.Time.Equal(deletedAt) }
+	// the unique indexes: one checked-in visit and one seva a day per profile
+	if visit.Status == model.StatusCheckedIn {
+		for _, v := range r.s.visits {
+			if v.ProfileID == visit.ProfileID && v.Status == model.StatusCheckedIn {
+				return nil, repository.ErrDuplicate
+			}
+		}
+	}
+	for _, restored := range r.s.deleted.schedules {
+		if !ofVisit(restored) {
+			continue
+		}
+		for _, sch := range r.s.schedules {
+			if sch.ProfileID == restored.ProfileID && sch.Date.Equal(restored.Date) {
+				return nil, repository.ErrDuplicate
+			}
+		}
+	}
+
+	undelete(r.s.deleted.schedules, r.s.schedules, stampSchedule, ofVisit)
+	undelete(r.s.deleted.feedbacks, r.s.feedbacks, stampFeedback, func(f model.Feedback) bool {
+		return f.VisitID != nil && *f.VisitID == id && f.DeletedAt.Time.Equal(deletedAt)
+	})
+
+	visit.DeletedAt = gorm.DeletedAt{}
+	r.s.visits[id] = visit
+	delete(r.s.deleted.visits, id)
+	visit = r.s.withPlace(visit)
+	return &visit, nil
+}
+
+func (r *visits) Purge(visitID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	id, _ := parseID(visitID)
+	if _, ok := r.s.deleted.visits[id]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.purgeVisit(id)
+	return nil
+}
+
+// callers hold the write lock and have checked the visit is deleted
+func (s *Store) purgeVisit(id uuid.UUID) {
+	drop(s.deleted.schedules, func(sch model.Schedule) bool { return sch.VisitID == id })
+	drop(s.deleted.feedbacks, func(f model.Feedback) bool { return f.VisitID != nil && *f.VisitID == id })
+	s.dropRollCallEntries(func(visitID uuid.UUID) bool { return visitID == id })
+	delete(s.deleted.visits, id)
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"counterapp/internal/model"
+	"slices"
+	"time"
+)
+
+type exports struct {
+	s *Store
+}
+
+// calls fn with every row after the lock is released, so a slow reader of
+// the export does not hold up writes
+func each[T any](rows []T, fn func(T) error) error {
+	for _, row := range rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *exports) Schedules(startDate time.Time, endDate time.Time, fn func(model.ScheduleExportRow) error) error {
+	r.s.mu.RLock()
+	var rows []model.ScheduleExportRow
+	for _, sch := range r.s.schedules {
+		if sch.Date.Before(startDate) || sch.Date.After(endDate) {
+			continue
+		}
+		// the exports join deleted profiles too
+		profile, ok := r.s.profiles[sch.ProfileID]
+		if !ok {
+			profile = r.s.deleted.profiles[sch.ProfileID]
+		}
+		visit := r.s.withPlace(r.s.visits[sch.VisitID])
+		row := model.ScheduleExportRow{
+			ID:            sch.ID.String(),
+			Date:          sch.Date,
+			ProfileName:   profile.Name,
+			Email:         profile.Email,
+			PhoneNumber:   profile.PhoneNumber,
+			Gender:        string(profile.Gender),
+			Category:      string(profile.Category),
+			SevaType:      r.s.sevaTypes[sch.SevaTypeID].Name,
+			Location:      sch.Location,
+			Notes:         sch.Notes,
+			ArrivalDate:   visit.ArrivalDate,
+			DepartureDate: visit.DepartureDate,
+		}
+		if _, ok := r.s.stayAreas[visit.StayAreaID]; ok {
+			row.StayArea = &visit.StayArea.Name
+		}
+		if visit.Locker != nil {
+			row.LockerNumber = &visit.Locker.LockerNumber
+			row.LockerSection = &visit.Locker.Section
+		}
+		rows = append(rows, row)
+	}
+	r.s.mu.RUnlock()
+
+	slices.SortStableFunc(rows, func(a, b model.ScheduleExportRow) int {
+		return cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.SevaType, b.SevaType), cmp.Compare(a.ProfileName, b.ProfileName))
+	})
+	return each(rows, fn)
+}
+
+func (r *exports) Residents(stayAreaID string, fn func(model.ResidentExportRow) error) error {
+	r.s.mu.RLock()
+	var rows []model.ResidentExportRow
+	for _, v := range r.s.visits {
+		if v.Status != model.StatusCheckedIn || (stayAreaID != "" && v.StayAreaID.String() != stayAreaID) {
+			continue
+		}
+		stayArea, ok := r.s.stayAreas[v.StayAreaID]
+		if !ok {
+			continue
+		}
+		profile, ok := r.s.profiles[v.ProfileID]
+		if !ok {
+			profile = r.s.deleted.profiles[v.ProfileID]
+		}
+		v = r.s.withPlace(v)
+		row := model.ResidentExportRow{
+			VisitID:       v.ID.String(),
+			ProfileID:     profile.ID.String(),
+			Name:          profile.Name,
+			Email:         profile.Email,
+			PhoneNumber:   profile.PhoneNumber,
+			Gender:        string(profile.Gender),
+			Category:      string(profile.Category),
+			StayAreaID:    stayArea.ID.String(),
+			StayArea:      stayArea.Name,
+			ArrivalDate:   v.ArrivalDate,
+			DepartureDate: v.DepartureDate,
+			Remarks:       v.Remarks,
+		}
+		if v.Locker != nil {
+			row.LockerNumber = &v.Locker.LockerNumber
+			row.LockerSection = &v.Locker.Section
+		}
+		rows = append(rows, row)
+	}
+	r.s.mu.RUnlock()
+
+	slices.SortStableFunc(rows, func(a, b model.ResidentExportRow) int {
+		return cmp.Or(cmp.Compare(a.StayArea, b.StayArea), cmp.Compare(a.Name, b.Name))
+	})
+	return each(rows, fn)
+}
+
+func (r *exports) Profiles(fn func(model.ProfileOverview) error) error {
+	rows, err := (&profiles{r.s}).GetProfilesData()
+	if err != nil {
+		return err
+	}
+	return each(rows, fn)
+}
+
+// the maps are always there
+type health struct{}
+
+func (health) Ping(ctx context.Context) error        { return nil }
+func (health) CheckSchema(ctx context.Context) error { return nil }
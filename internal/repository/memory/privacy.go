@@ -0,0 +1,193 @@
+package memory
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// the rows of a profile, live and deleted alike
+func rowsOf[T any](live, deleted map[uuid.UUID]T, match func(T) bool) []T {
+	var rows []T
+	for _, m := range []map[uuid.UUID]T{live, deleted} {
+		for _, row := range m {
+			if match(row) {
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows
+}
+
+// the profile with profileID, deleted or not. Callers hold the lock.
+func (s *Store) storedProfile(profileID string) (model.Profile, bool) {
+	id, _ := parseID(profileID)
+	profile, ok := s.profiles[id]
+	if !ok {
+		profile, ok = s.deleted.profiles[id]
+	}
+	return profile, ok
+}
+
+func (r *profiles) ExportPersonalData(profileID string, actorID *uuid.UUID) (*model.PersonalData, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	profile, ok := r.s.storedProfile(profileID)
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	id := profile.ID
+
+	data := model.PersonalData{ExportedAt: r.s.now().UTC(), Profile: profile, Medical: profile.Medical}
+	for _, v := range rowsOf(r.s.visits, r.s.deleted.visits, func(v model.Visit) bool { return v.ProfileID == id }) {
+		data.Visits = append(data.Visits, r.s.withPlace(v))
+	}
+	sort.Slice(data.Visits, func(i, j int) bool { return data.Visits[i].ArrivalDate.Before(data.Visits[j].ArrivalDate) })
+	for _, sch := range rowsOf(r.s.schedules, r.s.deleted.schedules, func(sch model.Schedule) bool { return sch.ProfileID == id }) {
+		sch.SevaType = r.s.sevaTypes[sch.SevaTypeID]
+		data.Schedules = append(data.Schedules, sch)
+	}
+	sort.Slice(data.Schedules, func(i, j int) bool { return data.Schedules[i].Date.Before(data.Schedules[j].Date) })
+	data.Feedbacks = rowsOf(r.s.feedbacks, r.s.deleted.feedbacks, func(f model.Feedback) bool { return f.ProfileID == id })
+	sort.Slice(data.Feedbacks, func(i, j int) bool { return data.Feedbacks[i].CreatedAt.Before(data.Feedbacks[j].CreatedAt) })
+	for _, msg := range sorted(r.s.outbox, func(a, b model.OutboxMessage) bool { return a.CreatedAt.Before(b.CreatedAt) }) {
+		if msg.ProfileID != nil && *msg.ProfileID == id {
+			data.Notifications = append(data.Notifications, msg)
+		}
+	}
+
+	r.s.addAuditRecord(model.AuditRecord{
+		Action:     model.AuditPersonalDataExported,
+		EntityType: "profile",
+		EntityID:   id,
+		ActorID:    actorID,
+		Details: map[string]any{
+			"visits":        len(data.Visits),
+			"schedules":     len(data.Schedules),
+			"feedbacks":     len(data.Feedbacks),
+			"notifications": len(data.Notifications),
+		},
+	})
+	return &data, nil
+}
+
+func (r *profiles) Erase(profileID string, actorID *uuid.UUID) (*model.Profile, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	profile, ok := r.s.storedProfile(profileID)
+	if !ok || profile.ErasedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+	return r.s.erase(profile, actorID, ""), nil
+}
+
+// anonymizes profile like dao.EraseProfile, reason is audited when the
+// erasure was not asked for by a person. Callers hold the write lock.
+func (s *Store) erase(profile model.Profile, actorID *uuid.UUID, reason string) *model.Profile {
+	id := profile.ID
+	now := s.now()
+	profile.Name = model.ErasedName
+	profile.Email = model.ErasedEmail(id)
+	profile.PhoneNumber = ""
+	profile.Remarks = nil
+	profile.EmergencyContacts = []model.EmergencyContact{}
+	profile.Medical = nil
+	profile.NotificationsOptOut = true
+	profile.ErasedAt = &now
+	profile.UpdatedAt = now
+	if _, deleted := s.deleted.profiles[id]; deleted {
+		s.deleted.profiles[id] = profile
+	} else {
+		s.profiles[id] = profile
+	}
+
+	details := map[string]any{}
+	count := 0
+	for _, m := range []map[uuid.UUID]model.Visit{s.visits, s.deleted.visits} {
+		for visitID, v := range m {
+			if v.ProfileID == id {
+				v.Remarks = nil
+				m[visitID] = v
+				count++
+			}
+		}
+	}
+	details["visits"] = count
+	count = 0
+	for _, m := range []map[uuid.UUID]model.Schedule{s.schedules, s.deleted.schedules} {
+		for scheduleID, sch := range m {
+			if sch.ProfileID == id {
+				sch.Notes = nil
+				m[scheduleID] = sch
+				count++
+			}
+		}
+	}
+	details["schedules"] = count
+	count = 0
+	for _, m := range []map[uuid.UUID]model.Feedback{s.feedbacks, s.deleted.feedbacks} {
+		for feedbackID, f := range m {
+			if f.ProfileID == id {
+				f.Content = model.ErasedFeedback
+				m[feedbackID] = f
+				count++
+			}
+		}
+	}
+	details["feedbacks"] = count
+	count = 0
+	for msgID, msg := range s.outbox {
+		if msg.ProfileID != nil && *msg.ProfileID == id {
+			delete(s.outbox, msgID)
+			count++
+		}
+	}
+	details["notifications"] = count
+	if reason != "" {
+		details["reason"] = reason
+	}
+
+	s.addAuditRecord(model.AuditRecord{
+		Action:     model.AuditProfileErased,
+		EntityType: "profile",
+		EntityID:   id,
+		ActorID:    actorID,
+		Details:    details,
+	})
+	return &profile
+}
+
+// callers hold the write lock
+func (s *Store) addAuditRecord(record model.AuditRecord) {
+	record.ID = uuid.New()
+	record.CreatedAt = s.now()
+	s.audit[record.ID] = record
+}
+
+type audit struct {
+	s *Store
+}
+
+func (r *audit) List(filter model.AuditFilter) ([]model.AuditRecord, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var result []model.AuditRecord
+	for _, record := range sorted(r.s.audit, func(a, b model.AuditRecord) bool { return a.CreatedAt.After(b.CreatedAt) }) {
+		if len(result) == filter.Limit {
+			break
+		}
+		if filter.Action != "" && record.Action != filter.Action {
+			continue
+		}
+		if filter.EntityID != nil && record.EntityID != *filter.EntityID {
+			continue
+		}
+		result = append(result, record)
+	}
+	return result, nil
+}
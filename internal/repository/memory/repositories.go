@@ -0,0 +1,820 @@
+package memory
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+	"counterapp/internal/util"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// returns the values of m ordered by less
+func sorted[T any](m map[uuid.UUID]T, less func(a, b T) bool) []T {
+	values := make([]T, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	sort.SliceStable(values, func(i, j int) bool { return less(values[i], values[j]) })
+	return values
+}
+
+type profiles struct {
+	s *Store
+}
+
+func (r *profiles) GetProfilesData() ([]model.ProfileOverview, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	visits := sorted(r.s.visits, func(a, b model.Visit) bool { return a.CreatedAt.Before(b.CreatedAt) })
+
+	var data []model.ProfileOverview
+	for _, p := range sorted(r.s.profiles, func(a, b model.Profile) bool { return a.Name < b.Name }) {
+		row := model.ProfileOverview{
+			ID:          p.ID.String(),
+			Name:        p.Name,
+			PhoneNumber: p.PhoneNumber,
+			Email:       p.Email,
+			Gender:      string(p.Gender),
+			Category:    string(p.Category),
+			IsBlocked:   p.IsBlocked,
+		}
+		if p.Remarks != nil {
+			row.Remarks = *p.Remarks
+		}
+
+		// one row per checked-in visit, or a single row without visit
+		// columns, like the LEFT JOIN LATERAL in dao.GetProfilesData
+		matched := false
+		for _, v := range visits {
+			if v.ProfileID != p.ID || v.Status != model.StatusCheckedIn {
+				continue
+			}
+			matched = true
+
+			visitRow := row
+			visitID := v.ID.String()
+			status := string(v.Status)
+			visitRow.ActiveVisitID = &visitID
+			visitRow.Status = &status
+			if v.DepartureDate != nil {
+				departure := util.FormatDate(*v.DepartureDate)
+				visitRow.DepartureDate = &departure
+			}
+			if sa, ok := r.s.stayAreas[v.StayAreaID]; ok {
+				name := sa.Name
+				visitRow.StayArea = &name
+			}
+			data = append(data, visitRow)
+		}
+		if !matched {
+			data = append(data, row)
+		}
+	}
+	return data, nil
+}
+
+func (r *profiles) GetByID(profileID string) (*model.Profile, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	id, _ := parseID(profileID)
+	profile, ok := r.s.profiles[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &profile, nil
+}
+
+func (r *profiles) GetByIDWithDeleted(profileID string) (*model.Profile, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	profile, ok := r.s.storedProfile(profileID)
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &profile, nil
+}
+
+func (r *profiles) GetByEmail(email string) (*model.Profile, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, p := range r.s.profiles {
+		if strings.EqualFold(p.Email, email) {
+			return &p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *profiles) Create(profile *model.Profile) (*model.Profile, error) {
+	if profile == nil {
+		return nil, errors.New("cannot create an empty profile")
+	}
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, p := range r.s.profiles {
+		if p.Email == profile.Email {
+			return nil, repository.ErrDuplicate
+		}
+	}
+
+	if profile.ID == uuid.Nil {
+		profile.ID = uuid.New()
+	}
+	if _, ok := r.s.profiles[profile.ID]; ok {
+		return nil, repository.ErrDuplicate
+	}
+	if profile.NotificationChannel == "" {
+		profile.NotificationChannel = model.ChannelEmail
+	}
+	if profile.EmergencyContacts == nil {
+		profile.EmergencyContacts = []model.EmergencyContact{}
+	}
+	now := r.s.now()
+	profile.CreatedAt = now
+	profile.UpdatedAt = now
+	r.s.profiles[profile.ID] = *profile
+	return profile, nil
+}
+
+func (r *profiles) Update(profileID string, updates *model.ProfileUpdate) (*model.Profile, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	id, _ := parseID(profileID)
+	profile, ok := r.s.profiles[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	if updates.Name != nil {
+		profile.Name = *updates.Name
+	}
+	if updates.PhoneNumber != nil {
+		profile.PhoneNumber = *updates.PhoneNumber
+	}
+	if updates.Gender != nil {
+		profile.Gender = *updates.Gender
+	}
+	if updates.Category != nil {
+		profile.Category = *updates.Category
+	}
+	if updates.IsBlocked != nil {
+		profile.IsBlocked = *updates.IsBlocked
+	}
+	if updates.Remarks != nil {
+		remarks := *updates.Remarks
+		profile.Remarks = &remarks
+	}
+	if updates.NotificationChannel != nil {
+		profile.NotificationChannel = *updates.NotificationChannel
+	}
+	if updates.NotificationsOptOut != nil {
+		profile.NotificationsOptOut = *updates.NotificationsOptOut
+	}
+	if updates.EmergencyContacts != nil {
+		profile.EmergencyContacts = slices.Clone(*updates.EmergencyContacts)
+	}
+	profile.UpdatedAt = r.s.now()
+
+	r.s.profiles[id] = profile
+	return &profile, nil
+}
+
+func (r *profiles) SetMedical(profileID string, medical *model.MedicalInfo) (*model.Profile, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	id, _ := parseID(profileID)
+	profile, ok := r.s.profiles[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if medical != nil {
+		stored := *medical
+		medical = &stored
+	}
+	profile.Medical = medical
+	profile.UpdatedAt = r.s.now()
+
+	r.s.profiles[id] = profile
+	return &profile, nil
+}
+
+type visits struct {
+	s *Store
+}
+
+func (r *visits) GetByProfileID(profileID string) ([]model.Visit, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	id, _ := parseID(profileID)
+	var result []model.Visit
+	for _, v := range sorted(r.s.visits, func(a, b model.Visit) bool { return a.CreatedAt.Before(b.CreatedAt) }) {
+		if v.ProfileID == id {
+			result = append(result, r.s.withPlace(v))
+		}
+	}
+	return result, nil
+}
+
+func (r *visits) GetByID(visitID string) (*model.Visit, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	id, _ := parseID(visitID)
+	visit, ok := r.s.visits[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	visit = r.s.withPlace(visit)
+	return &visit, nil
+}
+
+func (r *visits) GetDetailsByID(visitID string) (*model.Visit, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	id, _ := parseID(visitID)
+	visit, ok := r.s.visits[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	visit = r.s.withPlace(visit)
+	visit.Profile = r.s.profiles[visit.ProfileID]
+	return &visit, nil
+}
+
+func (r *visits) GetByProfileAndArrivalDate(profileID string, arrivalDate time.Time) (*model.Visit, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	id, _ := parseID(profileID)
+	for _, v := range r.s.visits {
+		if v.ProfileID == id && v.ArrivalDate.Equal(arrivalDate) {
+			return &v, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *visits) GetOverdue(today time.Time) ([]model.Visit, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var result []model.Visit
+	for _, v := range r.s.visits {
+		if v.Status == model.StatusCheckedIn && v.DepartureDate != nil && v.DepartureDate.Before(today) {
+			result = append(result, v)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].DepartureDate.Before(*result[j].DepartureDate) })
+	return result, nil
+}
+
+func (r *visits) Add(req model.AddVisitRequest) (*model.Visit, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.profiles[req.ProfileID]; !ok {
+		return nil, errors.New("visit references an unknown profile")
+	}
+	if _, ok := r.s.stayAreas[req.StayAreaID]; !ok {
+		return nil, errors.New("visit references an unknown stay area")
+	}
+
+	status := req.Status
+	if status == "" {
+		status = model.StatusPending
+	}
+	visit := model.Visit{
+		ID:            uuid.New(),
+		ProfileID:     req.ProfileID,
+		ArrivalDate:   req.ArrivalDate,
+		DepartureDate: req.DepartureDate,
+		StayAreaID:    req.StayAreaID,
+		Status:        status,
+		LockerID:      req.LockerID,
+		Remarks:       req.Remarks,
+		CreatedAt:     r.s.now(),
+	}
+	if status == model.StatusCheckedIn {
+		checkedInAt := visit.CreatedAt
+		visit.CheckedInAt = &checkedInAt
+	}
+	r.s.visits[visit.ID] = visit
+	return &visit, nil
+}
+
+func (r *visits) Update(visitID string, req model.UpdateVisitRequest) (*model.Visit, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	id, _ := parseID(visitID)
+	visit, ok := r.s.visits[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	if req.DepartureDate != nil {
+		departure := *req.DepartureDate
+		visit.DepartureDate = &departure
+	}
+	if req.StayAreaID != nil {
+		if _, ok := r.s.stayAreas[*req.StayAreaID]; !ok {
+			return nil, errors.New("visit references an unknown stay area")
+		}
+		visit.StayAreaID = *req.StayAreaID
+	}
+	if req.Status != nil && *req.Status != visit.Status {
+		visit.Status = *req.Status
+		visit.CheckedOutAt = nil
+		if visit.Status == model.StatusCheckedOut {
+			now := time.Now()
+			visit.CheckedOutAt = &now
+		}
+		if visit.Status == model.StatusCheckedIn && visit.CheckedInAt == nil {
+			now := time.Now()
+			visit.CheckedInAt = &now
+		}
+	}
+	if req.LockerID != nil {
+		if _, ok := r.s.lockers[*req.LockerID]; !ok {
+			return nil, errors.New("visit references an unknown locker")
+		}
+		lockerID := *req.LockerID
+		visit.LockerID = &lockerID
+	}
+	if req.Remarks != nil {
+		remarks := *req.Remarks
+		visit.Remarks = &remarks
+	}
+
+	r.s.visits[id] = visit
+	return &visit, nil
+}
+
+type schedules struct {
+	s *Store
+}
+
+func (r *schedules) GetForDateRange(startDate time.Time, endDate time.Time) ([]model.Schedule, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var result []model.Schedule
+	for _, sch := range sorted(r.s.schedules, func(a, b model.Schedule) bool { return a.CreatedAt.Before(b.CreatedAt) }) {
+		if sch.Date.Before(startDate) || sch.Date.After(endDate) {
+			continue
+		}
+		sch.Profile = r.s.profiles[sch.ProfileID]
+		sch.SevaType = r.s.sevaTypes[sch.SevaTypeID]
+		sch.Visit = r.s.withPlace(r.s.visits[sch.VisitID])
+		result = append(result, sch)
+	}
+	return result, nil
+}
+
+func (r *schedules) GetByProfileAndDate(profileID string, date time.Time) (*model.Schedule, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	id, _ := parseID(profileID)
+	for _, sch := range r.s.schedules {
+		if sch.ProfileID == id && sch.Date.Equal(date) {
+			return &sch, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *schedules) Add(req model.AddScheduleRequest) (*model.Schedule, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.profiles[req.ProfileID]; !ok {
+		return nil, errors.New("schedule references an unknown profile")
+	}
+	if _, ok := r.s.visits[req.VisitID]; !ok {
+		return nil, errors.New("schedule references an unknown visit")
+	}
+	if _, ok := r.s.sevaTypes[req.SevaTypeID]; !ok {
+		return nil, errors.New("schedule references an unknown seva type")
+	}
+
+	schedule := model.Schedule{
+		ID:         uuid.New(),
+		ProfileID:  req.ProfileID,
+		VisitID:    req.VisitID,
+		SevaTypeID: req.SevaTypeID,
+		Location:   req.Location,
+		Date:       req.Date,
+		CreatedAt:  r.s.now(),
+	}
+	r.s.schedules[schedule.ID] = schedule
+	return &schedule, nil
+}
+
+type feedbacks struct {
+	s *Store
+}
+
+func (r *feedbacks) GetAll() ([]model.Feedback, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var result []model.Feedback
+	for _, f := range sorted(r.s.feedbacks, func(a, b model.Feedback) bool { return a.CreatedAt.Before(b.CreatedAt) }) {
+		f.Profile = r.s.profiles[f.ProfileID]
+		result = append(result, f)
+	}
+	return result, nil
+}
+
+func (r *feedbacks) GetForProfile(profileID string) ([]model.Feedback, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	id, _ := parseID(profileID)
+	var result []model.Feedback
+	for _, f := range sorted(r.s.feedbacks, func(a, b model.Feedback) bool { return a.CreatedAt.Before(b.CreatedAt) }) {
+		if f.ProfileID == id {
+			result = append(result, f)
+		}
+	}
+	return result, nil
+}
+
+func (r *feedbacks) Add(req model.AddFeedbackRequest) (*model.Feedback, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.profiles[req.ProfileID]; !ok {
+		return nil, errors.New("feedback references an unknown profile")
+	}
+	if req.VisitID != nil {
+		if _, ok := r.s.visits[*req.VisitID]; !ok {
+			return nil, errors.New("feedback references an unknown visit")
+		}
+	}
+
+	feedback := model.Feedback{
+		ID:        uuid.New(),
+		ProfileID: req.ProfileID,
+		VisitID:   req.VisitID,
+		Content:   req.Content,
+		Type:      req.Type,
+		CreatedBy: req.CreatedBy,
+		CreatedAt: r.s.now(),
+	}
+	r.s.feedbacks[feedback.ID] = feedback
+	return &feedback, nil
+}
+
+type sevaTypes struct {
+	s *Store
+}
+
+func (r *sevaTypes) GetAll() ([]model.SevaType, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var result []model.SevaType
+	for _, st := range sorted(r.s.sevaTypes, func(a, b model.SevaType) bool { return a.Name < b.Name }) {
+		if st.IsActive {
+			result = append(result, st)
+		}
+	}
+	return result, nil
+}
+
+func (r *sevaTypes) GetByID(id string) (*model.SevaType, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	parsed, _ := parseID(id)
+	sevaType, ok := r.s.sevaTypes[parsed]
+	if !ok || !sevaType.IsActive {
+		return nil, repository.ErrNotFound
+	}
+	return &sevaType, nil
+}
+
+func (r *sevaTypes) GetByName(name string) (*model.SevaType, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, st := range r.s.sevaTypes {
+		if st.Name == name && st.IsActive {
+			return &st, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *sevaTypes) Add(req model.AddSevaTypeRequest) (*model.SevaType, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, st := range r.s.sevaTypes {
+		if st.Name == req.Name {
+			return nil, repository.ErrDuplicate
+		}
+	}
+
+	sevaType := model.SevaType{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		Description: req.Description,
+		IsActive:    true,
+		CreatedAt:   r.s.now(),
+	}
+	r.s.sevaTypes[sevaType.ID] = sevaType
+	return &sevaType, nil
+}
+
+type stayAreas struct {
+	s *Store
+}
+
+func byStayAreaName(a, b model.StayArea) bool { return a.Name < b.Name }
+
+func (r *stayAreas) GetAll() ([]model.StayArea, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return sorted(r.s.stayAreas, byStayAreaName), nil
+}
+
+func (r *stayAreas) GetByID(id string) (*model.StayArea, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	parsed, _ := parseID(id)
+	stayArea, ok := r.s.stayAreas[parsed]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &stayArea, nil
+}
+
+func (r *stayAreas) GetByName(name string) (*model.StayArea, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, sa := range r.s.stayAreas {
+		if sa.Name == name {
+			return &sa, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *stayAreas) Add(req model.AddStayAreaRequest) (*model.StayArea, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stayArea := model.StayArea{
+		ID:       uuid.New(),
+		Name:     req.Name,
+		Capacity: req.Capacity,
+	}
+	r.s.stayAreas[stayArea.ID] = stayArea
+	return &stayArea, nil
+}
+
+func (r *stayAreas) GetAllWithOccupancy() ([]model.StayAreaOccupancy, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	occupied := map[uuid.UUID]int{}
+	for _, v := range r.s.visits {
+		if v.Status == model.StatusCheckedIn {
+			occupied[v.StayAreaID]++
+		}
+	}
+
+	result := make([]model.StayAreaOccupancy, 0, len(r.s.stayAreas))
+	for _, sa := range sorted(r.s.stayAreas, byStayAreaName) {
+		result = append(result, model.StayAreaOccupancy{
+			StayAreaID:           sa.ID.String(),
+			StayName:             sa.Name,
+			StayCapacity:         sa.Capacity,
+			CurrentOccupiedCount: occupied[sa.ID],
+		})
+	}
+	return result, nil
+}
+
+type lockers struct {
+	s *Store
+}
+
+func (r *lockers) GetAll() ([]model.Locker, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return sorted(r.s.lockers, func(a, b model.Locker) bool {
+		if a.Section != b.Section {
+			return a.Section < b.Section
+		}
+		return a.LockerNumber < b.LockerNumber
+	}), nil
+}
+
+type notifications struct {
+	s *Store
+}
+
+func (r *notifications) Enqueue(msg *model.OutboxMessage) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if msg.DedupKey != nil {
+		for _, queued := range r.s.outbox {
+			if queued.DedupKey != nil && *queued.DedupKey == *msg.DedupKey {
+				return nil
+			}
+		}
+	}
+
+	if msg.ID == uuid.Nil {
+		msg.ID = uuid.New()
+	}
+	now := r.s.now()
+	if msg.Status == "" {
+		msg.Status = model.DeliveryPending
+	}
+	if msg.NextAttemptAt.IsZero() {
+		msg.NextAttemptAt = now
+	}
+	msg.CreatedAt = now
+	msg.UpdatedAt = now
+	r.s.outbox[msg.ID] = *msg
+	return nil
+}
+
+func (r *notifications) List(status string, limit int) ([]model.OutboxMessage, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var result []model.OutboxMessage
+	for _, msg := range sorted(r.s.outbox, func(a, b model.OutboxMessage) bool { return a.CreatedAt.After(b.CreatedAt) }) {
+		if len(result) == limit {
+			break
+		}
+		if status == "" || string(msg.Status) == status {
+			result = append(result, msg)
+		}
+	}
+	return result, nil
+}
+
+func (r *notifications) Retry(id string) (*model.OutboxMessage, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	parsed, _ := parseID(id)
+	msg, ok := r.s.outbox[parsed]
+	if !ok || msg.Status != model.DeliveryFailed {
+		return nil, repository.ErrNotFound
+	}
+
+	now := r.s.now()
+	msg.Status = model.DeliveryPending
+	msg.Attempts = 0
+	msg.NextAttemptAt = now
+	msg.UpdatedAt = now
+	r.s.outbox[parsed] = msg
+	return &msg, nil
+}
+
+type webhooks struct {
+	s *Store
+}
+
+func (r *webhooks) GetAllSubscriptions() ([]model.WebhookSubscription, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return sorted(r.s.subscriptions, func(a, b model.WebhookSubscription) bool { return a.CreatedAt.Before(b.CreatedAt) }), nil
+}
+
+func (r *webhooks) AddSubscription(req model.AddWebhookSubscriptionRequest) (*model.WebhookSubscription, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	sub := model.WebhookSubscription{
+		ID:         uuid.New(),
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: slices.Clone(req.EventTypes),
+		IsActive:   true,
+		CreatedAt:  r.s.now(),
+	}
+	r.s.subscriptions[sub.ID] = sub
+	return &sub, nil
+}
+
+func (r *webhooks) DeactivateSubscription(id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	parsed, _ := parseID(id)
+	sub, ok := r.s.subscriptions[parsed]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	sub.IsActive = false
+	r.s.subscriptions[parsed] = sub
+	return nil
+}
+
+func (r *webhooks) GetDeliveries(subscriptionID string, limit int) ([]model.WebhookDelivery, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	id, _ := parseID(subscriptionID)
+	var result []model.WebhookDelivery
+	for _, d := range sorted(r.s.webhookDeliveries, func(a, b model.WebhookDelivery) bool { return a.CreatedAt.After(b.CreatedAt) }) {
+		if len(result) == limit {
+			break
+		}
+		if d.SubscriptionID == id {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (r *webhooks) Redeliver(deliveryID string) (*model.WebhookDelivery, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	id, _ := parseID(deliveryID)
+	original, ok := r.s.webhookDeliveries[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	now := r.s.now()
+	redelivery := model.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         model.DeliveryPending,
+		NextAttemptAt:  now,
+		RedeliveryOf:   &original.ID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	r.s.webhookDeliveries[redelivery.ID] = redelivery
+	return &redelivery, nil
+}
+
+type users struct {
+	s *Store
+}
+
+func (r *users) Create(user *model.User) (*model.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, existing := range r.s.users {
+		if existing.Email == user.Email || existing.TokenHash == user.TokenHash {
+			return nil, repository.ErrDuplicate
+		}
+	}
+	created := *user
+	if created.ID == uuid.Nil {
+		created.ID = uuid.New()
+	}
+	created.CreatedAt = r.s.now()
+	r.s.users[created.ID] = created
+	return &created, nil
+}
+
+func (r *users) GetByTokenHash(tokenHash string) (*model.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	for _, user := range r.s.users {
+		if user.TokenHash == tokenHash {
+			return &user, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
@@ -0,0 +1,245 @@
+package memory
+
+import (
+	"bytes"
+	"counterapp/internal/model"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// the rules of dao.ApplyRetention on the maps
+type retentionRule struct {
+	name   string
+	entity string
+	action model.AuditAction
+	cutoff time.Time
+	// the IDs of the rows that are due, callers hold the lock
+	due func() []uuid.UUID
+	// changes the row, callers hold the write lock
+	apply func(r retentionRule, id uuid.UUID)
+}
+
+// the IDs of the rows in live and deleted that match, in the order the
+// database lists them
+func dueIDs[T any](live, deleted map[uuid.UUID]T, match func(T) bool) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, m := range []map[uuid.UUID]T{live, deleted} {
+		for id, row := range m {
+			if match(row) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+	return ids
+}
+
+// changes the row with id, whether it is deleted or not
+func change[T any](live, deleted map[uuid.UUID]T, id uuid.UUID, set func(*T)) {
+	for _, m := range []map[uuid.UUID]T{live, deleted} {
+		if row, ok := m[id]; ok {
+			set(&row)
+			m[id] = row
+		}
+	}
+}
+
+func (s *Store) retentionRules(policy model.RetentionPolicy, now time.Time) []retentionRule {
+	var rs []retentionRule
+
+	if days := policy.PurgeDeletedDays; days > 0 {
+		cutoff := now.AddDate(0, 0, -days)
+		rs = append(rs, retentionRule{
+			name:   "purge_deleted_profiles",
+			entity: "profile",
+			action: model.AuditRecordPurged,
+			cutoff: cutoff,
+			due: func() []uuid.UUID {
+				return dueIDs(nil, s.deleted.profiles, func(p model.Profile) bool { return p.DeletedAt.Time.Before(cutoff) })
+			},
+			apply: func(r retentionRule, id uuid.UUID) {
+				s.purgeProfile(id)
+				s.addAuditRecord(r.audit(id, map[string]any{}))
+			},
+		}, retentionRule{
+			name:   "purge_deleted_visits",
+			entity: "visit",
+			action: model.AuditRecordPurged,
+			cutoff: cutoff,
+			// visits of deleted profiles go with their profile
+			due: func() []uuid.UUID {
+				return dueIDs(nil, s.deleted.visits, func(v model.Visit) bool {
+					_, profileDeleted := s.deleted.profiles[v.ProfileID]
+					return v.DeletedAt.Time.Before(cutoff) && !profileDeleted
+				})
+			},
+			apply: func(r retentionRule, id uuid.UUID) {
+				s.purgeVisit(id)
+				s.addAuditRecord(r.audit(id, map[string]any{}))
+			},
+		})
+	}
+
+	if years := policy.InactiveProfileYears; years > 0 {
+		cutoff := now.AddDate(-years, 0, 0)
+		rs = append(rs, retentionRule{
+			name:   "inactive_profiles",
+			entity: "profile",
+			action: model.AuditProfileErased,
+			cutoff: cutoff,
+			due: func() []uuid.UUID {
+				return dueIDs(s.profiles, nil, func(p model.Profile) bool { return p.ErasedAt == nil && s.inactive(p, cutoff) })
+			},
+			apply: func(r retentionRule, id uuid.UUID) {
+				s.erase(s.profiles[id], nil, r.name)
+			},
+		})
+	}
+
+	if months := policy.RemarksMonths; months > 0 {
+		cutoff := now.AddDate(0, -months, 0)
+		// deleted rows are cleared too, they are kept until purged
+		ofInactive := func(profileID uuid.UUID) bool {
+			p, ok := s.profiles[profileID]
+			if !ok {
+				p, ok = s.deleted.profiles[profileID]
+			}
+			return ok && s.inactive(p, cutoff)
+		}
+		rs = append(rs, retentionRule{
+			name:   "profile_remarks",
+			entity: "profile",
+			action: model.AuditRemarksCleared,
+			cutoff: cutoff,
+			due: func() []uuid.UUID {
+				return dueIDs(s.profiles, s.deleted.profiles, func(p model.Profile) bool { return p.Remarks != nil && ofInactive(p.ID) })
+			},
+			apply: func(r retentionRule, id uuid.UUID) {
+				change(s.profiles, s.deleted.profiles, id, func(p *model.Profile) { p.Remarks = nil })
+				s.addAuditRecord(r.audit(id, map[string]any{"field": "remarks"}))
+			},
+		}, retentionRule{
+			name:   "visit_remarks",
+			entity: "visit",
+			action: model.AuditRemarksCleared,
+			cutoff: cutoff,
+			due: func() []uuid.UUID {
+				return dueIDs(s.visits, s.deleted.visits, func(v model.Visit) bool { return v.Remarks != nil && ofInactive(v.ProfileID) })
+			},
+			apply: func(r retentionRule, id uuid.UUID) {
+				change(s.visits, s.deleted.visits, id, func(v *model.Visit) { v.Remarks = nil })
+				s.addAuditRecord(r.audit(id, map[string]any{"field": "remarks"}))
+			},
+		}, retentionRule{
+			name:   "schedule_notes",
+			entity: "schedule",
+			action: model.AuditRemarksCleared,
+			cutoff: cutoff,
+			due: func() []uuid.UUID {
+				return dueIDs(s.schedules, s.deleted.schedules, func(sch model.Schedule) bool { return sch.Notes != nil && ofInactive(sch.ProfileID) })
+			},
+			apply: func(r retentionRule, id uuid.UUID) {
+				change(s.schedules, s.deleted.schedules, id, func(sch *model.Schedule) { sch.Notes = nil })
+				s.addAuditRecord(r.audit(id, map[string]any{"field": "notes"}))
+			},
+		})
+	}
+
+	if months := policy.FeedbackMonths; months > 0 {
+		cutoff := now.AddDate(0, -months, 0)
+		rs = append(rs, retentionRule{
+			name:   "feedback",
+			entity: "feedback",
+			action: model.AuditFeedbackCleared,
+			cutoff: cutoff,
+			due: func() []uuid.UUID {
+				return dueIDs(s.feedbacks, s.deleted.feedbacks, func(f model.Feedback) bool {
+					return f.CreatedAt.Before(cutoff) && f.Content != model.ErasedFeedback
+				})
+			},
+			apply: func(r retentionRule, id uuid.UUID) {
+				change(s.feedbacks, s.deleted.feedbacks, id, func(f *model.Feedback) { f.Content = model.ErasedFeedback })
+				s.addAuditRecord(r.audit(id, map[string]any{"field": "content"}))
+			},
+		})
+	}
+
+	return rs
+}
+
+// whether profile was created before cutoff and has not been checked in or
+// on a visit since, counting deleted visits. Callers hold the lock.
+func (s *Store) inactive(profile model.Profile, cutoff time.Time) bool {
+	if !profile.CreatedAt.Before(cutoff) {
+		return false
+	}
+	for _, m := range []map[uuid.UUID]model.Visit{s.visits, s.deleted.visits} {
+		for _, v := range m {
+			if v.ProfileID != profile.ID {
+				continue
+			}
+			last := v.ArrivalDate
+			if v.CheckedOutAt != nil {
+				last = *v.CheckedOutAt
+			} else if v.DepartureDate != nil {
+				last = *v.DepartureDate
+			}
+			if v.Status == model.StatusCheckedIn || !last.Before(cutoff) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r retentionRule) report(count int, ids []uuid.UUID) model.RetentionRuleReport {
+	if ids == nil {
+		ids = []uuid.UUID{}
+	}
+	return model.RetentionRuleReport{
+		Rule:   r.name,
+		Entity: r.entity,
+		Action: r.action,
+		Cutoff: r.cutoff,
+		Count:  count,
+		IDs:    ids[:min(model.RetentionReportIDs, len(ids))],
+	}
+}
+
+func (r retentionRule) audit(id uuid.UUID, details map[string]any) model.AuditRecord {
+	details["rule"] = r.name
+	return model.AuditRecord{Action: r.action, EntityType: r.entity, EntityID: id, Details: details}
+}
+
+type retention struct {
+	s *Store
+}
+
+func (r *retention) Plan(policy model.RetentionPolicy, now time.Time) (*model.RetentionReport, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	report := model.RetentionReport{DryRun: true, RanAt: now, Rules: []model.RetentionRuleReport{}}
+	for _, rule := range r.s.retentionRules(policy, now) {
+		due := rule.due()
+		report.Rules = append(report.Rules, rule.report(len(due), due))
+	}
+	return &report, nil
+}
+
+func (r *retention) Apply(policy model.RetentionPolicy, now time.Time) (*model.RetentionReport, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	report := model.RetentionReport{RanAt: now, Rules: []model.RetentionRuleReport{}}
+	for _, rule := range r.s.retentionRules(policy, now) {
+		due := rule.due()
+		for _, id := range due {
+			rule.apply(rule, id)
+		}
+		report.Rules = append(report.Rules, rule.report(len(due), due))
+	}
+	return &report, nil
+}
@@ -0,0 +1,184 @@
+package memory
+
+import (
+	"counterapp/internal/dao"
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// roll calls are stored with bare entries, what an entry shows is loaded
+// when it is read like the preloads of dao.GetRollCall
+type rollCalls struct {
+	s *Store
+}
+
+// the live row with the ID or else the deleted one
+func liveOrDeleted[T any](live, deleted map[uuid.UUID]T, id uuid.UUID) (T, bool) {
+	if row, ok := live[id]; ok {
+		return row, true
+	}
+	row, ok := deleted[id]
+	return row, ok
+}
+
+func (s *Store) withEntryDetails(e model.RollCallEntry) model.RollCallEntry {
+	visit, _ := liveOrDeleted(s.visits, s.deleted.visits, e.VisitID)
+	e.Visit = s.withPlace(visit)
+	e.Visit.Profile, _ = liveOrDeleted(s.profiles, s.deleted.profiles, visit.ProfileID)
+	e.StayArea = s.stayAreas[e.StayAreaID]
+	e.Schedule = nil
+	if e.ScheduleID != nil {
+		if schedule, ok := liveOrDeleted(s.schedules, s.deleted.schedules, *e.ScheduleID); ok {
+			schedule.SevaType = s.sevaTypes[schedule.SevaTypeID]
+			e.Schedule = &schedule
+		}
+	}
+	return e
+}
+
+func (s *Store) withEntries(rc model.RollCall) model.RollCall {
+	entries := make([]model.RollCallEntry, len(rc.Entries))
+	for i, e := range rc.Entries {
+		entries[i] = s.withEntryDetails(e)
+	}
+	dao.SortRollCallEntries(entries)
+	rc.Entries = entries
+	return rc
+}
+
+func (s *Store) onSite(today time.Time) []model.RollCallEntry {
+	entries := []model.RollCallEntry{}
+	for _, v := range s.visits {
+		if v.Status != model.StatusCheckedIn {
+			continue
+		}
+		entry := model.RollCallEntry{VisitID: v.ID, StayAreaID: v.StayAreaID}
+		for _, sch := range s.schedules {
+			if sch.VisitID == v.ID && sch.Date.Equal(today) {
+				entry.ScheduleID = &sch.ID
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (r *rollCalls) OnSite(today time.Time) ([]model.RollCallEntry, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return r.s.withEntries(model.RollCall{Entries: r.s.onSite(today)}).Entries, nil
+}
+
+func (r *rollCalls) Start(note *string, startedBy *uuid.UUID, today time.Time) (*model.RollCall, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	rollCall := model.RollCall{ID: uuid.New(), Note: note, StartedBy: startedBy, StartedAt: r.s.now()}
+	rollCall.Entries = r.s.onSite(today)
+	for i := range rollCall.Entries {
+		rollCall.Entries[i].ID = uuid.New()
+		rollCall.Entries[i].RollCallID = rollCall.ID
+	}
+	r.s.rollCalls[rollCall.ID] = rollCall
+
+	started := r.s.withEntries(rollCall)
+	return &started, nil
+}
+
+func (r *rollCalls) List(limit int) ([]model.RollCallSummary, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	summaries := []model.RollCallSummary{}
+	for _, rc := range sorted(r.s.rollCalls, func(a, b model.RollCall) bool { return a.StartedAt.After(b.StartedAt) }) {
+		if len(summaries) == limit {
+			break
+		}
+		summary := model.RollCallSummary{RollCall: rc, Total: len(rc.Entries)}
+		for _, e := range rc.Entries {
+			if e.AccountedAt != nil {
+				summary.Accounted++
+			}
+		}
+		summary.Entries = nil
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+func (r *rollCalls) GetByID(id string) (*model.RollCall, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	rollCallID, _ := parseID(id)
+	rollCall, ok := r.s.rollCalls[rollCallID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	rollCall = r.s.withEntries(rollCall)
+	return &rollCall, nil
+}
+
+func (r *rollCalls) MarkEntry(rollCallID string, entryID string, accounted bool, by *uuid.UUID) (*model.RollCallEntry, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	rcID, _ := parseID(rollCallID)
+	id, _ := parseID(entryID)
+	rollCall, ok := r.s.rollCalls[rcID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	i := slices.IndexFunc(rollCall.Entries, func(e model.RollCallEntry) bool { return e.ID == id })
+	if i < 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	entries := slices.Clone(rollCall.Entries)
+	entry := &entries[i]
+	if accounted && entry.AccountedAt == nil {
+		now := r.s.now()
+		entry.AccountedAt = &now
+		entry.AccountedBy = by
+	} else if !accounted {
+		entry.AccountedAt = nil
+		entry.AccountedBy = nil
+	}
+	rollCall.Entries = entries
+	r.s.rollCalls[rcID] = rollCall
+
+	marked := r.s.withEntryDetails(*entry)
+	return &marked, nil
+}
+
+func (r *rollCalls) Close(id string) (*model.RollCall, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	rollCallID, _ := parseID(id)
+	rollCall, ok := r.s.rollCalls[rollCallID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if rollCall.ClosedAt == nil {
+		now := r.s.now()
+		rollCall.ClosedAt = &now
+		r.s.rollCalls[rollCallID] = rollCall
+	}
+
+	closed := r.s.withEntries(rollCall)
+	return &closed, nil
+}
+
+// removes the entries of purged visits, like ON DELETE CASCADE
+func (s *Store) dropRollCallEntries(purged func(visitID uuid.UUID) bool) {
+	for id, rc := range s.rollCalls {
+		rc.Entries = slices.DeleteFunc(slices.Clone(rc.Entries), func(e model.RollCallEntry) bool { return purged(e.VisitID) })
+		s.rollCalls[id] = rc
+	}
+}
@@ -0,0 +1,178 @@
+// Package memory implements the repositories on plain maps so handlers and
+// business rules can be exercised without PostgreSQL. It keeps the same
+// semantics as the GORM implementation where callers can observe them
+// (not found and duplicate errors, preloaded associations, filters) but does
+// not record webhook deliveries or publish events.
+package memory
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Store holds every record. All repositories returned by Repositories share
+// it, so a visit added through Visits shows up in the profiles listing.
+type Store struct {
+	mu sync.RWMutex
+	tables
+
+	// held for the whole of a transaction, see transactions.Run
+	txMu sync.Mutex
+
+	now func() time.Time
+}
+
+// rows are replaced rather than changed in place, so copying the maps is
+// enough to keep a snapshot
+type tables struct {
+	profiles          map[uuid.UUID]model.Profile
+	visits            map[uuid.UUID]model.Visit
+	schedules         map[uuid.UUID]model.Schedule
+	feedbacks         map[uuid.UUID]model.Feedback
+	sevaTypes         map[uuid.UUID]model.SevaType
+	stayAreas         map[uuid.UUID]model.StayArea
+	lockers           map[uuid.UUID]model.Locker
+	outbox            map[uuid.UUID]model.OutboxMessage
+	subscriptions     map[uuid.UUID]model.WebhookSubscription
+	webhookDeliveries map[uuid.UUID]model.WebhookDelivery
+	users             map[uuid.UUID]model.User
+	audit             map[uuid.UUID]model.AuditRecord
+	rollCalls         map[uuid.UUID]model.RollCall
+
+	// soft deleted rows are moved here, out of sight of every read
+	deleted trash
+}
+
+func NewStore() *Store {
+	return &Store{
+		tables: tables{
+			profiles:          map[uuid.UUID]model.Profile{},
+			visits:            map[uuid.UUID]model.Visit{},
+			schedules:         map[uuid.UUID]model.Schedule{},
+			feedbacks:         map[uuid.UUID]model.Feedback{},
+			sevaTypes:         map[uuid.UUID]model.SevaType{},
+			stayAreas:         map[uuid.UUID]model.StayArea{},
+			lockers:           map[uuid.UUID]model.Locker{},
+			outbox:            map[uuid.UUID]model.OutboxMessage{},
+			subscriptions:     map[uuid.UUID]model.WebhookSubscription{},
+			webhookDeliveries: map[uuid.UUID]model.WebhookDelivery{},
+			users:             map[uuid.UUID]model.User{},
+			audit:             map[uuid.UUID]model.AuditRecord{},
+			rollCalls:         map[uuid.UUID]model.RollCall{},
+			deleted: trash{
+				profiles:  map[uuid.UUID]model.Profile{},
+				visits:    map[uuid.UUID]model.Visit{},
+				schedules: map[uuid.UUID]model.Schedule{},
+				feedbacks: map[uuid.UUID]model.Feedback{},
+			},
+		},
+		now: time.Now,
+	}
+}
+
+func (t tables) clone() tables {
+	return tables{
+		profiles:          maps.Clone(t.profiles),
+		visits:            maps.Clone(t.visits),
+		schedules:         maps.Clone(t.schedules),
+		feedbacks:         maps.Clone(t.feedbacks),
+		sevaTypes:         maps.Clone(t.sevaTypes),
+		stayAreas:         maps.Clone(t.stayAreas),
+		lockers:           maps.Clone(t.lockers),
+		outbox:            maps.Clone(t.outbox),
+		subscriptions:     maps.Clone(t.subscriptions),
+		webhookDeliveries: maps.Clone(t.webhookDeliveries),
+		users:             maps.Clone(t.users),
+		audit:             maps.Clone(t.audit),
+		rollCalls:         maps.Clone(t.rollCalls),
+		deleted: trash{
+			profiles:  maps.Clone(t.deleted.profiles),
+			visits:    maps.Clone(t.deleted.visits),
+			schedules: maps.Clone(t.deleted.schedules),
+			feedbacks: maps.Clone(t.deleted.feedbacks),
+		},
+	}
+}
+
+// NewRepositories returns repositories over a fresh, empty store
+func NewRepositories() *repository.Repositories {
+	return NewStore().Repositories()
+}
+
+func (s *Store) Repositories() *repository.Repositories {
+	return &repository.Repositories{
+		Profiles:      &profiles{s},
+		Visits:        &visits{s},
+		Schedules:     &schedules{s},
+		Feedbacks:     &feedbacks{s},
+		SevaTypes:     &sevaTypes{s},
+		StayAreas:     &stayAreas{s},
+		Lockers:       &lockers{s},
+		Notifications: &notifications{s},
+		Webhooks:      &webhooks{s},
+		Users:         &users{s},
+		Audit:         &audit{s},
+		RollCalls:     &rollCalls{s},
+		Retention:     &retention{s},
+		Exports:       &exports{s},
+		Health:        health{},
+		Transactions:  &transactions{s},
+	}
+}
+
+// AddLocker seeds a locker, lockers have no create endpoint
+func (s *Store) AddLocker(locker model.Locker) *model.Locker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if locker.ID == uuid.Nil {
+		locker.ID = uuid.New()
+	}
+	if locker.CreatedAt.IsZero() {
+		locker.CreatedAt = s.now()
+	}
+	s.lockers[locker.ID] = locker
+	return &locker
+}
+
+// AddWebhookDelivery seeds a delivery, in the database they are recorded
+// alongside the change that emitted the event
+func (s *Store) AddWebhookDelivery(delivery model.WebhookDelivery) *model.WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if delivery.ID == uuid.Nil {
+		delivery.ID = uuid.New()
+	}
+	now := s.now()
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = now
+	}
+	delivery.UpdatedAt = now
+	s.webhookDeliveries[delivery.ID] = delivery
+	return &delivery
+}
+
+// parses an ID the way PostgreSQL would compare it, a malformed ID simply
+// matches nothing
+func parseID(id string) (uuid.UUID, bool) {
+	parsed, err := uuid.Parse(id)
+	return parsed, err == nil
+}
+
+// loads the stay area and locker of a visit, like Preload("StayArea").Preload("Locker")
+func (s *Store) withPlace(v model.Visit) model.Visit {
+	v.StayArea = s.stayAreas[v.StayAreaID]
+	v.Locker = nil
+	if v.LockerID != nil {
+		if locker, ok := s.lockers[*v.LockerID]; ok {
+			v.Locker = &locker
+		}
+	}
+	return v
+}
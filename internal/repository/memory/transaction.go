@@ -0,0 +1,27 @@
+package memory
+
+import "counterapp/internal/repository"
+
+type transactions struct {
+	s *Store
+}
+
+// runs fn on the shared store and puts back a snapshot taken before it when
+// fn fails. Transactions run one at a time; writes made outside of one while
+// it runs are lost with the rollback, fine for the tests this store is for.
+func (r *transactions) Run(fn func(repos *repository.Repositories) error) error {
+	r.s.txMu.Lock()
+	defer r.s.txMu.Unlock()
+
+	r.s.mu.RLock()
+	snapshot := r.s.tables.clone()
+	r.s.mu.RUnlock()
+
+	if err := fn(r.s.Repositories()); err != nil {
+		r.s.mu.Lock()
+		r.s.tables = snapshot
+		r.s.mu.Unlock()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+// Package repository describes the data access the handlers need, so they
+// can run against PostgreSQL (NewGormRepositories) or entirely in memory
+// (see the memory package).
+package repository
+
+import (
+	"context"
+	"counterapp/internal/dao"
+	"counterapp/internal/model"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// returned when the requested record does not exist
+	ErrNotFound = errors.New("record not found")
+
+	// returned when a record breaks a unique constraint, e.g. a second
+	// profile with the same email
+	ErrDuplicate = errors.New("record already exists")
+
+	// returned when restoring a visit of a deleted profile
+	ErrParentDeleted = dao.ErrParentDeleted
+)
+
+type ProfileRepository interface {
+	GetProfilesData() ([]model.ProfileOverview, error)
+	GetByID(profileID string) (*model.Profile, error)
+	// like GetByID, finding deleted profiles too
+	GetByIDWithDeleted(profileID string) (*model.Profile, error)
+	// returns nil without error when no profile has the email
+	GetByEmail(email string) (*model.Profile, error)
+	Create(profile *model.Profile) (*model.Profile, error)
+	Update(profileID string, updates *model.ProfileUpdate) (*model.Profile, error)
+	// replaces the medical info, nil clears it
+	SetMedical(profileID string, medical *model.MedicalInfo) (*model.Profile, error)
+	// soft deletes the profile with its visits, schedules and feedback
+	Delete(profileID string) error
+	// brings back a deleted profile and what was deleted with it, returns
+	// ErrNotFound unless the profile is deleted
+	Restore(profileID string) (*model.Profile, error)
+	// removes a deleted profile and all its records for good, returns
+	// ErrNotFound unless the profile is deleted
+	Purge(profileID string) error
+	// returns everything stored about the profile, deleted or not, and
+	// audits the export
+	ExportPersonalData(profileID string, actorID *uuid.UUID) (*model.PersonalData, error)
+	// anonymizes the profile, deleted or not, and audits it, returns
+	// ErrNotFound when the profile is already erased
+	Erase(profileID string, actorID *uuid.UUID) (*model.Profile, error)
+}
+
+type VisitRepository interface {
+	GetByProfileID(profileID string) ([]model.Visit, error)
+	// returns the visit with its stay area and locker
+	GetByID(visitID string) (*model.Visit, error)
+	// returns the visit with its profile, stay area and locker
+	GetDetailsByID(visitID string) (*model.Visit, error)
+	// returns nil without error when the profile has no visit arriving then
+	GetByProfileAndArrivalDate(profileID string, arrivalDate time.Time) (*model.Visit, error)
+	// returns checked-in visits whose departure date is before today
+	GetOverdue(today time.Time) ([]model.Visit, error)
+	Add(req model.AddVisitRequest) (*model.Visit, error)
+	Update(visitID string, req model.UpdateVisitRequest) (*model.Visit, error)
+	// soft deletes the visit with its schedules and feedback
+	Delete(visitID string) error
+	// brings back a deleted visit and what was deleted with it, returns
+	// ErrNotFound unless the visit is deleted
+	Restore(visitID string) (*model.Visit, error)
+	// removes a deleted visit with its schedules and feedback for good,
+	// returns ErrNotFound unless the visit is deleted
+	Purge(visitID string) error
+}
+
+type ScheduleRepository interface {
+	GetForDateRange(startDate time.Time, endDate time.Time) ([]model.Schedule, error)
+	// returns nil without error when the profile has no schedule on date
+	GetByProfileAndDate(profileID string, date time.Time) (*model.Schedule, error)
+	Add(req model.AddScheduleRequest) (*model.Schedule, error)
+}
+
+type FeedbackRepository interface {
+	GetAll() ([]model.Feedback, error)
+	GetForProfile(profileID string) ([]model.Feedback, error)
+	Add(req model.AddFeedbackRequest) (*model.Feedback, error)
+}
+
+type SevaTypeRepository interface {
+	GetAll() ([]model.SevaType, error)
+	GetByID(id string) (*model.SevaType, error)
+	GetByName(name string) (*model.SevaType, error)
+	Add(req model.AddSevaTypeRequest) (*model.SevaType, error)
+}
+
+type StayAreaRepository interface {
+	GetAll() ([]model.StayArea, error)
+	GetByID(id string) (*model.StayArea, error)
+	GetByName(name string) (*model.StayArea, error)
+	Add(req model.AddStayAreaRequest) (*model.StayArea, error)
+	GetAllWithOccupancy() ([]model.StayAreaOccupancy, error)
+}
+
+type LockerRepository interface {
+	GetAll() ([]model.Locker, error)
+}
+
+type NotificationRepository interface {
+	// stores a message in the outbox, messages whose DedupKey is already
+	// queued are dropped
+	Enqueue(msg *model.OutboxMessage) error
+	List(status string, limit int) ([]model.OutboxMessage, error)
+	Retry(id string) (*model.OutboxMessage, error)
+}
+
+type WebhookRepository interface {
+	GetAllSubscriptions() ([]model.WebhookSubscription, error)
+	AddSubscription(req model.AddWebhookSubscriptionRequest) (*model.WebhookSubscription, error)
+	DeactivateSubscription(id string) error
+	GetDeliveries(subscriptionID string, limit int) ([]model.WebhookDelivery, error)
+	Redeliver(deliveryID string) (*model.WebhookDelivery, error)
+}
+
+type AuditRepository interface {
+	List(filter model.AuditFilter) ([]model.AuditRecord, error)
+}
+
+type RollCallRepository interface {
+	// returns the checked-in visits as the entries of a roll call that is
+	// not stored, with the seva each person has on today
+	OnSite(today time.Time) ([]model.RollCallEntry, error)
+	// stores a roll call of everyone checked in now
+	Start(note *string, startedBy *uuid.UUID, today time.Time) (*model.RollCall, error)
+	List(limit int) ([]model.RollCallSummary, error)
+	// returns the roll call with its entries by stay area and name
+	GetByID(id string) (*model.RollCall, error)
+	// sets or clears the accounted-for mark, returns ErrNotFound when the
+	// entry is not in the roll call
+	MarkEntry(rollCallID string, entryID string, accounted bool, by *uuid.UUID) (*model.RollCallEntry, error)
+	Close(id string) (*model.RollCall, error)
+}
+
+type RetentionRepository interface {
+	// reports what Apply would change at now without changing anything
+	Plan(policy model.RetentionPolicy, now time.Time) (*model.RetentionReport, error)
+	// runs the enabled rules, auditing every row changed
+	Apply(policy model.RetentionPolicy, now time.Time) (*model.RetentionReport, error)
+}
+
+// ExportRepository streams the rows of the exports to fn one at a time, so a
+// large export is never loaded at once. An error from fn stops the stream and
+// is returned.
+type ExportRepository interface {
+	// schedules in the date range ordered by date, seva type and name
+	Schedules(startDate time.Time, endDate time.Time, fn func(model.ScheduleExportRow) error) error
+	// checked-in residents ordered by stay area and name, an empty
+	// stayAreaID returns the residents of every stay area
+	Residents(stayAreaID string, fn func(model.ResidentExportRow) error) error
+	// the rows of ProfileRepository.GetProfilesData ordered by name
+	Profiles(fn func(model.ProfileOverview) error) error
+}
+
+// HealthRepository backs the readiness probe, its checks change nothing
+type HealthRepository interface {
+	// checks the database answers
+	Ping(ctx context.Context) error
+	// checks the schema has every migration this build knows
+	CheckSchema(ctx context.Context) error
+}
+
+type UserRepository interface {
+	Create(user *model.User) (*model.User, error)
+	GetByTokenHash(tokenHash string) (*model.User, error)
+}
+
+type TransactionRepository interface {
+	// runs fn with repositories whose changes are all kept when fn returns
+	// nil and all discarded otherwise. Calls do not nest.
+	Run(fn func(repos *Repositories) error) error
+}
+
+// Repositories is the container handed to the API layer
+type Repositories struct {
+	Profiles      ProfileRepository
+	Visits        VisitRepository
+	Schedules     ScheduleRepository
+	Feedbacks     FeedbackRepository
+	SevaTypes     SevaTypeRepository
+	StayAreas     StayAreaRepository
+	Lockers       LockerRepository
+	Notifications NotificationRepository
+	Webhooks      WebhookRepository
+	Users         UserRepository
+	Audit         AuditRepository
+	RollCalls     RollCallRepository
+	Retention     RetentionRepository
+	Exports       ExportRepository
+	Health        HealthRepository
+	Transactions  TransactionRepository
+}
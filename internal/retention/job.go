@@ -0,0 +1,55 @@
+// Package retention runs the data retention rules in the background.
+package retention
+
+import (
+	"context"
+	"counterapp/internal/config"
+	"counterapp/internal/service"
+	"log/slog"
+	"time"
+)
+
+// Job applies the retention rules in the background every cfg.Interval
+type Job struct {
+	svc    *service.RetentionService
+	cfg    config.Retention
+	logger *slog.Logger
+}
+
+// svc should run on repositories whose queries end with the context given
+// to Run, so a run stops at shutdown
+func NewJob(svc *service.RetentionService, cfg config.Retention, logger *slog.Logger) *Job {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Job{svc: svc, cfg: cfg, logger: logger}
+}
+
+// Run applies the rules until ctx is cancelled, starting right away
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		j.apply()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *Job) apply() {
+	report, err := j.svc.Apply(j.cfg, time.Now())
+	if err != nil {
+		j.logger.Error("error while applying retention rules", "error", err)
+		return
+	}
+	for _, r := range report.Rules {
+		if r.Count > 0 {
+			j.logger.Info("applied retention rule", "rule", r.Rule, "action", r.Action, "count", r.Count)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package seed
+
+import "counterapp/internal/model"
+
+var sevaTypes = []struct {
+	name        string
+	description string
+	locations   []string
+}{
+	{"Dining", "Food service and dining hall seva", []string{"Dining Hall", "Annexe Dining"}},
+	{"Kitchen Support", "Kitchen preparation and cleaning seva", []string{"Main Kitchen", "Bakery"}},
+	{"Counter", "Reception and counter service seva", []string{"Reception Counter", "Information Desk"}},
+	{"Cleaning", "General cleaning and maintenance seva", []string{"Meditation Hall", "Dormitory Block"}},
+	{"Security", "Security and monitoring seva", []string{"Main Gate", "Parking"}},
+	{"Garden", "Gardening and landscaping seva", []string{"Front Garden", "Nursery"}},
+	{"Transport", "Transportation and logistics seva", []string{"Vehicle Bay"}},
+	{"Medical", "Medical assistance and first aid seva", []string{"Clinic"}},
+}
+
+var stayAreas = []struct {
+	name     string
+	capacity int
+}{
+	{"Dormitory A - Men", 50},
+	{"Dormitory B - Men", 50},
+	{"Dormitory C - Women", 40},
+	{"Dormitory D - Women", 40},
+	{"Family Room Block 1", 20},
+	{"Family Room Block 2", 20},
+	{"Guest House - VIP", 10},
+	{"Cottage Area", 15},
+}
+
+var categories = []model.Category{model.CategorySTV, model.CategoryLTV, model.CategoryOverseas}
+
+var maleNames = []string{
+	"Aarav", "Arjun", "Amit", "Karthik", "Rajesh", "Rohan", "Sanjay", "Suresh",
+	"Vikram", "Vivek", "Anand", "Deepak", "Harish", "Manoj", "Naveen", "Pranav",
+	"Daniel", "Lukas", "Mateo", "Thomas",
+}
+
+var femaleNames = []string{
+	"Ananya", "Deepa", "Divya", "Kavya", "Lakshmi", "Meera", "Nisha", "Pooja",
+	"Priya", "Radha", "Shreya", "Sneha", "Anjali", "Gayatri", "Ishita", "Revathi",
+	"Anna", "Clara", "Sofia", "Emma",
+}
+
+var lastNames = []string{
+	"Sharma", "Patel", "Reddy", "Iyer", "Nair", "Menon", "Singh", "Kumar",
+	"Desai", "Joshi", "Rao", "Pillai", "Gupta", "Mehta", "Bose", "Das",
+	"Krishnan", "Verma", "Fischer", "Garcia",
+}
+
+var coordinators = []string{"Kitchen Coordinator", "Counter Manager", "Seva Coordinator", "Dining Manager", "Reception Head"}
+
+var feedbackContents = map[model.FeedbackType][]string{
+	model.TypePositive: {
+		"Very dedicated and punctual. Great team player.",
+		"Excellent work throughout the stay. Highly recommended.",
+		"Always arrives early for shifts. Very enthusiastic.",
+		"Handles guests with patience and care.",
+	},
+	model.TypeNeutral: {
+		"Needs more training on counter procedures.",
+		"Adapting to the environment, could take more initiative.",
+	},
+	model.TypeNegative: {
+		"Missed several shifts without informing the coordinator.",
+		"Did not follow kitchen hygiene guidelines.",
+	},
+}
@@ -0,0 +1,515 @@
+// Package seed fills a database with reference data and a reproducible set
+// of volunteers, visits, schedules and feedback for development, demos and
+// load tests.
+package seed
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/util"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	batchSize = 500
+	// profiles generated and written at a time, memory stays flat however
+	// many are asked for
+	chunkSize = 5000
+)
+
+// ErrNotEmpty is returned when profiles already exist and Append is not set
+var ErrNotEmpty = errors.New("database already has profiles, clear it first or append")
+
+type Options struct {
+	Profiles int
+	// total visits to aim for, made up with earlier stays. Zero gives one or
+	// two per profile
+	Visits  int
+	Lockers int
+	// generated blocks on top of the reference stay areas, which only take
+	// about 250 checked-in volunteers
+	StayAreas int
+	// adds to the profiles already there instead of refusing, occupancy and
+	// free lockers are taken into account
+	Append bool
+	// the same seed, day and number of existing profiles produce the same
+	// data
+	Seed uint64
+	// visits and schedules are placed around this day, zero means today
+	Today  time.Time
+	Logger *slog.Logger
+}
+
+func DefaultOptions() Options {
+	return Options{Profiles: 50, Lockers: 100, Seed: 1}
+}
+
+func (o Options) withDefaults() Options {
+	if o.Today.IsZero() {
+		o.Today = time.Now()
+	}
+	o.Today = util.Day(o.Today)
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	return o
+}
+
+type Summary struct {
+	SevaTypes int
+	StayAreas int
+	Lockers   int
+	Profiles  int
+	Visits    int
+	Schedules int
+	Feedbacks int
+}
+
+// the tables Clear empties, users and schema_migrations are kept
+var clearedTables = []string{
+	"roll_call_entries", "roll_calls",
+	"webhook_deliveries", "webhook_subscriptions", "outbox_messages",
+	"feedbacks", "schedules", "visits", "profiles",
+	"lockers", "stay_areas", "seva_types",
+}
+
+// removes every profile, visit and the reference data
+func Clear(db *gorm.DB) error {
+	return db.Exec("TRUNCATE TABLE " + strings.Join(clearedTables, ", ") + " CASCADE").Error
+}
+
+// inserts the reference data when missing and opts.Profiles generated
+// volunteers with their visits, schedules and feedback, all in one
+// transaction. The volunteers are written in chunks so large runs do not
+// hold everything in memory.
+func Run(db *gorm.DB, opts Options) (*Summary, error) {
+	opts = opts.withDefaults()
+
+	var summary *Summary
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&model.Profile{}).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 && !opts.Append {
+			return ErrNotEmpty
+		}
+
+		// appending again with the same seed continues instead of repeating
+		// the IDs and emails of the first run
+		g := &generator{
+			rng:      rand.New(rand.NewPCG(opts.Seed, opts.Seed+uint64(existing))),
+			today:    opts.Today,
+			existing: int(existing),
+			summary:  &Summary{},
+		}
+		if err := g.loadReferenceData(tx, opts.StayAreas); err != nil {
+			return err
+		}
+		if err := g.loadOccupancy(tx); err != nil {
+			return err
+		}
+		if err := g.createLockers(tx, opts.Lockers); err != nil {
+			return err
+		}
+
+		for start := 0; start < opts.Profiles; start += chunkSize {
+			end := min(start+chunkSize, opts.Profiles)
+			for i := start; i < end; i++ {
+				g.volunteer(i, opts)
+			}
+			if err := g.flush(tx); err != nil {
+				return err
+			}
+			opts.Logger.Info("seeded profiles", "done", end, "total", opts.Profiles)
+		}
+
+		// the lockers handed out above
+		err := tx.Model(&model.Locker{}).
+			Where("id IN (?)", tx.Model(&model.Visit{}).Select("locker_id").Where("status = ? AND locker_id IS NOT NULL", model.StatusCheckedIn)).
+			Update("is_occupied", true).Error
+		if err != nil {
+			return err
+		}
+		summary = g.summary
+		return nil
+	})
+	return summary, err
+}
+
+type generator struct {
+	rng   *rand.Rand
+	today time.Time
+	// profiles there before this run, new emails are numbered after them
+	existing int
+	summary  *Summary
+
+	sevaTypes   []model.SevaType
+	stayAreas   []model.StayArea
+	occupancy   map[uuid.UUID]int
+	freeLockers []uuid.UUID
+
+	// the current chunk, emptied by flush
+	profiles  []model.Profile
+	visits    []model.Visit
+	schedules []model.Schedule
+	feedbacks []model.Feedback
+}
+
+// creates the seva types and stay areas that do not exist yet, matching by
+// name, and loads them all. extra generated blocks are added after the
+// reference stay areas.
+func (g *generator) loadReferenceData(tx *gorm.DB, extra int) error {
+	for _, st := range sevaTypes {
+		description := st.description
+		sevaType := model.SevaType{ID: g.newID(), Name: st.name, Description: &description, IsActive: true}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&sevaType).Error; err != nil {
+			return fmt.Errorf("seva type %s: %w", st.name, err)
+		}
+	}
+	if err := tx.Order("name").Find(&g.sevaTypes).Error; err != nil {
+		return err
+	}
+
+	var existing []model.StayArea
+	if err := tx.Find(&existing).Error; err != nil {
+		return err
+	}
+	known := map[string]bool{}
+	for _, sa := range existing {
+		known[sa.Name] = true
+	}
+	for _, sa := range stayAreas {
+		if known[sa.name] {
+			continue
+		}
+		if err := tx.Create(&model.StayArea{ID: g.newID(), Name: sa.name, Capacity: sa.capacity}).Error; err != nil {
+			return fmt.Errorf("stay area %s: %w", sa.name, err)
+		}
+	}
+	var blocks []model.StayArea
+	for i := 1; i <= extra; i++ {
+		name := blockName(i)
+		if known[name] {
+			continue
+		}
+		blocks = append(blocks, model.StayArea{ID: g.newID(), Name: name, Capacity: 10 + 5*g.rng.IntN(9)})
+	}
+	if err := tx.CreateInBatches(&blocks, batchSize).Error; err != nil {
+		return err
+	}
+	if err := tx.Order("name").Find(&g.stayAreas).Error; err != nil {
+		return err
+	}
+	g.summary.SevaTypes, g.summary.StayAreas = len(g.sevaTypes), len(g.stayAreas)
+	return nil
+}
+
+// generated stay areas take turns being for men, women and anyone
+func blockName(i int) string {
+	switch i % 3 {
+	case 1:
+		return fmt.Sprintf("Block %03d - Men", i)
+	case 2:
+		return fmt.Sprintf("Block %03d - Women", i)
+	}
+	return fmt.Sprintf("Block %03d", i)
+}
+
+// counts the beds already taken, new check-ins only go to free ones
+func (g *generator) loadOccupancy(tx *gorm.DB) error {
+	var rows []struct {
+		StayAreaID uuid.UUID
+		Count      int
+	}
+	err := tx.Model(&model.Visit{}).
+		Select("stay_area_id, count(*) AS count").
+		Where("status = ?", model.StatusCheckedIn).
+		Group("stay_area_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	g.occupancy = map[uuid.UUID]int{}
+	for _, row := range rows {
+		g.occupancy[row.StayAreaID] = row.Count
+	}
+	return nil
+}
+
+// creates n lockers numbered after the existing ones, free lockers from
+// earlier runs are handed out first
+func (g *generator) createLockers(tx *gorm.DB, n int) error {
+	var existing int64
+	if err := tx.Model(&model.Locker{}).Count(&existing).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&model.Locker{}).Where("NOT is_occupied").Order("locker_number").Pluck("id", &g.freeLockers).Error; err != nil {
+		return err
+	}
+
+	lockers := make([]model.Locker, 0, n)
+	for i := 1; i <= n; i++ {
+		section := "Section A"
+		if i > (n+1)/2 {
+			section = "Section B"
+		}
+		lockers = append(lockers, model.Locker{ID: g.newID(), LockerNumber: fmt.Sprintf("L%03d", int(existing)+i), Section: section})
+		g.freeLockers = append(g.freeLockers, lockers[i-1].ID)
+	}
+	if err := tx.CreateInBatches(&lockers, batchSize).Error; err != nil {
+		return err
+	}
+	g.summary.Lockers = n
+	return nil
+}
+
+// generates the i-th profile of the run with its earlier stays and maybe a
+// current or upcoming visit
+func (g *generator) volunteer(i int, opts Options) {
+	profile := g.profile(g.existing + i)
+	g.profiles = append(g.profiles, profile)
+
+	// earlier stays, walking back from the latest
+	departed := g.today.AddDate(0, 0, -g.rng.IntN(30)-30*g.rng.IntN(3)-1)
+	history := g.rng.IntN(3)
+	if opts.Visits > 0 {
+		// keeps the running total, current and upcoming visits included, on
+		// course for opts.Visits
+		due := opts.Visits*(i+1)/opts.Profiles - g.summary.Visits - len(g.visits)
+		history = max(0, due-g.rng.IntN(2))
+	}
+	for n := history; n > 0; n-- {
+		arrival := departed.AddDate(0, 0, -3-g.rng.IntN(20))
+		visit := g.visit(profile, arrival, departed, model.StatusCheckedOut)
+		if visit == nil {
+			break
+		}
+		if g.rng.IntN(10) < 3 {
+			g.feedback(profile, visit, departed)
+		}
+		departed = arrival.AddDate(0, 0, -10-g.rng.IntN(60))
+	}
+	if profile.IsBlocked {
+		g.feedbacks = append(g.feedbacks, model.Feedback{
+			ID: g.newID(), ProfileID: profile.ID, Content: "Conduct concerns reported by the stay area coordinator.",
+			Type: model.TypeNegative, CreatedBy: ptr("Admin"), CreatedAt: g.today.AddDate(0, 0, -g.rng.IntN(30)),
+		})
+		return
+	}
+
+	switch roll := g.rng.IntN(100); {
+	case roll < 45:
+		arrival := g.today.AddDate(0, 0, -g.rng.IntN(14))
+		departure := g.today.AddDate(0, 0, g.rng.IntN(14))
+		if g.rng.IntN(20) == 0 {
+			// left without checking out
+			departure = g.today.AddDate(0, 0, -1-g.rng.IntN(3))
+			if departure.Before(arrival) {
+				arrival = departure
+			}
+		}
+		visit := g.visit(profile, arrival, departure, model.StatusCheckedIn)
+		if visit == nil {
+			return
+		}
+		if len(g.freeLockers) > 0 && g.rng.IntN(10) < 7 {
+			lockerID := g.freeLockers[0]
+			g.freeLockers = g.freeLockers[1:]
+			visit.LockerID = &lockerID
+		}
+		g.scheduleVisit(profile, visit)
+	case roll < 55:
+		arrival := g.today.AddDate(0, 0, 1+g.rng.IntN(14))
+		g.visit(profile, arrival, arrival.AddDate(0, 0, 3+g.rng.IntN(10)), model.StatusPending)
+	}
+}
+
+// writes the current chunk and empties it
+func (g *generator) flush(tx *gorm.DB) error {
+	for _, rows := range []any{&g.profiles, &g.visits, &g.schedules, &g.feedbacks} {
+		if err := tx.CreateInBatches(rows, batchSize).Error; err != nil {
+			return err
+		}
+	}
+	g.summary.Profiles += len(g.profiles)
+	g.summary.Visits += len(g.visits)
+	g.summary.Schedules += len(g.schedules)
+	g.summary.Feedbacks += len(g.feedbacks)
+	g.profiles, g.visits, g.schedules, g.feedbacks = g.profiles[:0], g.visits[:0], g.schedules[:0], g.feedbacks[:0]
+	return nil
+}
+
+// the n-th profile in the database, n numbers the email
+func (g *generator) profile(n int) model.Profile {
+	gender := model.GenderMale
+	firstNames := maleNames
+	switch roll := g.rng.IntN(100); {
+	case roll < 48:
+		gender, firstNames = model.GenderFemale, femaleNames
+	case roll < 50:
+		gender = model.GenderOther
+	}
+	first := firstNames[g.rng.IntN(len(firstNames))]
+	last := lastNames[g.rng.IntN(len(lastNames))]
+
+	channel := model.ChannelEmail
+	switch g.rng.IntN(10) {
+	case 0:
+		channel = model.ChannelSMS
+	case 1, 2:
+		channel = model.ChannelWhatsApp
+	}
+
+	created := g.today.AddDate(0, 0, -g.rng.IntN(365)-1)
+	return model.Profile{
+		ID:                  g.newID(),
+		Name:                first + " " + last,
+		Email:               fmt.Sprintf("%s.%s.%d@example.com", strings.ToLower(first), strings.ToLower(last), n+1),
+		PhoneNumber:         fmt.Sprintf("+9198%08d", g.rng.IntN(100_000_000)),
+		Gender:              gender,
+		Category:            categories[g.rng.IntN(len(categories))],
+		IsBlocked:           g.rng.IntN(100) < 3,
+		NotificationChannel: channel,
+		CreatedAt:           created,
+		UpdatedAt:           created,
+	}
+}
+
+// adds a visit to a stay area suitable for the profile, checked-in visits
+// only go to areas with a free bed. Returns nil when no area fits.
+func (g *generator) visit(profile model.Profile, arrival time.Time, departure time.Time, status model.ProfileStatus) *model.Visit {
+	var candidates []model.StayArea
+	for _, sa := range g.stayAreas {
+		if !suits(sa, profile.Gender) {
+			continue
+		}
+		if status == model.StatusCheckedIn && g.occupancy[sa.ID] >= sa.Capacity {
+			continue
+		}
+		candidates = append(candidates, sa)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	stayArea := candidates[g.rng.IntN(len(candidates))]
+	if status == model.StatusCheckedIn {
+		g.occupancy[stayArea.ID]++
+	}
+
+	visit := model.Visit{
+		ID:            g.newID(),
+		ProfileID:     profile.ID,
+		ArrivalDate:   arrival,
+		DepartureDate: &departure,
+		StayAreaID:    stayArea.ID,
+		Status:        status,
+		CreatedAt:     arrival,
+	}
+	if status == model.StatusCheckedIn || status == model.StatusCheckedOut {
+		checkedInAt := arrival
+		visit.CheckedInAt = &checkedInAt
+	}
+	if status == model.StatusCheckedOut {
+		checkedOutAt := departure
+		visit.CheckedOutAt = &checkedOutAt
+	}
+	g.visits = append(g.visits, visit)
+	return &g.visits[len(g.visits)-1]
+}
+
+// areas named "... - Men" or "... - Women" only take that gender
+func suits(sa model.StayArea, gender model.Gender) bool {
+	switch {
+	case strings.HasSuffix(sa.Name, "- Men"):
+		return gender == model.GenderMale
+	case strings.HasSuffix(sa.Name, "- Women"):
+		return gender == model.GenderFemale
+	}
+	return true
+}
+
+// one seva on most days from two days ago, or the arrival, to tomorrow
+func (g *generator) scheduleVisit(profile model.Profile, visit *model.Visit) {
+	day := g.today.AddDate(0, 0, -2)
+	if visit.ArrivalDate.After(day) {
+		day = visit.ArrivalDate
+	}
+	last := g.today.AddDate(0, 0, 1)
+	if visit.DepartureDate.Before(last) {
+		last = *visit.DepartureDate
+	}
+
+	for ; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if g.rng.IntN(10) < 2 {
+			continue
+		}
+		seva := sevaTypes[g.rng.IntN(len(sevaTypes))]
+		location := seva.locations[g.rng.IntN(len(seva.locations))]
+		g.schedules = append(g.schedules, model.Schedule{
+			ID:         g.newID(),
+			ProfileID:  profile.ID,
+			VisitID:    visit.ID,
+			Date:       day,
+			SevaTypeID: g.sevaTypeID(seva.name),
+			Location:   &location,
+			CreatedAt:  day.AddDate(0, 0, -1),
+		})
+	}
+}
+
+func (g *generator) feedback(profile model.Profile, visit *model.Visit, at time.Time) {
+	feedbackType := model.TypePositive
+	switch roll := g.rng.IntN(10); {
+	case roll == 0:
+		feedbackType = model.TypeNegative
+	case roll < 3:
+		feedbackType = model.TypeNeutral
+	}
+	contents := feedbackContents[feedbackType]
+	visitID := visit.ID
+	g.feedbacks = append(g.feedbacks, model.Feedback{
+		ID:        g.newID(),
+		ProfileID: profile.ID,
+		VisitID:   &visitID,
+		Content:   contents[g.rng.IntN(len(contents))],
+		Type:      feedbackType,
+		CreatedBy: ptr(coordinators[g.rng.IntN(len(coordinators))]),
+		CreatedAt: at,
+	})
+}
+
+func (g *generator) sevaTypeID(name string) uuid.UUID {
+	for _, st := range g.sevaTypes {
+		if st.Name == name {
+			return st.ID
+		}
+	}
+	return g.sevaTypes[0].ID
+}
+
+// a version 4 UUID drawn from the seeded generator
+func (g *generator) newID() uuid.UUID {
+	var id uuid.UUID
+	for i := 0; i < len(id); i += 8 {
+		v := g.rng.Uint64()
+		for j := 0; j < 8; j++ {
+			id[i+j] = byte(v >> (8 * j))
+		}
+	}
+	id[6] = (id[6] & 0x0f) | 0x40
+	id[8] = (id[8] & 0x3f) | 0x80
+	return id
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
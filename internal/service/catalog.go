@@ -0,0 +1,54 @@
+package service
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+)
+
+type SevaTypeService struct {
+	repos *repository.Repositories
+}
+
+func (s *SevaTypeService) List() ([]model.SevaType, error) {
+	return s.repos.SevaTypes.GetAll()
+}
+
+func (s *SevaTypeService) Add(req model.AddSevaTypeRequest) (*model.SevaType, error) {
+	sevaType, err := s.repos.SevaTypes.Add(req)
+	if err != nil {
+		return nil, conflictAs(err, "A seva type with this name already exists")
+	}
+	return sevaType, nil
+}
+
+type StayAreaService struct {
+	repos *repository.Repositories
+}
+
+func (s *StayAreaService) List() ([]model.StayArea, error) {
+	return s.repos.StayAreas.GetAll()
+}
+
+func (s *StayAreaService) GetByName(name string) (*model.StayArea, error) {
+	stayArea, err := s.repos.StayAreas.GetByName(name)
+	if err != nil {
+		return nil, notFoundAs(err, "Stay area not found")
+	}
+	return stayArea, nil
+}
+
+func (s *StayAreaService) Add(req model.AddStayAreaRequest) (*model.StayArea, error) {
+	return s.repos.StayAreas.Add(req)
+}
+
+func (s *StayAreaService) Occupancy() ([]model.StayAreaOccupancy, error) {
+	return s.repos.StayAreas.GetAllWithOccupancy()
+}
+
+type LockerService struct {
+	repos *repository.Repositories
+}
+
+func (s *LockerService) List() ([]model.Locker, error) {
+	return s.repos.Lockers.GetAll()
+}
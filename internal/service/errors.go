@@ -0,0 +1,85 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind classifies domain errors so callers can react without matching
+// messages, e.g. the HTTP layer maps each kind to a status code
+type Kind string
+
+const (
+	KindNotFound   Kind = "not_found"
+	KindConflict   Kind = "conflict"
+	KindValidation Kind = "validation"
+	KindForbidden  Kind = "forbidden"
+	// the caller could not be identified, e.g. an unknown API token
+	KindUnauthorized Kind = "unauthorized"
+)
+
+// FieldError points a validation failure at one input field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is returned for every rule the service enforces. Errors of any other
+// type are unexpected failures of the storage underneath.
+type Error struct {
+	Kind    Kind
+	Message string
+	Details []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func NotFound(format string, args ...any) error {
+	return &Error{Kind: KindNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func Conflict(format string, args ...any) error {
+	return &Error{Kind: KindConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func Validation(format string, args ...any) error {
+	return &Error{Kind: KindValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// a Validation error for a single field, the message doubles as the detail
+func InvalidField(field string, format string, args ...any) error {
+	message := fmt.Sprintf(format, args...)
+	return &Error{Kind: KindValidation, Message: message, Details: []FieldError{{Field: field, Message: message}}}
+}
+
+func Forbidden(format string, args ...any) error {
+	return &Error{Kind: KindForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+func Unauthorized(format string, args ...any) error {
+	return &Error{Kind: KindUnauthorized, Message: fmt.Sprintf(format, args...)}
+}
+
+// returns the kind of a domain error, or false for unexpected errors
+func KindOf(err error) (Kind, bool) {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Kind, true
+	}
+	return "", false
+}
+
+func IsNotFound(err error) bool {
+	kind, ok := KindOf(err)
+	return ok && kind == KindNotFound
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+	"time"
+)
+
+// ExportService streams the rows of the exports, fn gets one row at a time
+// and an error from it stops the export
+type ExportService struct {
+	repos *repository.Repositories
+}
+
+func (s *ExportService) Schedules(startDate time.Time, endDate time.Time, fn func(model.ScheduleExportRow) error) error {
+	return s.repos.Exports.Schedules(startDate, endDate, fn)
+}
+
+// an empty stayAreaID exports the residents of every stay area
+func (s *ExportService) Residents(stayAreaID string, fn func(model.ResidentExportRow) error) error {
+	return s.repos.Exports.Residents(stayAreaID, fn)
+}
+
+func (s *ExportService) Profiles(fn func(model.ProfileOverview) error) error {
+	return s.repos.Exports.Profiles(fn)
+}
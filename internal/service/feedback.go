@@ -0,0 +1,37 @@
+package service
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+)
+
+type FeedbackService struct {
+	repos *repository.Repositories
+}
+
+func (s *FeedbackService) List() ([]model.Feedback, error) {
+	return s.repos.Feedbacks.GetAll()
+}
+
+func (s *FeedbackService) ListForProfile(profileID string) ([]model.Feedback, error) {
+	return s.repos.Feedbacks.GetForProfile(profileID)
+}
+
+func (s *FeedbackService) Add(req model.AddFeedbackRequest) (*model.Feedback, error) {
+	if !req.Type.IsValid() {
+		return nil, InvalidField("type", "Invalid feedback type, use Positive, Negative or Neutral")
+	}
+	if _, err := s.repos.Profiles.GetByID(req.ProfileID.String()); err != nil {
+		return nil, notFoundAs(err, "Profile not found")
+	}
+	if req.VisitID != nil {
+		visit, err := s.repos.Visits.GetByID(req.VisitID.String())
+		if err != nil {
+			return nil, notFoundAs(err, "Visit not found")
+		}
+		if visit.ProfileID != req.ProfileID {
+			return nil, InvalidField("visit_id", "Visit does not belong to the profile")
+		}
+	}
+	return s.repos.Feedbacks.Add(req)
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"counterapp/internal/repository"
+)
+
+// HealthService runs the readiness checks, none of them changes anything
+type HealthService struct {
+	repos *repository.Repositories
+}
+
+// the database answers
+func (s *HealthService) Database(ctx context.Context) error {
+	return s.repos.Health.Ping(ctx)
+}
+
+// the schema has every migration this build knows
+func (s *HealthService) Schema(ctx context.Context) error {
+	return s.repos.Health.CheckSchema(ctx)
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+)
+
+const MaxNotificationsListed = 500
+
+type NotificationService struct {
+	repos *repository.Repositories
+}
+
+// lists the most recent outbox messages, status is optional
+func (s *NotificationService) List(status string, limit int) ([]model.OutboxMessage, error) {
+	switch model.DeliveryStatus(status) {
+	case "", model.DeliveryPending, model.DeliverySending, model.DeliverySent, model.DeliveryFailed:
+	default:
+		return nil, InvalidField("status", "Invalid status, use pending, sending, sent or failed")
+	}
+	if limit <= 0 || limit > MaxNotificationsListed {
+		return nil, InvalidField("limit", "Limit must be between 1 and %d", MaxNotificationsListed)
+	}
+	return s.repos.Notifications.List(status, limit)
+}
+
+// queues a failed message again
+func (s *NotificationService) Retry(id string) (*model.OutboxMessage, error) {
+	msg, err := s.repos.Notifications.Retry(id)
+	if err != nil {
+		return nil, notFoundAs(err, "Failed notification not found")
+	}
+	return msg, nil
+}
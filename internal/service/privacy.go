@@ -0,0 +1,69 @@
+package service
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const MaxAuditRecordsListed = 500
+
+// returns everything stored about a profile, the export is audited under actor
+func (s *ProfileService) ExportPersonalData(profileID string, actor *model.User) (*model.PersonalData, error) {
+	data, err := s.repos.Profiles.ExportPersonalData(profileID, actorID(actor))
+	if err != nil {
+		return nil, notFoundAs(err, "Profile not found")
+	}
+	return data, nil
+}
+
+// anonymizes a profile for good, deleted profiles too since they are kept
+// until purged. confirm has to repeat the profile's email, so a mistyped ID
+// cannot erase someone else.
+func (s *ProfileService) Erase(profileID string, confirm string, actor *model.User) (*model.Profile, error) {
+	profile, err := s.repos.Profiles.GetByIDWithDeleted(profileID)
+	if err != nil {
+		return nil, notFoundAs(err, "Profile not found")
+	}
+	if profile.ErasedAt != nil {
+		return nil, Conflict("Profile is already erased")
+	}
+	if !strings.EqualFold(strings.TrimSpace(confirm), profile.Email) {
+		return nil, InvalidField("confirm", "Enter the email of the profile to confirm the erasure")
+	}
+
+	erased, err := s.repos.Profiles.Erase(profileID, actorID(actor))
+	if err != nil {
+		return nil, notFoundAs(err, "Profile not found")
+	}
+	return erased, nil
+}
+
+type AuditService struct {
+	repos *repository.Repositories
+}
+
+// lists the most recent audit records, action and entityID are optional
+func (s *AuditService) List(action string, entityID string, limit int) ([]model.AuditRecord, error) {
+	filter := model.AuditFilter{Action: model.AuditAction(action), Limit: limit}
+	if entityID != "" {
+		id, err := uuid.Parse(entityID)
+		if err != nil {
+			return nil, InvalidField("entity_id", "Invalid entity ID format")
+		}
+		filter.EntityID = &id
+	}
+	if limit <= 0 || limit > MaxAuditRecordsListed {
+		return nil, InvalidField("limit", "Limit must be between 1 and %d", MaxAuditRecordsListed)
+	}
+	return s.repos.Audit.List(filter)
+}
+
+func actorID(actor *model.User) *uuid.UUID {
+	if actor == nil {
+		return nil
+	}
+	return &actor.ID
+}
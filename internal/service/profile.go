@@ -0,0 +1,153 @@
+package service
+
+import (
+	"counterapp/internal/crypt"
+	"counterapp/internal/model"
+	"counterapp/internal/notify"
+	"counterapp/internal/repository"
+	"errors"
+	"strings"
+)
+
+type ProfileService struct {
+	repos *repository.Repositories
+}
+
+func (s *ProfileService) List() ([]model.ProfileOverview, error) {
+	return s.repos.Profiles.GetProfilesData()
+}
+
+func (s *ProfileService) Get(profileID string) (*model.Profile, error) {
+	profile, err := s.repos.Profiles.GetByID(profileID)
+	if err != nil {
+		return nil, notFoundAs(err, "Profile not found")
+	}
+	return profile, nil
+}
+
+// returns nil without error when no profile has the email
+func (s *ProfileService) FindByEmail(email string) (*model.Profile, error) {
+	return s.repos.Profiles.GetByEmail(email)
+}
+
+func (s *ProfileService) Create(profile *model.Profile) (*model.Profile, error) {
+	if err := checkPhoneNumber(profile.PhoneNumber); err != nil {
+		return nil, err
+	}
+	created, err := s.repos.Profiles.Create(profile)
+	if err != nil {
+		return nil, conflictAs(err, "A profile with this email already exists")
+	}
+	return created, nil
+}
+
+func (s *ProfileService) Update(profileID string, updates *model.ProfileUpdate) (*model.Profile, error) {
+	if updates.NotificationChannel != nil && !updates.NotificationChannel.IsValid() {
+		return nil, InvalidField("notification_channel", "Invalid notification channel, use email, sms or whatsapp")
+	}
+	if updates.PhoneNumber != nil {
+		if err := checkPhoneNumber(*updates.PhoneNumber); err != nil {
+			return nil, err
+		}
+	}
+
+	profile, err := s.repos.Profiles.Update(profileID, updates)
+	if err != nil {
+		return nil, notFoundAs(err, "Profile not found")
+	}
+	return profile, nil
+}
+
+// SMS and WhatsApp messages go to the phone number, so it has to be one the
+// gateways accept. Leaving it blank is fine.
+func checkPhoneNumber(phone string) error {
+	if strings.TrimSpace(phone) == "" {
+		return nil
+	}
+	if _, err := notify.NormalizePhone(phone); err != nil {
+		return InvalidField("phone_number", "Invalid phone number, use international format like +919845012345")
+	}
+	return nil
+}
+
+// the medical info of the profile, nil when nothing is noted
+func (s *ProfileService) Medical(profileID string) (*model.MedicalInfo, error) {
+	profile, err := s.Get(profileID)
+	if err != nil {
+		return nil, err
+	}
+	return profile.Medical, nil
+}
+
+// replaces the medical info of the profile, blank fields are cleared
+func (s *ProfileService) SetMedical(profileID string, medical *model.MedicalInfo) (*model.MedicalInfo, error) {
+	medical = trimMedical(medical)
+	if err := checkMedicalStorable(medical); err != nil {
+		return nil, err
+	}
+
+	profile, err := s.repos.Profiles.SetMedical(profileID, medical)
+	if err != nil {
+		return nil, notFoundAs(err, "Profile not found")
+	}
+	return profile.Medical, nil
+}
+
+// drops blank fields, nil when nothing is left
+func trimMedical(medical *model.MedicalInfo) *model.MedicalInfo {
+	if medical == nil {
+		return nil
+	}
+	trim := func(field *string) *string {
+		if field == nil || strings.TrimSpace(*field) == "" {
+			return nil
+		}
+		trimmed := strings.TrimSpace(*field)
+		return &trimmed
+	}
+	trimmed := &model.MedicalInfo{Notes: trim(medical.Notes), DietaryNeeds: trim(medical.DietaryNeeds)}
+	if trimmed.IsEmpty() {
+		return nil
+	}
+	return trimmed
+}
+
+// medical info is only stored encrypted, clearing it works without a key
+func checkMedicalStorable(medical *model.MedicalInfo) error {
+	if medical != nil && !crypt.Enabled() {
+		return Conflict("Medical info cannot be stored until database.encryption_key is configured")
+	}
+	return nil
+}
+
+// soft deletes the profile with its visits, schedules and feedback, they can
+// be restored until the profile is purged
+func (s *ProfileService) Delete(profileID string) error {
+	if err := s.repos.Profiles.Delete(profileID); err != nil {
+		return notFoundAs(err, "Profile not found")
+	}
+	return nil
+}
+
+func (s *ProfileService) Restore(profileID string) (*model.Profile, error) {
+	profile, err := s.repos.Profiles.Restore(profileID)
+	if err != nil {
+		return nil, conflictAs(notFoundAs(err, "Deleted profile not found"), "Another profile has this email now")
+	}
+	return profile, nil
+}
+
+// removes a profile and all its records for good. Only deleted profiles can
+// be purged, so nothing disappears in one step.
+func (s *ProfileService) Purge(profileID string) error {
+	err := s.repos.Profiles.Purge(profileID)
+	if errors.Is(err, repository.ErrNotFound) {
+		if _, getErr := s.repos.Profiles.GetByID(profileID); getErr == nil {
+			return Conflict("Delete the profile before purging it")
+		}
+	}
+	if err != nil {
+		return notFoundAs(err, "Deleted profile not found")
+	}
+	return nil
+}
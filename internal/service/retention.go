@@ -0,0 +1,32 @@
+package service
+
+import (
+	"counterapp/internal/config"
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+	"time"
+)
+
+type RetentionService struct {
+	repos *repository.Repositories
+}
+
+// what the rules of cfg would change if they ran at now, whether or not the
+// background job is enabled
+func (s *RetentionService) Report(cfg config.Retention, now time.Time) (*model.RetentionReport, error) {
+	return s.repos.Retention.Plan(retentionPolicy(cfg), now)
+}
+
+// runs the rules of cfg at now, every row changed is audited without an actor
+func (s *RetentionService) Apply(cfg config.Retention, now time.Time) (*model.RetentionReport, error) {
+	return s.repos.Retention.Apply(retentionPolicy(cfg), now)
+}
+
+func retentionPolicy(cfg config.Retention) model.RetentionPolicy {
+	return model.RetentionPolicy{
+		InactiveProfileYears: cfg.InactiveProfileYears,
+		RemarksMonths:        cfg.RemarksMonths,
+		FeedbackMonths:       cfg.FeedbackMonths,
+		PurgeDeletedDays:     cfg.PurgeDeletedDays,
+	}
+}
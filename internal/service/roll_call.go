@@ -0,0 +1,75 @@
+package service
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+	"counterapp/internal/util"
+	"time"
+)
+
+const MaxRollCallsListed = 500
+
+type RollCallService struct {
+	repos *repository.Repositories
+}
+
+// lists everyone checked in at now, by stay area and name, with the seva
+// they have that day
+func (s *RollCallService) OnSite(now time.Time) ([]model.RollCallEntry, error) {
+	return s.repos.RollCalls.OnSite(util.Day(now))
+}
+
+// starts a roll call of everyone checked in at now
+func (s *RollCallService) Start(note *string, actor *model.User, now time.Time) (*model.RollCall, error) {
+	return s.repos.RollCalls.Start(note, actorID(actor), util.Day(now))
+}
+
+func (s *RollCallService) List(limit int) ([]model.RollCallSummary, error) {
+	if limit <= 0 || limit > MaxRollCallsListed {
+		return nil, InvalidField("limit", "Limit must be between 1 and %d", MaxRollCallsListed)
+	}
+	return s.repos.RollCalls.List(limit)
+}
+
+func (s *RollCallService) Get(id string) (*model.RollCall, error) {
+	rollCall, err := s.repos.RollCalls.GetByID(id)
+	if err != nil {
+		return nil, notFoundAs(err, "Roll call not found")
+	}
+	return rollCall, nil
+}
+
+// marks a person as accounted for by actor, or clears the mark when it was
+// set by mistake. Closed roll calls cannot be changed.
+func (s *RollCallService) Mark(rollCallID string, entryID string, accounted bool, actor *model.User) (*model.RollCallEntry, error) {
+	rollCall, err := s.Get(rollCallID)
+	if err != nil {
+		return nil, err
+	}
+	if rollCall.ClosedAt != nil {
+		return nil, Conflict("Roll call is closed")
+	}
+
+	entry, err := s.repos.RollCalls.MarkEntry(rollCallID, entryID, accounted, actorID(actor))
+	if err != nil {
+		return nil, notFoundAs(err, "Roll call entry not found")
+	}
+	return entry, nil
+}
+
+// ends the roll call, it is kept for review
+func (s *RollCallService) Close(id string) (*model.RollCall, error) {
+	rollCall, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if rollCall.ClosedAt != nil {
+		return nil, Conflict("Roll call is already closed")
+	}
+
+	closed, err := s.repos.RollCalls.Close(id)
+	if err != nil {
+		return nil, notFoundAs(err, "Roll call not found")
+	}
+	return closed, nil
+}
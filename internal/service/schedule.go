@@ -0,0 +1,79 @@
+package service
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/notify"
+	"counterapp/internal/repository"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ScheduleService struct {
+	repos  *repository.Repositories
+	logger *slog.Logger
+}
+
+func (s *ScheduleService) ListForDateRange(startDate time.Time, endDate time.Time) ([]model.Schedule, error) {
+	if endDate.Before(startDate) {
+		return nil, InvalidField("end_date", "Start date cannot be after end date")
+	}
+	return s.repos.Schedules.GetForDateRange(startDate, endDate)
+}
+
+type AssignRequest struct {
+	ProfileID uuid.UUID
+	VisitID   uuid.UUID
+	SevaType  string
+	Location  *string
+	Date      time.Time
+}
+
+// assigns seva for a day. The visit has to belong to the profile and be
+// checked in, and a profile gets at most one assignment per day.
+func (s *ScheduleService) Assign(req AssignRequest) (*model.Schedule, error) {
+	visit, err := s.repos.Visits.GetByID(req.VisitID.String())
+	if err != nil {
+		return nil, notFoundAs(err, "Visit not found")
+	}
+	if visit.ProfileID != req.ProfileID {
+		return nil, InvalidField("visit_id", "Visit does not belong to the profile")
+	}
+	if visit.Status != model.StatusCheckedIn {
+		return nil, InvalidField("visit_id", "Visit is not active")
+	}
+
+	existing, err := s.repos.Schedules.GetByProfileAndDate(req.ProfileID.String(), req.Date)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, Conflict("Schedule already exists for this date")
+	}
+
+	sevaType, err := s.repos.SevaTypes.GetByName(req.SevaType)
+	if err != nil {
+		return nil, validationAs(err, "seva_type", "Invalid seva type or seva type not found")
+	}
+
+	schedule, err := s.repos.Schedules.Add(model.AddScheduleRequest{
+		ProfileID:  req.ProfileID,
+		VisitID:    req.VisitID,
+		SevaTypeID: sevaType.ID,
+		Location:   req.Location,
+		Date:       req.Date,
+	})
+	if err != nil {
+		// a concurrent assignment won the unique index
+		return nil, conflictAs(err, "Schedule already exists for this date")
+	}
+
+	if profile, err := s.repos.Profiles.GetByID(req.ProfileID.String()); err == nil {
+		err = notify.EnqueueScheduleAssignment(s.repos.Notifications, profile, schedule, sevaType)
+		if err != nil {
+			s.logger.Warn("unable to queue schedule assignment", "schedule_id", schedule.ID, "error", err)
+		}
+	}
+	return schedule, nil
+}
@@ -0,0 +1,90 @@
+// Package service owns the business rules of the app. The HTTP handlers, the
+// CLI and background jobs call it instead of the repositories so every entry
+// point enforces the same rules.
+package service
+
+import (
+	"counterapp/internal/repository"
+	"errors"
+	"log/slog"
+)
+
+type Services struct {
+	Profiles      *ProfileService
+	Visits        *VisitService
+	Schedules     *ScheduleService
+	Feedbacks     *FeedbackService
+	SevaTypes     *SevaTypeService
+	StayAreas     *StayAreaService
+	Lockers       *LockerService
+	Notifications *NotificationService
+	Webhooks      *WebhookService
+	Users         *UserService
+	Audit         *AuditService
+	RollCalls     *RollCallService
+	Retention     *RetentionService
+	Exports       *ExportService
+	Health        *HealthService
+
+	repos  *repository.Repositories
+	logger *slog.Logger
+}
+
+// logger receives the failures that do not fail the call, such as a
+// confirmation that could not be queued
+func New(repos *repository.Repositories, logger *slog.Logger) *Services {
+	return &Services{
+		Profiles:      &ProfileService{repos: repos},
+		Visits:        &VisitService{repos: repos, logger: logger},
+		Schedules:     &ScheduleService{repos: repos, logger: logger},
+		Feedbacks:     &FeedbackService{repos: repos},
+		SevaTypes:     &SevaTypeService{repos: repos},
+		StayAreas:     &StayAreaService{repos: repos},
+		Lockers:       &LockerService{repos: repos},
+		Notifications: &NotificationService{repos: repos},
+		Webhooks:      &WebhookService{repos: repos},
+		Users:         &UserService{repos: repos},
+		Audit:         &AuditService{repos: repos},
+		RollCalls:     &RollCallService{repos: repos},
+		Retention:     &RetentionService{repos: repos},
+		Exports:       &ExportService{repos: repos},
+		Health:        &HealthService{repos: repos},
+
+		repos:  repos,
+		logger: logger,
+	}
+}
+
+// runs fn with services whose changes are all kept when fn returns nil and
+// all discarded otherwise, for callers applying several changes at once
+func (s *Services) Transaction(fn func(tx *Services) error) error {
+	return s.repos.Transactions.Run(func(repos *repository.Repositories) error {
+		return fn(New(repos, s.logger))
+	})
+}
+
+// turns a repository not found into a NotFound with message, other errors
+// are returned as they are
+func notFoundAs(err error, message string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return &Error{Kind: KindNotFound, Message: message, Err: err}
+	}
+	return err
+}
+
+// turns a repository duplicate into a Conflict with message
+func conflictAs(err error, message string) error {
+	if errors.Is(err, repository.ErrDuplicate) {
+		return &Error{Kind: KindConflict, Message: message, Err: err}
+	}
+	return err
+}
+
+// turns a repository not found into a Validation of field, for references
+// given by name in the request
+func validationAs(err error, field string, message string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return &Error{Kind: KindValidation, Message: message, Details: []FieldError{{Field: field, Message: message}}, Err: err}
+	}
+	return err
+}
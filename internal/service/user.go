@@ -0,0 +1,91 @@
+package service
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/mail"
+	"strings"
+)
+
+const (
+	apiTokenPrefix = "cat_"
+	apiTokenBytes  = 32
+)
+
+type UserService struct {
+	repos *repository.Repositories
+}
+
+// creates a user with the admin role. The API token is returned separately
+// since only its hash is stored, it cannot be shown again.
+func (s *UserService) CreateAdmin(name string, email string) (*model.User, string, error) {
+	return s.create(name, email, model.RoleAdmin)
+}
+
+// creates a user with role, admin or staff. Like CreateAdmin the API token
+// is returned separately.
+func (s *UserService) Create(name string, email string, role model.Role) (*model.User, string, error) {
+	if !role.IsValid() {
+		return nil, "", InvalidField("role", "Role must be %s or %s", model.RoleAdmin, model.RoleStaff)
+	}
+	return s.create(name, email, role)
+}
+
+func (s *UserService) create(name string, email string, role model.Role) (*model.User, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", InvalidField("name", "Name is required")
+	}
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, "", InvalidField("email", "Invalid email address")
+	}
+
+	token, err := generateAPIToken()
+	if err != nil {
+		return nil, "", err
+	}
+	user, err := s.repos.Users.Create(&model.User{
+		Name:      name,
+		Email:     email,
+		Role:      role,
+		TokenHash: hashAPIToken(token),
+	})
+	if err != nil {
+		return nil, "", conflictAs(err, "A user with this email already exists")
+	}
+	return user, token, nil
+}
+
+// returns the user the API token belongs to
+func (s *UserService) Authenticate(token string) (*model.User, error) {
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		return nil, Unauthorized("Invalid API token")
+	}
+	user, err := s.repos.Users.GetByTokenHash(hashAPIToken(token))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, Unauthorized("Invalid API token")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func generateAPIToken() (string, error) {
+	buf := make([]byte, apiTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return apiTokenPrefix + hex.EncodeToString(buf), nil
+}
+
+// tokens are random, so a plain hash is enough to keep them out of the
+// database
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
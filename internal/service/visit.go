@@ -0,0 +1,254 @@
+package service
+
+import (
+	"counterapp/internal/model"
+	"counterapp/internal/notify"
+	"counterapp/internal/repository"
+	"counterapp/internal/util"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type VisitService struct {
+	repos  *repository.Repositories
+	logger *slog.Logger
+}
+
+func (s *VisitService) ListForProfile(profileID string) ([]model.Visit, error) {
+	return s.repos.Visits.GetByProfileID(profileID)
+}
+
+// returns the visit with its profile, stay area and locker
+func (s *VisitService) GetDetails(visitID string) (*model.Visit, error) {
+	visit, err := s.repos.Visits.GetDetailsByID(visitID)
+	if err != nil {
+		return nil, notFoundAs(err, "Visit not found")
+	}
+	return visit, nil
+}
+
+// returns nil without error when the profile has no visit arriving then
+func (s *VisitService) GetByArrival(profileID string, arrivalDate time.Time) (*model.Visit, error) {
+	return s.repos.Visits.GetByProfileAndArrivalDate(profileID, arrivalDate)
+}
+
+type PlanVisitRequest struct {
+	ProfileID     uuid.UUID
+	ArrivalDate   time.Time
+	DepartureDate *time.Time
+	StayAreaID    uuid.UUID
+}
+
+// adds a pending visit for a profile expected to arrive, one per arrival
+// date. Nothing is checked out, that happens when the person checks in.
+func (s *VisitService) Plan(req PlanVisitRequest) (*model.Visit, error) {
+	if _, err := s.repos.Profiles.GetByID(req.ProfileID.String()); err != nil {
+		return nil, notFoundAs(err, "Profile not found")
+	}
+	if req.DepartureDate != nil && req.DepartureDate.Before(req.ArrivalDate) {
+		return nil, InvalidField("departure_date", "Departure date cannot be before arrival date")
+	}
+	if _, err := s.repos.StayAreas.GetByID(req.StayAreaID.String()); err != nil {
+		return nil, notFoundAs(err, "Stay area not found")
+	}
+	existing, err := s.GetByArrival(req.ProfileID.String(), req.ArrivalDate)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, Conflict("Profile already has a visit arriving on this date")
+	}
+
+	return s.repos.Visits.Add(model.AddVisitRequest{
+		ProfileID:     req.ProfileID,
+		ArrivalDate:   req.ArrivalDate,
+		DepartureDate: req.DepartureDate,
+		StayAreaID:    req.StayAreaID,
+		Status:        model.StatusPending,
+	})
+}
+
+type CheckInRequest struct {
+	ProfileID     uuid.UUID
+	ArrivalDate   time.Time
+	DepartureDate *time.Time
+	StayAreaID    uuid.UUID
+
+	// replace what the profile has when set, so the desk can confirm them
+	// with the person while checking them in
+	EmergencyContacts *[]model.EmergencyContact
+	Medical           *model.MedicalInfo
+}
+
+// checks a profile in to a stay area. Visits of the profile that are still
+// checked in are checked out first since a person is only in one place.
+// Blocked profiles cannot be checked in.
+func (s *VisitService) CheckIn(req CheckInRequest) (*model.Visit, error) {
+	profile, err := s.repos.Profiles.GetByID(req.ProfileID.String())
+	if err != nil {
+		return nil, notFoundAs(err, "Profile not found")
+	}
+	if profile.IsBlocked {
+		return nil, Forbidden("Profile is blocked and cannot be checked in")
+	}
+	if req.DepartureDate != nil && req.DepartureDate.Before(req.ArrivalDate) {
+		return nil, InvalidField("departure_date", "Departure date cannot be before arrival date")
+	}
+	if _, err := s.repos.StayAreas.GetByID(req.StayAreaID.String()); err != nil {
+		return nil, notFoundAs(err, "Stay area not found")
+	}
+	medical := trimMedical(req.Medical)
+	if err := checkMedicalStorable(medical); err != nil {
+		return nil, err
+	}
+
+	// the confirmed contacts and medical info, the check-out of earlier
+	// visits and the new visit are kept together or not at all
+	var visit *model.Visit
+	err = s.repos.Transactions.Run(func(repos *repository.Repositories) error {
+		if req.EmergencyContacts != nil {
+			_, err := repos.Profiles.Update(profile.ID.String(), &model.ProfileUpdate{EmergencyContacts: req.EmergencyContacts})
+			if err != nil {
+				return notFoundAs(err, "Profile not found")
+			}
+		}
+		if req.Medical != nil {
+			if _, err := repos.Profiles.SetMedical(profile.ID.String(), medical); err != nil {
+				return notFoundAs(err, "Profile not found")
+			}
+		}
+
+		if err := checkOutActiveVisits(repos, profile.ID); err != nil {
+			return err
+		}
+
+		added, err := repos.Visits.Add(model.AddVisitRequest{
+			ProfileID:     req.ProfileID,
+			ArrivalDate:   req.ArrivalDate,
+			DepartureDate: req.DepartureDate,
+			StayAreaID:    req.StayAreaID,
+			Status:        model.StatusCheckedIn,
+		})
+		if err != nil {
+			// a concurrent check-in of the same profile won the unique index
+			return conflictAs(err, "Profile is already checked in")
+		}
+		visit = added
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if details, err := s.repos.Visits.GetByID(visit.ID.String()); err == nil {
+		err = notify.EnqueueVisitConfirmation(s.repos.Notifications, profile, details)
+		if err != nil {
+			s.logger.Warn("unable to queue visit confirmation", "visit_id", visit.ID, "error", err)
+		}
+	}
+	return visit, nil
+}
+
+func checkOutActiveVisits(repos *repository.Repositories, profileID uuid.UUID) error {
+	visits, err := repos.Visits.GetByProfileID(profileID.String())
+	if err != nil {
+		return err
+	}
+	for _, visit := range visits {
+		if visit.Status != model.StatusCheckedIn {
+			continue
+		}
+		checkedOutStatus := model.StatusCheckedOut
+		_, err = repos.Visits.Update(visit.ID.String(), model.UpdateVisitRequest{
+			Status: &checkedOutStatus,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *VisitService) Update(visitID string, req model.UpdateVisitRequest) (*model.Visit, error) {
+	if req.Status != nil && !req.Status.IsValid() {
+		return nil, InvalidField("status", "Invalid status, use checked-in, pending or checked-out")
+	}
+
+	visit, err := s.repos.Visits.GetByID(visitID)
+	if err != nil {
+		return nil, notFoundAs(err, "Visit not found")
+	}
+	if req.DepartureDate != nil && req.DepartureDate.Before(visit.ArrivalDate) {
+		return nil, InvalidField("departure_date", "Departure date cannot be before arrival date")
+	}
+	if req.StayAreaID != nil {
+		if _, err := s.repos.StayAreas.GetByID(req.StayAreaID.String()); err != nil {
+			return nil, notFoundAs(err, "Stay area not found")
+		}
+	}
+
+	updated, err := s.repos.Visits.Update(visitID, req)
+	if err != nil {
+		return nil, conflictAs(notFoundAs(err, "Visit not found"), "Profile already has a checked-in visit")
+	}
+	return updated, nil
+}
+
+// checks out every visit still checked in after its departure date, today
+// being the first day that is not overdue. Returns the visits checked out.
+func (s *VisitService) CheckOutOverdue(today time.Time) ([]model.Visit, error) {
+	overdue, err := s.repos.Visits.GetOverdue(util.Day(today))
+	if err != nil {
+		return nil, err
+	}
+
+	checkedOutStatus := model.StatusCheckedOut
+	checkedOut := make([]model.Visit, 0, len(overdue))
+	for _, visit := range overdue {
+		updated, err := s.repos.Visits.Update(visit.ID.String(), model.UpdateVisitRequest{Status: &checkedOutStatus})
+		if err != nil {
+			return checkedOut, err
+		}
+		checkedOut = append(checkedOut, *updated)
+	}
+	return checkedOut, nil
+}
+
+// soft deletes the visit with its schedules and the feedback given on it,
+// they can be restored until the visit is purged
+func (s *VisitService) Delete(visitID string) error {
+	if err := s.repos.Visits.Delete(visitID); err != nil {
+		return notFoundAs(err, "Visit not found")
+	}
+	return nil
+}
+
+func (s *VisitService) Restore(visitID string) (*model.Visit, error) {
+	visit, err := s.repos.Visits.Restore(visitID)
+	if errors.Is(err, repository.ErrParentDeleted) {
+		return nil, Conflict("The profile of this visit is deleted, restore the profile instead")
+	}
+	if err != nil {
+		err = notFoundAs(err, "Deleted visit not found")
+		return nil, conflictAs(err, "Profile has checked in again or has a seva on a day of this visit")
+	}
+	return visit, nil
+}
+
+// removes a visit with its schedules and feedback for good. Only deleted
+// visits can be purged.
+func (s *VisitService) Purge(visitID string) error {
+	err := s.repos.Visits.Purge(visitID)
+	if errors.Is(err, repository.ErrNotFound) {
+		if _, getErr := s.repos.Visits.GetByID(visitID); getErr == nil {
+			return Conflict("Delete the visit before purging it")
+		}
+	}
+	if err != nil {
+		return notFoundAs(err, "Deleted visit not found")
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"counterapp/internal/events"
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+)
+
+const (
+	MaxWebhookDeliveriesListed = 500
+	webhookSecretBytes         = 32
+)
+
+type WebhookService struct {
+	repos *repository.Repositories
+}
+
+func (s *WebhookService) List() ([]model.WebhookSubscription, error) {
+	return s.repos.Webhooks.GetAllSubscriptions()
+}
+
+type SubscribeRequest struct {
+	URL        string
+	EventTypes []string
+	// generated when empty
+	Secret string
+}
+
+// subscribes a URL to event types. The secret is returned separately since
+// the subscription never serializes it.
+func (s *WebhookService) Subscribe(req SubscribeRequest) (*model.WebhookSubscription, string, error) {
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, "", InvalidField("url", "Invalid webhook URL, expected an http or https URL")
+	}
+
+	if len(req.EventTypes) == 0 {
+		return nil, "", InvalidField("event_types", "At least one event type is required")
+	}
+	for _, t := range req.EventTypes {
+		if !events.Type(t).IsValid() {
+			return nil, "", InvalidField("event_types", "Unknown event type: %s", t)
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret, err = generateWebhookSecret()
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	subscription, err := s.repos.Webhooks.AddSubscription(model.AddWebhookSubscriptionRequest{
+		URL:        req.URL,
+		Secret:     secret,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return subscription, secret, nil
+}
+
+// stops new deliveries for the subscription, its delivery log is kept
+func (s *WebhookService) Unsubscribe(id string) error {
+	return notFoundAs(s.repos.Webhooks.DeactivateSubscription(id), "Webhook not found")
+}
+
+func (s *WebhookService) Deliveries(subscriptionID string, limit int) ([]model.WebhookDelivery, error) {
+	if limit <= 0 || limit > MaxWebhookDeliveriesListed {
+		return nil, InvalidField("limit", "Limit must be between 1 and %d", MaxWebhookDeliveriesListed)
+	}
+	return s.repos.Webhooks.GetDeliveries(subscriptionID, limit)
+}
+
+func (s *WebhookService) Redeliver(deliveryID string) (*model.WebhookDelivery, error) {
+	delivery, err := s.repos.Webhooks.Redeliver(deliveryID)
+	if err != nil {
+		return nil, notFoundAs(err, "Delivery not found")
+	}
+	return delivery, nil
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,141 @@
+// Package testdb provides the PostgreSQL the integration tests run against:
+// the database named by COUNTERAPP_TEST_DSN, or an embedded server started
+// for the test run. Tests get a transaction each that is rolled back when they
+// end, so they never see each other's rows.
+package testdb
+
+import (
+	"counterapp/internal/migrations"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// a libpq connection string or URL of an existing database to test against
+// instead of the embedded server. Its schema is migrated up, rows the tests
+// write are rolled back.
+const DSNEnv = "COUNTERAPP_TEST_DSN"
+
+// the embedded server is downloaded once into the embedded-postgres cache,
+// ~/.embedded-postgres-go
+const embeddedVersion = embeddedpostgres.V16
+
+// ErrUnavailable is returned by Start when no DSN is set and the embedded
+// server cannot be started, e.g. without network access for the first
+// download or when running as root, which PostgreSQL refuses
+var ErrUnavailable = errors.New("no PostgreSQL available for integration tests")
+
+type Server struct {
+	DB  *gorm.DB
+	DSN string
+
+	embedded *embeddedpostgres.EmbeddedPostgres
+	dir      string
+}
+
+// connects to the database named by COUNTERAPP_TEST_DSN or starts the
+// embedded server, and applies every migration
+func Start() (*Server, error) {
+	s := &Server{}
+	dsn := os.Getenv(DSNEnv)
+	if dsn == "" {
+		var err error
+		if dsn, err = s.startEmbedded(); err != nil {
+			s.Close()
+			return nil, fmt.Errorf("%w: set %s or allow the embedded server to start: %v", ErrUnavailable, DSNEnv, err)
+		}
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Discard,
+	})
+	if err != nil {
+		s.Close()
+		return nil, fmt.Errorf("connecting to test database: %w", err)
+	}
+	s.DB = db
+	s.DSN = dsn
+
+	if _, err := migrations.Up(db); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("migrating test database: %w", err)
+	}
+	return s, nil
+}
+
+func (s *Server) startEmbedded() (string, error) {
+	port, err := freePort()
+	if err != nil {
+		return "", err
+	}
+	s.dir, err = os.MkdirTemp("", "counterapp-testdb-")
+	if err != nil {
+		return "", err
+	}
+
+	config := embeddedpostgres.DefaultConfig().
+		Version(embeddedVersion).
+		Port(port).
+		Database("counterapp_test").
+		RuntimePath(filepath.Join(s.dir, "runtime")).
+		DataPath(filepath.Join(s.dir, "data")).
+		StartTimeout(time.Minute).
+		Logger(io.Discard)
+	embedded := embeddedpostgres.NewDatabase(config)
+	if err := embedded.Start(); err != nil {
+		return "", err
+	}
+	s.embedded = embedded
+	return config.GetConnectionURL() + "?sslmode=disable", nil
+}
+
+// closes the connections and stops the embedded server
+func (s *Server) Close() error {
+	var errs []error
+	if s.DB != nil {
+		if sqlDB, err := s.DB.DB(); err == nil {
+			errs = append(errs, sqlDB.Close())
+		}
+	}
+	if s.embedded != nil {
+		errs = append(errs, s.embedded.Stop())
+	}
+	if s.dir != "" {
+		errs = append(errs, os.RemoveAll(s.dir))
+	}
+	return errors.Join(errs...)
+}
+
+// begins a transaction that is rolled back when t ends. The app's own
+// transactions run inside it as savepoints.
+func (s *Server) Tx(t testing.TB) *gorm.DB {
+	t.Helper()
+	tx := s.DB.Begin()
+	if tx.Error != nil {
+		t.Fatalf("beginning test transaction: %v", tx.Error)
+	}
+	t.Cleanup(func() {
+		tx.Rollback()
+	})
+	return tx
+}
+
+func freePort() (uint32, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return uint32(l.Addr().(*net.TCPAddr).Port), nil
+}
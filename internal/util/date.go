@@ -22,3 +22,9 @@ func CompareDates(date1 time.Time, date2 time.Time) bool {
 func FormatDate(date time.Time) string {
 	return date.Format("2006-01-02")
 }
+
+// the calendar day of date in its own location, as midnight UTC like the
+// dates stored for visits and schedules
+func Day(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,50 @@
+package util
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	minE164Digits = 8
+	maxE164Digits = 15
+)
+
+// normalizes free text phone numbers such as "098450 12345", "+91-98450-12345"
+// or "0091 9845012345" to E.164 (+919845012345). Numbers without a country
+// code get defaultCountryCode, given without the leading +.
+func NormalizePhoneE164(raw string, defaultCountryCode string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", errors.New("phone number is empty")
+	}
+
+	international := strings.HasPrefix(raw, "+")
+	var digits strings.Builder
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '/' || r == '+':
+		default:
+			return "", errors.New("phone number contains invalid characters")
+		}
+	}
+	number := digits.String()
+
+	switch {
+	case international:
+	case strings.HasPrefix(number, "00"):
+		number = strings.TrimPrefix(number, "00")
+	default:
+		number = strings.TrimLeft(number, "0")
+		if !strings.HasPrefix(number, defaultCountryCode) || len(number) <= len(defaultCountryCode)+minE164Digits {
+			number = defaultCountryCode + number
+		}
+	}
+
+	if len(number) < minE164Digits || len(number) > maxE164Digits || number[0] == '0' {
+		return "", errors.New("phone number has an invalid length")
+	}
+	return "+" + number, nil
+}
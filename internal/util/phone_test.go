@@ -0,0 +1,45 @@
+package util_test
+
+import (
+	"counterapp/internal/util"
+	"testing"
+)
+
+func TestNormalizePhoneE164(t *testing.T) {
+	cases := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr string
+	}{
+		{"international", "+91-98450-12345", "+919845012345", ""},
+		{"international with spaces", " +44 (20) 7946 0958 ", "+442079460958", ""},
+		{"double zero prefix", "0091 9845012345", "+919845012345", ""},
+		{"trunk zero", "098450 12345", "+919845012345", ""},
+		{"local number", "98450.12345", "+919845012345", ""},
+		{"country code without plus", "919845012345", "+919845012345", ""},
+		{"local number starting like the country code", "9198450123", "+919198450123", ""},
+		{"empty", "   ", "", "phone number is empty"},
+		{"letters", "98450 CALLME", "", "phone number contains invalid characters"},
+		{"extension", "+91 98450 12345 ext 2", "", "phone number contains invalid characters"},
+		{"too short", "12345", "", "phone number has an invalid length"},
+		{"too short international", "+91 12", "", "phone number has an invalid length"},
+		{"too long", "+91 98450 12345 67890", "", "phone number has an invalid length"},
+		{"country code starting with zero", "+0 9845012345", "", "phone number has an invalid length"},
+		{"only separators", "+ - ()", "", "phone number has an invalid length"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := util.NormalizePhoneE164(tc.raw, "91")
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("NormalizePhoneE164(%q) = %q, %v, want error %q", tc.raw, got, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Fatalf("NormalizePhoneE164(%q) = %q, %v, want %q", tc.raw, got, err, tc.want)
+			}
+		})
+	}
+}
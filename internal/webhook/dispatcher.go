@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"counterapp/internal/dao"
+	"counterapp/internal/model"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign returns the signature sent in HeaderSignature. Receivers recompute
+// it from the raw body and HeaderTimestamp with their secret and compare:
+//
+//	sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type DispatcherOptions struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	// first retry delay, doubled after every failed attempt up to MaxBackoff
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+	Timeout      time.Duration
+	// how long a claimed batch is kept from other dispatchers, see
+	// notify.SenderOptions
+	Lease  time.Duration
+	Logger *slog.Logger
+}
+
+func (o DispatcherOptions) withDefaults() DispatcherOptions {
+	if o.PollInterval <= 0 {
+		o.PollInterval = 5 * time.Second
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 20
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 8
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = 30 * time.Second
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 6 * time.Hour
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.Lease <= 0 {
+		o.Lease = time.Duration(o.BatchSize)*o.Timeout + time.Minute
+	}
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	return o
+}
+
+// Dispatcher delivers pending webhook deliveries in the background
+type Dispatcher struct {
+	db     *gorm.DB
+	client *http.Client
+	opts   DispatcherOptions
+}
+
+func NewDispatcher(db *gorm.DB, opts DispatcherOptions) *Dispatcher {
+	opts = opts.withDefaults()
+	return &Dispatcher{
+		db:     db,
+		client: &http.Client{Timeout: opts.Timeout},
+		opts:   opts,
+	}
+}
+
+// Run polls for due deliveries until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DeliverDue(ctx); err != nil {
+			d.opts.Logger.Error("error while delivering webhooks", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue sends every delivery that is due, one batch at a time
+func (d *Dispatcher) DeliverDue(ctx context.Context) error {
+	for {
+		processed, err := d.deliverBatch(ctx)
+		if err != nil || processed < d.opts.BatchSize || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// claims a batch and posts it outside of any transaction, recording each
+// result on its own
+func (d *Dispatcher) deliverBatch(ctx context.Context) (int, error) {
+	now := time.Now()
+	deliveries, err := dao.ClaimDueWebhookDeliveries(d.db, now, now.Add(d.opts.Lease), d.opts.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		statusCode, deliveryErr := d.deliver(ctx, delivery)
+		if deliveryErr == nil {
+			err = dao.MarkWebhookDeliverySent(d.db, delivery.ID.String(), statusCode, time.Now())
+		} else {
+			var code *int
+			if statusCode != 0 {
+				code = &statusCode
+			}
+			attempt := delivery.Attempts + 1
+			failed := attempt >= d.opts.MaxAttempts || !delivery.Subscription.IsActive
+			next := time.Now().Add(d.backoff(attempt))
+			err = dao.MarkWebhookDeliveryAttemptFailed(d.db, delivery.ID.String(), code, deliveryErr.Error(), next, failed)
+		}
+		if err != nil {
+			return len(deliveries), err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// posts the payload and returns the response status, 0 when the request
+// did not get a response
+func (d *Dispatcher) deliver(ctx context.Context, delivery model.WebhookDelivery) (int, error) {
+	if !delivery.Subscription.IsActive {
+		return 0, fmt.Errorf("subscription is inactive")
+	}
+
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "AshramConnect-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(delivery.Subscription.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver returned %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// exponential backoff with up to 10% jitter so retries from a burst of
+// events do not all land on the receiver at once
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.opts.RetryBackoff << (attempt - 1)
+	if delay <= 0 || delay > d.opts.MaxBackoff {
+		delay = d.opts.MaxBackoff
+	}
+	return delay + time.Duration(rand.Int64N(int64(delay)/10+1))
+}
@@ -3,7 +3,25 @@
 -- ============================================
 
 -- Clear existing data (optional - use with caution)
--- TRUNCATE TABLE feedbacks, schedules, visits, lockers, profiles CASCADE;
+-- TRUNCATE TABLE feedbacks, schedules, visits, lockers, profiles, stay_areas CASCADE;
+
+-- Run `server migrate up` (or start the server once) before loading this file.
+
+-- ============================================
+-- 0. STAY AREAS AND SEVA TYPES
+-- ============================================
+INSERT INTO stay_areas (id, name, capacity) VALUES
+('30000000-0000-0000-0000-000000000001', 'Dormitory A', 50),
+('30000000-0000-0000-0000-000000000002', 'Dormitory B', 50),
+('30000000-0000-0000-0000-000000000003', 'Dormitory C', 40),
+('30000000-0000-0000-0000-000000000004', 'Guest House', 10)
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO seva_types (id, name, description, is_active, created_at) VALUES
+(gen_random_uuid(), 'Dining', 'Food service and dining hall seva', true, NOW()),
+(gen_random_uuid(), 'Kitchen Support', 'Kitchen preparation and cleaning seva', true, NOW()),
+(gen_random_uuid(), 'Counter', 'Reception and counter service seva', true, NOW())
+ON CONFLICT (name) DO NOTHING;
 
 -- ============================================
 -- 1. PROFILES (10 volunteers)
@@ -49,86 +67,86 @@ INSERT INTO lockers (id, locker_number, section, is_occupied, created_at) VALUES
 -- 3. VISITS (Mix of checked-in, checked-out, and pending)
 -- ============================================
 -- Rajesh Kumar - Currently checked in
-INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area, status, locker_id, remarks, created_at) VALUES
-('01000000-0000-0000-0000-000000000001', 'a1b2c3d4-e5f6-7890-abcd-ef1234567890', NOW() - INTERVAL '5 days', NULL, 'Dormitory A', 'checked-in', '10000000-0000-0000-0000-000000000001', 'Extended stay', NOW() - INTERVAL '5 days');
+INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area_id, status, locker_id, remarks, created_at) VALUES
+('01000000-0000-0000-0000-000000000001', 'a1b2c3d4-e5f6-7890-abcd-ef1234567890', NOW() - INTERVAL '5 days', NULL, '30000000-0000-0000-0000-000000000001', 'checked-in', '10000000-0000-0000-0000-000000000001', 'Extended stay', NOW() - INTERVAL '5 days');
 
 -- Priya Sharma - Currently checked in
-INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area, status, locker_id, remarks, created_at) VALUES
-('02000000-0000-0000-0000-000000000001', 'b2c3d4e5-f6a7-8901-bcde-f12345678901', NOW() - INTERVAL '3 days', NULL, 'Dormitory B', 'checked-in', '10000000-0000-0000-0000-000000000002', NULL, NOW() - INTERVAL '3 days');
+INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area_id, status, locker_id, remarks, created_at) VALUES
+('02000000-0000-0000-0000-000000000001', 'b2c3d4e5-f6a7-8901-bcde-f12345678901', NOW() - INTERVAL '3 days', NULL, '30000000-0000-0000-0000-000000000002', 'checked-in', '10000000-0000-0000-0000-000000000002', NULL, NOW() - INTERVAL '3 days');
 
 -- Amit Patel - Checked out
-INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area, status, locker_id, remarks, created_at) VALUES
-('03000000-0000-0000-0000-000000000001', 'c3d4e5f6-a7b8-9012-cdef-123456789012', NOW() - INTERVAL '15 days', NOW() - INTERVAL '8 days', 'Dormitory A', 'checked-out', NULL, 'Completed seva period', NOW() - INTERVAL '15 days');
+INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area_id, status, locker_id, remarks, created_at) VALUES
+('03000000-0000-0000-0000-000000000001', 'c3d4e5f6-a7b8-9012-cdef-123456789012', NOW() - INTERVAL '15 days', NOW() - INTERVAL '8 days', '30000000-0000-0000-0000-000000000001', 'checked-out', NULL, 'Completed seva period', NOW() - INTERVAL '15 days');
 
 -- Sneha Reddy - Currently checked in
-INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area, status, locker_id, remarks, created_at) VALUES
-('04000000-0000-0000-0000-000000000001', 'd4e5f6a7-b8c9-0123-def1-234567890123', NOW() - INTERVAL '7 days', NULL, 'Guest House', 'checked-in', '10000000-0000-0000-0000-000000000004', 'Overseas volunteer', NOW() - INTERVAL '7 days');
+INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area_id, status, locker_id, remarks, created_at) VALUES
+('04000000-0000-0000-0000-000000000001', 'd4e5f6a7-b8c9-0123-def1-234567890123', NOW() - INTERVAL '7 days', NULL, '30000000-0000-0000-0000-000000000004', 'checked-in', '10000000-0000-0000-0000-000000000004', 'Overseas volunteer', NOW() - INTERVAL '7 days');
 
 -- Vikram Singh - Currently checked in
-INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area, status, locker_id, remarks, created_at) VALUES
-('05000000-0000-0000-0000-000000000001', 'e5f6a7b8-c9d0-1234-ef12-345678901234', NOW() - INTERVAL '2 days', NULL, 'Dormitory C', 'checked-in', '10000000-0000-0000-0000-000000000007', NULL, NOW() - INTERVAL '2 days');
+INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area_id, status, locker_id, remarks, created_at) VALUES
+('05000000-0000-0000-0000-000000000001', 'e5f6a7b8-c9d0-1234-ef12-345678901234', NOW() - INTERVAL '2 days', NULL, '30000000-0000-0000-0000-000000000003', 'checked-in', '10000000-0000-0000-0000-000000000007', NULL, NOW() - INTERVAL '2 days');
 
 -- Ananya Iyer - Pending arrival
-INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area, status, locker_id, remarks, created_at) VALUES
-('06000000-0000-0000-0000-000000000001', 'f6a7b8c9-d0e1-2345-f123-456789012345', NOW() + INTERVAL '2 days', NULL, NULL, 'pending', NULL, 'Arriving soon', NOW());
+INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area_id, status, locker_id, remarks, created_at) VALUES
+('06000000-0000-0000-0000-000000000001', 'f6a7b8c9-d0e1-2345-f123-456789012345', NOW() + INTERVAL '2 days', NULL, '30000000-0000-0000-0000-000000000003', 'pending', NULL, 'Arriving soon', NOW());
 
 -- Divya Nair - Currently checked in
-INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area, status, locker_id, remarks, created_at) VALUES
-('08000000-0000-0000-0000-000000000001', 'b8c9d0e1-f2a3-4567-2345-678901234567', NOW() - INTERVAL '10 days', NULL, 'Dormitory B', 'checked-in', '20000000-0000-0000-0000-000000000001', NULL, NOW() - INTERVAL '10 days');
+INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area_id, status, locker_id, remarks, created_at) VALUES
+('08000000-0000-0000-0000-000000000001', 'b8c9d0e1-f2a3-4567-2345-678901234567', NOW() - INTERVAL '10 days', NULL, '30000000-0000-0000-0000-000000000002', 'checked-in', '20000000-0000-0000-0000-000000000001', NULL, NOW() - INTERVAL '10 days');
 
 -- Arjun Desai - Currently checked in
-INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area, status, locker_id, remarks, created_at) VALUES
-('09000000-0000-0000-0000-000000000001', 'c9d0e1f2-a3b4-5678-3456-789012345678', NOW() - INTERVAL '4 days', NULL, 'Guest House', 'checked-in', '20000000-0000-0000-0000-000000000003', 'International volunteer', NOW() - INTERVAL '4 days');
+INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area_id, status, locker_id, remarks, created_at) VALUES
+('09000000-0000-0000-0000-000000000001', 'c9d0e1f2-a3b4-5678-3456-789012345678', NOW() - INTERVAL '4 days', NULL, '30000000-0000-0000-0000-000000000004', 'checked-in', '20000000-0000-0000-0000-000000000003', 'International volunteer', NOW() - INTERVAL '4 days');
 
 -- Meera Joshi - Checked out (past visit)
-INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area, status, locker_id, remarks, created_at) VALUES
-('0a000000-0000-0000-0000-000000000001', 'd0e1f2a3-b4c5-6789-4567-890123456789', NOW() - INTERVAL '20 days', NOW() - INTERVAL '15 days', 'Dormitory A', 'checked-out', NULL, NULL, NOW() - INTERVAL '20 days');
+INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area_id, status, locker_id, remarks, created_at) VALUES
+('0a000000-0000-0000-0000-000000000001', 'd0e1f2a3-b4c5-6789-4567-890123456789', NOW() - INTERVAL '20 days', NOW() - INTERVAL '15 days', '30000000-0000-0000-0000-000000000001', 'checked-out', NULL, NULL, NOW() - INTERVAL '20 days');
 
 -- Meera Joshi - Currently checked in (new visit)
-INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area, status, locker_id, remarks, created_at) VALUES
-('0a000000-0000-0000-0000-000000000002', 'd0e1f2a3-b4c5-6789-4567-890123456789', NOW() - INTERVAL '1 day', NULL, 'Dormitory C', 'checked-in', '20000000-0000-0000-0000-000000000006', 'Second visit', NOW() - INTERVAL '1 day');
+INSERT INTO visits (id, profile_id, arrival_date, departure_date, stay_area_id, status, locker_id, remarks, created_at) VALUES
+('0a000000-0000-0000-0000-000000000002', 'd0e1f2a3-b4c5-6789-4567-890123456789', NOW() - INTERVAL '1 day', NULL, '30000000-0000-0000-0000-000000000003', 'checked-in', '20000000-0000-0000-0000-000000000006', 'Second visit', NOW() - INTERVAL '1 day');
 
 -- ============================================
 -- 4. SCHEDULES (Seva assignments for checked-in volunteers)
 -- ============================================
 -- Rajesh Kumar schedules
-INSERT INTO schedules (id, profile_id, visit_id, date, seva_type, location, notes, created_at) VALUES
-('b1000000-0000-0000-0000-000000000001', 'a1b2c3d4-e5f6-7890-abcd-ef1234567890', '01000000-0000-0000-0000-000000000001', NOW() - INTERVAL '2 days', 'Kitchen Support', 'Main Kitchen', NULL, NOW() - INTERVAL '3 days'),
-('b1000000-0000-0000-0000-000000000002', 'a1b2c3d4-e5f6-7890-abcd-ef1234567890', '01000000-0000-0000-0000-000000000001', NOW() - INTERVAL '1 day', 'Kitchen Support', 'Main Kitchen', NULL, NOW() - INTERVAL '2 days'),
-('b1000000-0000-0000-0000-000000000003', 'a1b2c3d4-e5f6-7890-abcd-ef1234567890', '01000000-0000-0000-0000-000000000001', NOW(), 'Dining', 'Dining Hall', NULL, NOW() - INTERVAL '1 day'),
-('b1000000-0000-0000-0000-000000000004', 'a1b2c3d4-e5f6-7890-abcd-ef1234567890', '01000000-0000-0000-0000-000000000001', NOW() + INTERVAL '1 day', 'Kitchen Support', 'Main Kitchen', NULL, NOW());
+INSERT INTO schedules (id, profile_id, visit_id, date, seva_type_id, location, notes, created_at) VALUES
+('b1000000-0000-0000-0000-000000000001', 'a1b2c3d4-e5f6-7890-abcd-ef1234567890', '01000000-0000-0000-0000-000000000001', NOW() - INTERVAL '2 days', (SELECT id FROM seva_types WHERE name = 'Kitchen Support'), 'Main Kitchen', NULL, NOW() - INTERVAL '3 days'),
+('b1000000-0000-0000-0000-000000000002', 'a1b2c3d4-e5f6-7890-abcd-ef1234567890', '01000000-0000-0000-0000-000000000001', NOW() - INTERVAL '1 day', (SELECT id FROM seva_types WHERE name = 'Kitchen Support'), 'Main Kitchen', NULL, NOW() - INTERVAL '2 days'),
+('b1000000-0000-0000-0000-000000000003', 'a1b2c3d4-e5f6-7890-abcd-ef1234567890', '01000000-0000-0000-0000-000000000001', NOW(), (SELECT id FROM seva_types WHERE name = 'Dining'), 'Dining Hall', NULL, NOW() - INTERVAL '1 day'),
+('b1000000-0000-0000-0000-000000000004', 'a1b2c3d4-e5f6-7890-abcd-ef1234567890', '01000000-0000-0000-0000-000000000001', NOW() + INTERVAL '1 day', (SELECT id FROM seva_types WHERE name = 'Kitchen Support'), 'Main Kitchen', NULL, NOW());
 
 -- Priya Sharma schedules
-INSERT INTO schedules (id, profile_id, visit_id, date, seva_type, location, notes, created_at) VALUES
-('b2000000-0000-0000-0000-000000000001', 'b2c3d4e5-f6a7-8901-bcde-f12345678901', '02000000-0000-0000-0000-000000000001', NOW(), 'Counter', 'Reception Counter', NULL, NOW() - INTERVAL '1 day'),
-('b2000000-0000-0000-0000-000000000002', 'b2c3d4e5-f6a7-8901-bcde-f12345678901', '02000000-0000-0000-0000-000000000001', NOW() + INTERVAL '1 day', 'Counter', 'Reception Counter', NULL, NOW());
+INSERT INTO schedules (id, profile_id, visit_id, date, seva_type_id, location, notes, created_at) VALUES
+('b2000000-0000-0000-0000-000000000001', 'b2c3d4e5-f6a7-8901-bcde-f12345678901', '02000000-0000-0000-0000-000000000001', NOW(), (SELECT id FROM seva_types WHERE name = 'Counter'), 'Reception Counter', NULL, NOW() - INTERVAL '1 day'),
+('b2000000-0000-0000-0000-000000000002', 'b2c3d4e5-f6a7-8901-bcde-f12345678901', '02000000-0000-0000-0000-000000000001', NOW() + INTERVAL '1 day', (SELECT id FROM seva_types WHERE name = 'Counter'), 'Reception Counter', NULL, NOW());
 
 -- Sneha Reddy schedules
-INSERT INTO schedules (id, profile_id, visit_id, date, seva_type, location, notes, created_at) VALUES
-('b4000000-0000-0000-0000-000000000001', 'd4e5f6a7-b8c9-0123-def1-234567890123', '04000000-0000-0000-0000-000000000001', NOW() - INTERVAL '1 day', 'Dining', 'Dining Hall', 'Morning shift', NOW() - INTERVAL '2 days'),
-('b4000000-0000-0000-0000-000000000002', 'd4e5f6a7-b8c9-0123-def1-234567890123', '04000000-0000-0000-0000-000000000001', NOW(), 'Dining', 'Dining Hall', 'Morning shift', NOW() - INTERVAL '1 day'),
-('b4000000-0000-0000-0000-000000000003', 'd4e5f6a7-b8c9-0123-def1-234567890123', '04000000-0000-0000-0000-000000000001', NOW() + INTERVAL '1 day', 'Dining', 'Dining Hall', NULL, NOW());
+INSERT INTO schedules (id, profile_id, visit_id, date, seva_type_id, location, notes, created_at) VALUES
+('b4000000-0000-0000-0000-000000000001', 'd4e5f6a7-b8c9-0123-def1-234567890123', '04000000-0000-0000-0000-000000000001', NOW() - INTERVAL '1 day', (SELECT id FROM seva_types WHERE name = 'Dining'), 'Dining Hall', 'Morning shift', NOW() - INTERVAL '2 days'),
+('b4000000-0000-0000-0000-000000000002', 'd4e5f6a7-b8c9-0123-def1-234567890123', '04000000-0000-0000-0000-000000000001', NOW(), (SELECT id FROM seva_types WHERE name = 'Dining'), 'Dining Hall', 'Morning shift', NOW() - INTERVAL '1 day'),
+('b4000000-0000-0000-0000-000000000003', 'd4e5f6a7-b8c9-0123-def1-234567890123', '04000000-0000-0000-0000-000000000001', NOW() + INTERVAL '1 day', (SELECT id FROM seva_types WHERE name = 'Dining'), 'Dining Hall', NULL, NOW());
 
 -- Vikram Singh schedules
-INSERT INTO schedules (id, profile_id, visit_id, date, seva_type, location, notes, created_at) VALUES
-('b5000000-0000-0000-0000-000000000001', 'e5f6a7b8-c9d0-1234-ef12-345678901234', '05000000-0000-0000-0000-000000000001', NOW(), 'Counter', 'Information Desk', NULL, NOW() - INTERVAL '1 day'),
-('b5000000-0000-0000-0000-000000000002', 'e5f6a7b8-c9d0-1234-ef12-345678901234', '05000000-0000-0000-0000-000000000001', NOW() + INTERVAL '2 days', 'Counter', 'Information Desk', NULL, NOW());
+INSERT INTO schedules (id, profile_id, visit_id, date, seva_type_id, location, notes, created_at) VALUES
+('b5000000-0000-0000-0000-000000000001', 'e5f6a7b8-c9d0-1234-ef12-345678901234', '05000000-0000-0000-0000-000000000001', NOW(), (SELECT id FROM seva_types WHERE name = 'Counter'), 'Information Desk', NULL, NOW() - INTERVAL '1 day'),
+('b5000000-0000-0000-0000-000000000002', 'e5f6a7b8-c9d0-1234-ef12-345678901234', '05000000-0000-0000-0000-000000000001', NOW() + INTERVAL '2 days', (SELECT id FROM seva_types WHERE name = 'Counter'), 'Information Desk', NULL, NOW());
 
 -- Divya Nair schedules
-INSERT INTO schedules (id, profile_id, visit_id, date, seva_type, location, notes, created_at) VALUES
-('b8000000-0000-0000-0000-000000000001', 'b8c9d0e1-f2a3-4567-2345-678901234567', '08000000-0000-0000-0000-000000000001', NOW() - INTERVAL '3 days', 'Dining', 'Dining Hall', NULL, NOW() - INTERVAL '4 days'),
-('b8000000-0000-0000-0000-000000000002', 'b8c9d0e1-f2a3-4567-2345-678901234567', '08000000-0000-0000-0000-000000000001', NOW(), 'Dining', 'Dining Hall', NULL, NOW() - INTERVAL '1 day'),
-('b8000000-0000-0000-0000-000000000003', 'b8c9d0e1-f2a3-4567-2345-678901234567', '08000000-0000-0000-0000-000000000001', NOW() + INTERVAL '1 day', 'Dining', 'Dining Hall', NULL, NOW());
+INSERT INTO schedules (id, profile_id, visit_id, date, seva_type_id, location, notes, created_at) VALUES
+('b8000000-0000-0000-0000-000000000001', 'b8c9d0e1-f2a3-4567-2345-678901234567', '08000000-0000-0000-0000-000000000001', NOW() - INTERVAL '3 days', (SELECT id FROM seva_types WHERE name = 'Dining'), 'Dining Hall', NULL, NOW() - INTERVAL '4 days'),
+('b8000000-0000-0000-0000-000000000002', 'b8c9d0e1-f2a3-4567-2345-678901234567', '08000000-0000-0000-0000-000000000001', NOW(), (SELECT id FROM seva_types WHERE name = 'Dining'), 'Dining Hall', NULL, NOW() - INTERVAL '1 day'),
+('b8000000-0000-0000-0000-000000000003', 'b8c9d0e1-f2a3-4567-2345-678901234567', '08000000-0000-0000-0000-000000000001', NOW() + INTERVAL '1 day', (SELECT id FROM seva_types WHERE name = 'Dining'), 'Dining Hall', NULL, NOW());
 
 -- Arjun Desai schedules
-INSERT INTO schedules (id, profile_id, visit_id, date, seva_type, location, notes, created_at) VALUES
-('b9000000-0000-0000-0000-000000000001', 'c9d0e1f2-a3b4-5678-3456-789012345678', '09000000-0000-0000-0000-000000000001', NOW(), 'Kitchen Support', 'Main Kitchen', NULL, NOW() - INTERVAL '1 day'),
-('b9000000-0000-0000-0000-000000000002', 'c9d0e1f2-a3b4-5678-3456-789012345678', '09000000-0000-0000-0000-000000000001', NOW() + INTERVAL '1 day', 'Kitchen Support', 'Main Kitchen', NULL, NOW());
+INSERT INTO schedules (id, profile_id, visit_id, date, seva_type_id, location, notes, created_at) VALUES
+('b9000000-0000-0000-0000-000000000001', 'c9d0e1f2-a3b4-5678-3456-789012345678', '09000000-0000-0000-0000-000000000001', NOW(), (SELECT id FROM seva_types WHERE name = 'Kitchen Support'), 'Main Kitchen', NULL, NOW() - INTERVAL '1 day'),
+('b9000000-0000-0000-0000-000000000002', 'c9d0e1f2-a3b4-5678-3456-789012345678', '09000000-0000-0000-0000-000000000001', NOW() + INTERVAL '1 day', (SELECT id FROM seva_types WHERE name = 'Kitchen Support'), 'Main Kitchen', NULL, NOW());
 
 -- Meera Joshi schedules (current visit)
-INSERT INTO schedules (id, profile_id, visit_id, date, seva_type, location, notes, created_at) VALUES
-('ba000000-0000-0000-0000-000000000001', 'd0e1f2a3-b4c5-6789-4567-890123456789', '0a000000-0000-0000-0000-000000000002', NOW(), 'Counter', 'Reception Counter', NULL, NOW() - INTERVAL '1 day'),
-('ba000000-0000-0000-0000-000000000002', 'd0e1f2a3-b4c5-6789-4567-890123456789', '0a000000-0000-0000-0000-000000000002', NOW() + INTERVAL '2 days', 'Counter', 'Reception Counter', NULL, NOW());
+INSERT INTO schedules (id, profile_id, visit_id, date, seva_type_id, location, notes, created_at) VALUES
+('ba000000-0000-0000-0000-000000000001', 'd0e1f2a3-b4c5-6789-4567-890123456789', '0a000000-0000-0000-0000-000000000002', NOW(), (SELECT id FROM seva_types WHERE name = 'Counter'), 'Reception Counter', NULL, NOW() - INTERVAL '1 day'),
+('ba000000-0000-0000-0000-000000000002', 'd0e1f2a3-b4c5-6789-4567-890123456789', '0a000000-0000-0000-0000-000000000002', NOW() + INTERVAL '2 days', (SELECT id FROM seva_types WHERE name = 'Counter'), 'Reception Counter', NULL, NOW());
 
 -- ============================================
 -- 5. FEEDBACK (Mix of positive, negative, neutral)
@@ -155,10 +173,11 @@ SELECT COUNT(*) as visit_count FROM visits;
 SELECT COUNT(*) as schedule_count FROM schedules;
 SELECT COUNT(*) as feedback_count FROM feedbacks;
 
-View currently checked-in volunteers:
-SELECT p.name, v.arrival_date, v.stay_area, l.locker_number 
+-- View currently checked-in volunteers:
+SELECT p.name, v.arrival_date, sa.name AS stay_area, l.locker_number
 FROM profiles p
 JOIN visits v ON p.id = v.profile_id
+JOIN stay_areas sa ON v.stay_area_id = sa.id
 LEFT JOIN lockers l ON v.locker_id = l.id
 WHERE v.status = 'checked-in'
 ORDER BY v.arrival_date;
\ No newline at end of file
@@ -1,5 +1,5 @@
 -- SQL script to clear all data from the database
--- Use this if you prefer SQL approach over Go script
+-- Use this if you prefer SQL over `counterapp clear`
 
 -- Clear data in order (respecting foreign key constraints)
 TRUNCATE TABLE schedules CASCADE;
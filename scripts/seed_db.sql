@@ -24,10 +24,11 @@ INSERT INTO stay_areas (id, name, capacity) VALUES
 (gen_random_uuid(), 'Cottage Area', 15);
 
 -- Seed Lockers (100 lockers)
-INSERT INTO lockers (id, locker_number, is_occupied, created_at)
+INSERT INTO lockers (id, locker_number, section, is_occupied, created_at)
 SELECT 
     gen_random_uuid(),
     'L' || LPAD(generate_series::text, 3, '0'),
+    CASE WHEN generate_series <= 50 THEN 'A' ELSE 'B' END,
     false,
     NOW()
 FROM generate_series(1, 100);
@@ -0,0 +1,81 @@
+package api_test
+
+import (
+	"counterapp/internal/handler"
+	"counterapp/internal/model"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSevaTypes(t *testing.T) { forBackends(t, testSevaTypes) }
+
+func testSevaTypes(t *testing.T, a *app) {
+	var created model.SevaType
+	a.call("POST", "/api/seva-types", gin.H{"name": "Kitchen", "description": "Cooking and serving"}, 201, &created)
+	if !created.IsActive || created.Description == nil || *created.Description != "Cooking and serving" {
+		t.Fatalf("created seva type active=%v description=%v", created.IsActive, created.Description)
+	}
+	a.sevaType("Garden")
+
+	var sevaTypes []model.SevaType
+	a.call("GET", "/api/seva-types", nil, 200, &sevaTypes)
+	if len(sevaTypes) != 2 {
+		t.Fatalf("got %d seva types, want 2", len(sevaTypes))
+	}
+	a.callError("POST", "/api/seva-types", gin.H{"description": "no name"}, 400, handler.CodeValidation)
+
+	// last, the unique violation aborts the test transaction
+	a.callError("POST", "/api/seva-types", gin.H{"name": "Kitchen"}, 409, handler.CodeConflict)
+}
+
+func TestStayAreas(t *testing.T) { forBackends(t, testStayAreas) }
+
+func testStayAreas(t *testing.T, a *app) {
+	created := a.stayArea("North Dorm", 12)
+	if created.Name != "North Dorm" || created.Capacity != 12 {
+		t.Fatalf("created stay area %q of %d", created.Name, created.Capacity)
+	}
+
+	var stayAreas []model.StayArea
+	a.call("GET", "/api/stay-areas", nil, 200, &stayAreas)
+	if len(stayAreas) != 1 || stayAreas[0].ID != created.ID {
+		t.Fatalf("got %d stay areas, want the one created", len(stayAreas))
+	}
+	a.callError("POST", "/api/stay-areas", gin.H{"name": "Tent", "capacity": 0}, 400, handler.CodeValidation)
+	a.callError("POST", "/api/stay-areas", gin.H{"name": "Tent", "capacity": "many"}, 400, handler.CodeValidation)
+}
+
+func TestFeedbacks(t *testing.T) { forBackends(t, testFeedbacks) }
+
+func testFeedbacks(t *testing.T, a *app) {
+	stayArea := a.stayArea("North Dorm", 10)
+	asha := a.profile("Asha", model.GenderFemale)
+	ravi := a.profile("Ravi", model.GenderMale)
+	visit := a.checkIn(asha, stayArea, "2026-03-01", "")
+
+	var feedback model.Feedback
+	a.call("POST", "/api/feedbacks", gin.H{
+		"profile_id": asha.ID, "visit_id": visit.ID, "content": "Helpful in the kitchen", "type": model.TypePositive, "created_by": "Coordinator",
+	}, 201, &feedback)
+	if feedback.VisitID == nil || *feedback.VisitID != visit.ID {
+		t.Fatalf("feedback on visit %v, want %s", feedback.VisitID, visit.ID)
+	}
+	a.call("POST", "/api/feedbacks", gin.H{"profile_id": ravi.ID, "content": "Late for seva", "type": model.TypeNegative}, 201, nil)
+
+	var feedbacks []model.Feedback
+	a.call("GET", "/api/feedbacks", nil, 200, &feedbacks)
+	if len(feedbacks) != 2 {
+		t.Fatalf("got %d feedbacks, want 2", len(feedbacks))
+	}
+	a.call("GET", "/api/profiles/"+asha.ID.String()+"/feedbacks", nil, 200, &feedbacks)
+	if len(feedbacks) != 1 || feedbacks[0].ID != feedback.ID {
+		t.Fatalf("got %d feedbacks for the profile, want the one given", len(feedbacks))
+	}
+
+	a.callError("POST", "/api/feedbacks", gin.H{"profile_id": asha.ID, "content": "?", "type": "Mixed"}, 400, handler.CodeValidation)
+	a.callError("POST", "/api/feedbacks", gin.H{"profile_id": unknownID(), "content": "?", "type": model.TypeNeutral}, 404, handler.CodeNotFound)
+	a.callError("POST", "/api/feedbacks", gin.H{
+		"profile_id": ravi.ID, "visit_id": visit.ID, "content": "?", "type": model.TypeNeutral,
+	}, 400, handler.CodeValidation)
+}
@@ -0,0 +1,163 @@
+package api_test
+
+import (
+	"bytes"
+	"counterapp/internal/handler"
+	"counterapp/internal/importer"
+	"counterapp/internal/model"
+	"encoding/csv"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fetches a CSV export and returns its rows, header first
+func (a *app) csv(target string) [][]string {
+	a.t.Helper()
+	rec := a.fetch("GET", target, 200, "text/csv")
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		a.t.Fatalf("GET %s: reading CSV: %v", target, err)
+	}
+	return rows
+}
+
+func TestExports(t *testing.T) { forBackends(t, testExports) }
+
+func testExports(t *testing.T, a *app) {
+	a.sevaType("Kitchen")
+	north := a.stayArea("North Dorm", 10)
+	south := a.stayArea("South Dorm", 10)
+	asha := a.profile("Asha", model.GenderFemale)
+	ravi := a.profile("Ravi", model.GenderMale)
+	a.profile("Meera", model.GenderFemale)
+	visit := a.checkIn(asha, north, "2026-03-01", "2026-03-10")
+	a.checkIn(ravi, south, "2026-03-01", "")
+	a.call("POST", "/api/schedules", gin.H{
+		"profile_id": asha.ID, "visit_id": visit.ID, "seva_type": "Kitchen", "location": "Main Kitchen", "date": "2026-03-02",
+	}, 201, nil)
+
+	profiles := a.csv("/api/exports/profiles")
+	if len(profiles) != 4 || profiles[0][0] != "Name" {
+		t.Fatalf("profiles export has %d rows starting %v, want a header and 3 profiles", len(profiles), profiles[0])
+	}
+	profiles = a.csv("/api/exports/profiles?columns=email,name")
+	if strings.Join(profiles[0], ",") != "Email,Name" {
+		t.Fatalf("selected columns %v, want Email,Name", profiles[0])
+	}
+
+	residents := a.csv("/api/exports/residents")
+	if len(residents) != 3 {
+		t.Fatalf("residents export has %d rows, want a header and 2 residents", len(residents))
+	}
+	residents = a.csv("/api/exports/residents?columns=name,stay_area&stay_area_id=" + north.ID.String())
+	if len(residents) != 2 || residents[1][0] != "Asha" || residents[1][1] != "North Dorm" {
+		t.Fatalf("north dorm residents %v, want Asha", residents)
+	}
+
+	schedules := a.csv("/api/exports/schedules?start_date=2026-03-01&end_date=2026-03-31&columns=name,seva_type,location")
+	if len(schedules) != 2 || strings.Join(schedules[1], ",") != "Asha,Kitchen,Main Kitchen" {
+		t.Fatalf("schedules export %v, want Asha in the kitchen", schedules)
+	}
+
+	xlsx := a.fetch("GET", "/api/exports/profiles?format=xlsx", 200, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	if !bytes.HasPrefix(xlsx.Body.Bytes(), []byte("PK")) {
+		t.Fatalf("xlsx export is not a zip file")
+	}
+	if disposition := xlsx.Header().Get("Content-Disposition"); !strings.Contains(disposition, `filename="profiles.xlsx"`) {
+		t.Fatalf("content disposition %q", disposition)
+	}
+
+	a.callError("GET", "/api/exports/profiles?format=pdf", nil, 400, handler.CodeBadRequest)
+	a.callError("GET", "/api/exports/profiles?columns=shoe_size", nil, 400, handler.CodeValidation)
+	a.callError("GET", "/api/exports/schedules?start_date=2026-03-01", nil, 400, handler.CodeValidation)
+	a.callError("GET", "/api/exports/residents?stay_area_id=north", nil, 400, handler.CodeValidation)
+}
+
+const importCSV = `name,email,gender,category,phone,arrival_date,departure_date,stay_area
+Asha,asha@example.com,female,Short Term Volunteer,9876543210,2026-03-01,2026-03-05,North Dorm
+Ravi,ravi@example.com,Male,,,,,
+`
+
+func (a *app) profileCount() int {
+	a.t.Helper()
+	var profiles []model.ProfileOverview
+	a.call("GET", "/api/profiles", nil, 200, &profiles)
+	return len(profiles)
+}
+
+func TestImportProfiles(t *testing.T) { forBackends(t, testImportProfiles) }
+
+func testImportProfiles(t *testing.T, a *app) {
+	a.stayArea("North Dorm", 10)
+
+	var report importer.Report
+	a.call("POST", "/api/imports/profiles?dry_run=true", strings.NewReader(importCSV), 200, &report)
+	if !report.DryRun || report.Committed || report.Created != 2 {
+		t.Fatalf("dry run report %+v, want 2 created and nothing committed", report)
+	}
+	if n := a.profileCount(); n != 0 {
+		t.Fatalf("dry run saved %d profiles", n)
+	}
+
+	a.call("POST", "/api/imports/profiles", strings.NewReader(importCSV), 200, &report)
+	if !report.Committed || report.Created != 2 || report.Rows[0].VisitID == nil {
+		t.Fatalf("import report %+v, want 2 created and a visit for the first row", report)
+	}
+	if n := a.profileCount(); n != 2 {
+		t.Fatalf("import saved %d profiles, want 2", n)
+	}
+
+	// the same file again changes nothing
+	a.call("POST", "/api/imports/profiles", strings.NewReader(importCSV), 200, &report)
+	if report.Created != 0 || report.Skipped != 2 {
+		t.Fatalf("second import report %+v, want both rows skipped", report)
+	}
+}
+
+func TestImportProfilesMultipart(t *testing.T) { forBackends(t, testImportProfilesMultipart) }
+
+func testImportProfilesMultipart(t *testing.T, a *app) {
+	a.stayArea("North Dorm", 10)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "profiles.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(importCSV))
+	form.Close()
+
+	rec := a.request("POST", "/api/imports/profiles", &body, "Content-Type", form.FormDataContentType())
+	if rec.Code != 200 {
+		t.Fatalf("multipart import: status %d: %s", rec.Code, rec.Body)
+	}
+	if n := a.profileCount(); n != 2 {
+		t.Fatalf("import saved %d profiles, want 2", n)
+	}
+}
+
+func TestImportRejectsInvalidRows(t *testing.T) { forBackends(t, testImportRejectsInvalidRows) }
+
+func testImportRejectsInvalidRows(t *testing.T, a *app) {
+	a.stayArea("North Dorm", 10)
+
+	invalid := importCSV + "Meera,not-an-email,female,,,,,\nLata,lata@example.com,female,,,2026-03-01,,Nowhere\nUma,uma@example.com,female,,12,,,\n"
+	res := a.callError("POST", "/api/imports/profiles", strings.NewReader(invalid), 422, handler.CodeValidation)
+	fields := map[string]bool{}
+	for _, detail := range res.Details {
+		fields[detail.Field] = true
+	}
+	if !fields["rows[4]"] || !fields["rows[5]"] || !fields["rows[6]"] || len(fields) != 3 {
+		t.Fatalf("errors reported on %v, want rows[4], rows[5] and rows[6]", fields)
+	}
+	if n := a.profileCount(); n != 0 {
+		t.Fatalf("rejected import saved %d profiles", n)
+	}
+
+	a.callError("POST", "/api/imports/profiles", strings.NewReader("name,email\nAsha,asha@example.com\n"), 400, handler.CodeBadRequest)
+	a.callError("POST", "/api/imports/profiles?dry_run=maybe", strings.NewReader(importCSV), 400, handler.CodeValidation)
+}
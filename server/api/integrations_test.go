@@ -0,0 +1,239 @@
+package api_test
+
+import (
+	"context"
+	"counterapp/internal/dao"
+	"counterapp/internal/events"
+	"counterapp/internal/handler"
+	"counterapp/internal/model"
+	"counterapp/internal/notify"
+	"counterapp/internal/webhook"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNotifications(t *testing.T) {
+	a := newApp(t)
+	profile := a.profile("Asha", model.GenderFemale)
+	a.checkIn(profile, a.stayArea("North Dorm", 10), "2026-03-01", "2026-03-10")
+
+	var messages []model.OutboxMessage
+	a.call("GET", "/api/notifications", nil, 200, &messages)
+	if len(messages) != 1 || messages[0].Template != model.TemplateVisitConfirmation || messages[0].Recipient != profile.Email {
+		t.Fatalf("got %d notifications, want a visit confirmation to %s", len(messages), profile.Email)
+	}
+	msg := messages[0]
+
+	a.callError("POST", "/api/notifications/"+msg.ID.String()+"/retry", nil, 404, handler.CodeNotFound)
+	if err := a.tx.Model(&msg).Updates(map[string]any{"status": model.DeliveryFailed, "attempts": 6}).Error; err != nil {
+		t.Fatal(err)
+	}
+	a.call("GET", "/api/notifications?status=failed", nil, 200, &messages)
+	if len(messages) != 1 {
+		t.Fatalf("got %d failed notifications, want 1", len(messages))
+	}
+
+	var retried model.OutboxMessage
+	a.call("POST", "/api/notifications/"+msg.ID.String()+"/retry", nil, 200, &retried)
+	if retried.Status != model.DeliveryPending || retried.Attempts != 0 {
+		t.Fatalf("retried notification is %q after %d attempts, want pending and reset", retried.Status, retried.Attempts)
+	}
+	a.call("GET", "/api/notifications?status=failed&limit=10", nil, 200, &messages)
+	if len(messages) != 0 {
+		t.Fatalf("got %d failed notifications after the retry", len(messages))
+	}
+
+	a.callError("GET", "/api/notifications?status=lost", nil, 400, handler.CodeValidation)
+	a.callError("GET", "/api/notifications?limit=501", nil, 400, handler.CodeValidation)
+	a.callError("POST", "/api/notifications/"+unknownID()+"/retry", nil, 404, handler.CodeNotFound)
+}
+
+// records what it was asked to send, failing for failTo
+type fakeNotifier struct {
+	sent   []string
+	failTo string
+}
+
+func (f *fakeNotifier) Channel() model.NotificationChannel { return model.ChannelEmail }
+
+func (f *fakeNotifier) Send(ctx context.Context, msg notify.Message) error {
+	if msg.To == f.failTo {
+		return errors.New("mailbox unavailable")
+	}
+	f.sent = append(f.sent, msg.To)
+	return nil
+}
+
+func TestNotificationSender(t *testing.T) {
+	a := newApp(t)
+	queue := func(recipient string, status model.DeliveryStatus, nextAttemptAt time.Time) *model.OutboxMessage {
+		msg := &model.OutboxMessage{
+			Channel: model.ChannelEmail, Template: model.TemplateVisitConfirmation, Recipient: recipient,
+			Body: "Welcome", Status: status, NextAttemptAt: nextAttemptAt,
+		}
+		if err := dao.CreateOutboxMessage(a.tx, msg); err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+	due := queue("due@example.com", model.DeliveryPending, time.Now().Add(-time.Minute))
+	bounced := queue("bounced@example.com", model.DeliveryPending, time.Now().Add(-time.Minute))
+	// claimed by a sender that stopped, its lease ran out
+	abandoned := queue("abandoned@example.com", model.DeliverySending, time.Now().Add(-time.Minute))
+	// claimed by a sender that is still at it
+	leased := queue("leased@example.com", model.DeliverySending, time.Now().Add(time.Hour))
+	later := queue("later@example.com", model.DeliveryPending, time.Now().Add(time.Hour))
+
+	notifier := &fakeNotifier{failTo: bounced.Recipient}
+	sender := notify.NewSender(a.tx, map[model.NotificationChannel]notify.Notifier{model.ChannelEmail: notifier}, notify.SenderOptions{})
+	if err := sender.SendDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(notifier.sent)
+	if want := []string{abandoned.Recipient, due.Recipient}; !slices.Equal(notifier.sent, want) {
+		t.Fatalf("sent to %v, want %v", notifier.sent, want)
+	}
+
+	for msg, want := range map[*model.OutboxMessage]model.DeliveryStatus{
+		due: model.DeliverySent, abandoned: model.DeliverySent, bounced: model.DeliveryPending,
+		leased: model.DeliverySending, later: model.DeliveryPending,
+	} {
+		var stored model.OutboxMessage
+		if err := a.tx.First(&stored, "id = ?", msg.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if stored.Status != want {
+			t.Errorf("message to %s is %q, want %q", msg.Recipient, stored.Status, want)
+		}
+		if msg == bounced && (stored.Attempts != 1 || stored.LastError == nil || !stored.NextAttemptAt.After(time.Now())) {
+			t.Errorf("bounced message has %d attempts and error %v, want one failed attempt retried later", stored.Attempts, stored.LastError)
+		}
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	a := newApp(t)
+	stayArea := a.stayArea("North Dorm", 10)
+	subscribe := gin.H{"url": "https://hooks.example.com/counter", "event_types": []events.Type{events.VisitCheckedIn}}
+
+	a.callError("POST", "/api/webhooks", subscribe, 401, handler.CodeUnauthorized)
+	a.callError("GET", "/api/webhooks", nil, 401, handler.CodeUnauthorized)
+	a.signInAsAdmin()
+
+	var created handler.AddWebhookResponse
+	a.call("POST", "/api/webhooks", subscribe, 201, &created)
+	if len(created.Secret) != 64 || !created.IsActive {
+		t.Fatalf("created webhook active=%v with a %d character secret, want active with a generated one", created.IsActive, len(created.Secret))
+	}
+	var subscriptions []model.WebhookSubscription
+	a.call("GET", "/api/webhooks", nil, 200, &subscriptions)
+	if len(subscriptions) != 1 || subscriptions[0].ID != created.ID {
+		t.Fatalf("got %d webhooks, want the one created", len(subscriptions))
+	}
+
+	visit := a.checkIn(a.profile("Asha", model.GenderFemale), stayArea, "2026-03-01", "")
+	// not subscribed to check-outs
+	a.call("PATCH", "/api/visits/"+visit.ID.String(), gin.H{"status": model.StatusCheckedOut}, 200, nil)
+
+	deliveriesPath := "/api/webhooks/" + created.ID.String() + "/deliveries"
+	var deliveries []model.WebhookDelivery
+	a.call("GET", deliveriesPath, nil, 200, &deliveries)
+	if len(deliveries) != 1 || deliveries[0].EventType != string(events.VisitCheckedIn) || deliveries[0].Status != model.DeliveryPending {
+		t.Fatalf("got %d deliveries, want one pending visit.checked_in", len(deliveries))
+	}
+
+	var redelivery model.WebhookDelivery
+	a.call("POST", "/api/webhooks/deliveries/"+deliveries[0].ID.String()+"/redeliver", nil, 202, &redelivery)
+	if redelivery.RedeliveryOf == nil || *redelivery.RedeliveryOf != deliveries[0].ID || redelivery.EventID != deliveries[0].EventID {
+		t.Fatalf("redelivery of %v for event %s, want a copy of %s", redelivery.RedeliveryOf, redelivery.EventID, deliveries[0].ID)
+	}
+	a.call("GET", deliveriesPath+"?limit=1", nil, 200, &deliveries)
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries with limit 1", len(deliveries))
+	}
+
+	a.call("DELETE", "/api/webhooks/"+created.ID.String(), nil, 204, nil)
+	a.checkIn(a.profile("Ravi", model.GenderMale), stayArea, "2026-03-01", "")
+	a.call("GET", deliveriesPath, nil, 200, &deliveries)
+	if len(deliveries) != 2 {
+		t.Fatalf("got %d deliveries after unsubscribing, want the 2 from before", len(deliveries))
+	}
+
+	a.callError("POST", "/api/webhooks", gin.H{"url": "https://hooks.example.com", "event_types": []string{"visit.teleported"}}, 400, handler.CodeValidation)
+	a.callError("POST", "/api/webhooks", gin.H{"url": "ftp://hooks.example.com", "event_types": []events.Type{events.VisitCheckedIn}}, 400, handler.CodeValidation)
+	a.callError("POST", "/api/webhooks", gin.H{"url": "https://hooks.example.com", "event_types": []events.Type{events.VisitCheckedIn}, "secret": "short"}, 400, handler.CodeValidation)
+	a.callError("DELETE", "/api/webhooks/"+unknownID(), nil, 404, handler.CodeNotFound)
+	a.callError("DELETE", "/api/webhooks/hook", nil, 400, handler.CodeValidation)
+	a.callError("GET", deliveriesPath+"?limit=0", nil, 400, handler.CodeValidation)
+	a.callError("POST", "/api/webhooks/deliveries/"+unknownID()+"/redeliver", nil, 404, handler.CodeNotFound)
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	a := newApp(t)
+	var (
+		mu     sync.Mutex
+		posted []string
+	)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		posted = append(posted, r.Header.Get(webhook.HeaderDelivery))
+	}))
+	defer receiver.Close()
+
+	sub, err := dao.AddWebhookSubscription(a.tx, model.AddWebhookSubscriptionRequest{
+		URL: receiver.URL, Secret: strings.Repeat("s", 32), EventTypes: []string{string(events.VisitCheckedIn)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stayArea := a.stayArea("North Dorm", 10)
+	a.signInAsAdmin()
+	for _, name := range []string{"Asha", "Ravi", "Meera"} {
+		a.checkIn(a.profile(name, model.GenderFemale), stayArea, "2026-03-01", "")
+	}
+	var deliveries []model.WebhookDelivery
+	a.call("GET", "/api/webhooks/"+sub.ID.String()+"/deliveries", nil, 200, &deliveries)
+	if len(deliveries) != 3 {
+		t.Fatalf("got %d deliveries, want one per check-in", len(deliveries))
+	}
+	due, abandoned, leased := deliveries[0], deliveries[1], deliveries[2]
+	lease := func(d model.WebhookDelivery, until time.Time) {
+		if err := a.tx.Model(&d).Updates(map[string]any{"status": model.DeliverySending, "next_attempt_at": until}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	lease(abandoned, time.Now().Add(-time.Minute))
+	lease(leased, time.Now().Add(time.Hour))
+
+	if err := webhook.NewDispatcher(a.tx, webhook.DispatcherOptions{}).DeliverDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	slices.Sort(posted)
+	want := []string{due.ID.String(), abandoned.ID.String()}
+	slices.Sort(want)
+	if !slices.Equal(posted, want) {
+		t.Fatalf("posted %v, want %v", posted, want)
+	}
+
+	a.call("GET", "/api/webhooks/"+sub.ID.String()+"/deliveries", nil, 200, &deliveries)
+	for _, d := range deliveries {
+		want := model.DeliverySent
+		if d.ID == leased.ID {
+			want = model.DeliverySending
+		}
+		if d.Status != want {
+			t.Errorf("delivery %s is %q, want %q", d.ID, d.Status, want)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package api_test
+
+import (
+	"bytes"
+	"counterapp/internal/config"
+	"counterapp/internal/crypt"
+	"counterapp/internal/dao"
+	"counterapp/internal/events"
+	"counterapp/internal/handler"
+	"counterapp/internal/logging"
+	"counterapp/internal/metrics"
+	"counterapp/internal/model"
+	"counterapp/internal/repository"
+	"counterapp/internal/repository/memory"
+	"counterapp/internal/service"
+	"counterapp/internal/testdb"
+	"counterapp/server/api"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	server *testdb.Server
+	// why the tests are skipped when server is nil
+	skipReason string
+)
+
+// the suite runs against the database named by COUNTERAPP_TEST_DSN or an
+// embedded server. Without either it is skipped, unless the DSN was given
+// and does not work.
+func TestMain(m *testing.M) {
+	flag.Parse()
+	gin.SetMode(gin.TestMode)
+	// as dao.Connect does with the configured key
+	if err := crypt.SetKey(bytes.Repeat([]byte{7}, crypt.KeySize)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if testing.Short() {
+		skipReason = "integration test, skipped with -short"
+	} else {
+		var err error
+		server, err = testdb.Start()
+		if errors.Is(err, testdb.ErrUnavailable) {
+			skipReason = err.Error()
+		} else if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	code := m.Run()
+	if server != nil {
+		if err := server.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, "stopping test database:", err)
+		}
+	}
+	os.Exit(code)
+}
+
+// app is the router wired like counterapp serve does, on a transaction of
+// its own that is rolled back when the test ends
+type app struct {
+	t      *testing.T
+	tx     *gorm.DB
+	repos  *repository.Repositories
+	svc    *service.Services
+	bus    *events.Bus
+	router *gin.Engine
+	// sent as a bearer token when set
+	token string
+}
+
+// configure changes the default configuration before the router is set up
+func newApp(t *testing.T, configure ...func(cfg *config.Config)) *app {
+	t.Helper()
+	if server == nil {
+		t.Skip(skipReason)
+	}
+
+	tx := server.Tx(t)
+	logger := logging.Discard()
+	cfg := config.Default()
+	cfg.Server.MetricsPort = 0
+	for _, change := range configure {
+		change(cfg)
+	}
+
+	repos := repository.NewGormRepositories(tx)
+	svc := service.New(repos, logger)
+	bus := events.NewBus(100)
+	dao.SetEventPublisher(bus)
+	t.Cleanup(func() { dao.SetEventPublisher(nil) })
+
+	return &app{
+		t:      t,
+		tx:     tx,
+		repos:  repos,
+		svc:    svc,
+		bus:    bus,
+		router: api.SetupRouter(cfg, logger, svc, bus, metrics.New(tx, logger)),
+	}
+}
+
+// app on the in-memory repositories, which needs no database and so always
+// runs. Metrics are not collected.
+func newMemoryApp(t *testing.T) *app {
+	t.Helper()
+	logger := logging.Discard()
+	cfg := config.Default()
+	cfg.Server.MetricsPort = 0
+
+	repos := memory.NewRepositories()
+	svc := service.New(repos, logger)
+	bus := events.NewBus(100)
+	return &app{
+		t:      t,
+		repos:  repos,
+		svc:    svc,
+		bus:    bus,
+		router: api.SetupRouter(cfg, logger, svc, bus, nil),
+	}
+}
+
+// runs test on PostgreSQL and on the in-memory repositories, for tests that
+// only go through the API or the repositories, so both implementations are
+// held to the same behaviour
+func forBackends(t *testing.T, test func(t *testing.T, a *app)) {
+	t.Run("postgres", func(t *testing.T) { test(t, newApp(t)) })
+	t.Run("memory", func(t *testing.T) { test(t, newMemoryApp(t)) })
+}
+
+// sends a request, body is sent as it is when it is an io.Reader and
+// encoded as JSON otherwise
+func (a *app) request(method string, target string, body any, header ...string) *httptest.ResponseRecorder {
+	a.t.Helper()
+	var reader io.Reader
+	contentType := ""
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			a.t.Fatalf("encoding request body: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+		contentType = "application/json"
+	}
+
+	req := httptest.NewRequest(method, target, reader)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if a.token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	rec := httptest.NewRecorder()
+	a.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// sends a request and fails the test unless it is answered with status. The
+// response body is decoded into out when it is not nil.
+func (a *app) call(method string, target string, body any, status int, out any) {
+	a.t.Helper()
+	rec := a.request(method, target, body)
+	if rec.Code != status {
+		a.t.Fatalf("%s %s: status %d, want %d: %s", method, target, rec.Code, status, rec.Body)
+	}
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			a.t.Fatalf("%s %s: decoding %s: %v", method, target, rec.Body, err)
+		}
+	}
+}
+
+// sends a request and fails the test unless it is answered with an error of
+// status and code
+func (a *app) callError(method string, target string, body any, status int, code handler.ErrorCode) handler.ErrorBody {
+	a.t.Helper()
+	var res handler.ErrorResponse
+	a.call(method, target, body, status, &res)
+	if res.Error.Code != code {
+		a.t.Fatalf("%s %s: error code %q, want %q: %s", method, target, res.Error.Code, code, res.Error.Message)
+	}
+	return res.Error
+}
+
+func (a *app) stayArea(name string, capacity int) model.StayArea {
+	a.t.Helper()
+	var stayArea model.StayArea
+	a.call("POST", "/api/stay-areas", gin.H{"name": name, "capacity": capacity}, 201, &stayArea)
+	return stayArea
+}
+
+func (a *app) sevaType(name string) model.SevaType {
+	a.t.Helper()
+	var sevaType model.SevaType
+	a.call("POST", "/api/seva-types", gin.H{"name": name}, 201, &sevaType)
+	return sevaType
+}
+
+func (a *app) profile(name string, gender model.Gender) model.Profile {
+	a.t.Helper()
+	var profile model.Profile
+	a.call("POST", "/api/profiles", gin.H{"profile": gin.H{
+		"name":         name,
+		"email":        fmt.Sprintf("%s.%s@example.com", name, uuid.NewString()[:8]),
+		"phone_number": "9876543210",
+		"gender":       gender,
+		"category":     model.CategorySTV,
+	}}, 200, &profile)
+	return profile
+}
+
+// creates an admin and sends its token with the following requests
+func (a *app) signInAsAdmin() model.User {
+	a.t.Helper()
+	user, token, err := a.svc.Users.CreateAdmin("Desk Admin", "admin@example.com")
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	a.token = token
+	return *user
+}
+
+// checks profile in from arrival until departure, departure may be empty
+func (a *app) checkIn(profile model.Profile, stayArea model.StayArea, arrival string, departure string) model.Visit {
+	a.t.Helper()
+	body := gin.H{"profile_id": profile.ID, "stay_area_id": stayArea.ID, "arrival_date": arrival}
+	if departure != "" {
+		body["departure_date"] = departure
+	}
+	var visit model.Visit
+	a.call("POST", "/api/visits", body, 200, &visit)
+	return visit
+}
+
+func (a *app) visits(profile model.Profile) []model.Visit {
+	a.t.Helper()
+	var visits []model.Visit
+	a.call("GET", "/api/profiles/"+profile.ID.String()+"/visits", nil, 200, &visits)
+	return visits
+}
+
+// the occupancy of every stay area keyed by stay area ID
+func (a *app) occupancy() map[string]handler.StayAreaOccupancyResponse {
+	a.t.Helper()
+	var rows []handler.StayAreaOccupancyResponse
+	a.call("GET", "/api/stay-areas/occupancy", nil, 200, &rows)
+	byID := make(map[string]handler.StayAreaOccupancyResponse, len(rows))
+	for _, row := range rows {
+		byID[row.StayAreaID] = row
+	}
+	return byID
+}
+
+// a visit of id from visits, failing the test when it is missing
+func findVisit(t *testing.T, visits []model.Visit, id uuid.UUID) model.Visit {
+	t.Helper()
+	for _, v := range visits {
+		if v.ID == id {
+			return v
+		}
+	}
+	t.Fatalf("visit %s not found in %d visits", id, len(visits))
+	return model.Visit{}
+}
+
+// a well formed ID that matches nothing
+func unknownID() string {
+	return uuid.NewString()
+}
+
+// sends a request without a body and fails the test unless it is answered
+// with status and a content type starting with contentType
+func (a *app) fetch(method string, target string, status int, contentType string) *httptest.ResponseRecorder {
+	a.t.Helper()
+	rec := a.request(method, target, nil)
+	if rec.Code != status {
+		a.t.Fatalf("%s %s: status %d, want %d: %s", method, target, rec.Code, status, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, contentType) {
+		a.t.Fatalf("%s %s: content type %q, want %q", method, target, got, contentType)
+	}
+	return rec
+}
@@ -0,0 +1,72 @@
+package api_test
+
+import (
+	"counterapp/internal/handler"
+	"counterapp/internal/model"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEmergencyContactsAndMedicalInfo(t *testing.T) {
+	a := newApp(t)
+	dorm := a.stayArea("North Dorm", 10)
+	asha := a.profile("Asha", model.GenderFemale)
+	profile := "/api/profiles/" + asha.ID.String()
+
+	var updated model.Profile
+	a.call("PATCH", profile, gin.H{"emergency_contacts": []gin.H{
+		{"name": "Lakshmi", "relation": "Mother", "phone_number": "+91 98450 12345"},
+	}}, 200, &updated)
+	if len(updated.EmergencyContacts) != 1 || updated.EmergencyContacts[0].Relation != "Mother" {
+		t.Fatalf("emergency contacts %+v, want her mother", updated.EmergencyContacts)
+	}
+	a.callError("PATCH", profile, gin.H{"emergency_contacts": []gin.H{{"relation": "Brother"}}}, 400, handler.CodeValidation)
+
+	// medical info needs a role, at the desk too
+	a.callError("GET", profile+"/medical", nil, 401, handler.CodeUnauthorized)
+	a.callError("POST", "/api/visits", gin.H{
+		"profile_id": asha.ID, "stay_area_id": dorm.ID, "arrival_date": "2026-03-01", "medical": gin.H{"notes": "Asthma"},
+	}, 401, handler.CodeUnauthorized)
+
+	a.signInAsAdmin()
+	var medical model.MedicalInfo
+	a.call("PUT", profile+"/medical", gin.H{"notes": "Asthma, inhaler in her bag", "dietary_needs": " "}, 200, &medical)
+	if medical.Notes == nil || *medical.Notes != "Asthma, inhaler in her bag" || medical.DietaryNeeds != nil {
+		t.Fatalf("medical info %+v, want the notes and no dietary needs", medical)
+	}
+	var stored string
+	if err := a.tx.Raw("SELECT medical FROM profiles WHERE id = ?", asha.ID).Scan(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored == "" || strings.Contains(stored, "Asthma") {
+		t.Fatalf("medical column holds %q, want it encrypted", stored)
+	}
+
+	a.call("POST", "/api/visits", gin.H{
+		"profile_id": asha.ID, "stay_area_id": dorm.ID, "arrival_date": "2026-03-01",
+		"emergency_contacts": []gin.H{{"name": "Lakshmi", "relation": "Mother", "phone_number": "+91 98450 12345"}, {"name": "Ravi", "phone_number": "98450 67890"}},
+		"medical":            gin.H{"notes": "Asthma, inhaler in her bag", "dietary_needs": "No peanuts"},
+	}, 200, nil)
+	a.call("GET", profile+"/medical", nil, 200, &medical)
+	if medical.DietaryNeeds == nil || *medical.DietaryNeeds != "No peanuts" {
+		t.Fatalf("medical info after check-in %+v, want no peanuts", medical)
+	}
+
+	var onSite handler.RollCallResponse
+	a.call("GET", "/api/on-site", nil, 200, &onSite)
+	entry := onSite.StayAreas[0].Entries[0]
+	if len(entry.Visit.Profile.EmergencyContacts) != 2 || entry.Medical == nil || *entry.Medical.DietaryNeeds != "No peanuts" {
+		t.Fatalf("on site entry %+v with medical %+v, want both contacts and her dietary needs", entry.Visit.Profile.EmergencyContacts, entry.Medical)
+	}
+	a.fetch("GET", "/api/on-site?format=pdf", 200, "application/pdf")
+
+	// everyone sees whom to call, only staff what is wrong
+	a.token = ""
+	a.call("GET", "/api/on-site", nil, 200, &onSite)
+	entry = onSite.StayAreas[0].Entries[0]
+	if len(entry.Visit.Profile.EmergencyContacts) != 2 || entry.Medical != nil {
+		t.Fatalf("anonymous on site entry has medical %+v, want only the contacts", entry.Medical)
+	}
+}
@@ -0,0 +1,126 @@
+package api_test
+
+import (
+	"counterapp/internal/handler"
+	"counterapp/internal/model"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPersonalDataExport(t *testing.T) { forBackends(t, testPersonalDataExport) }
+
+func testPersonalDataExport(t *testing.T, a *app) {
+	asha := a.profile("Asha", model.GenderFemale)
+	visit := a.checkIn(asha, a.stayArea("North Dorm", 10), "2026-03-01", "2026-03-10")
+	a.call("POST", "/api/feedbacks", gin.H{"profile_id": asha.ID, "visit_id": visit.ID, "content": "Kind to everyone", "type": model.TypePositive}, 201, nil)
+	target := "/api/profiles/" + asha.ID.String() + "/personal-data"
+
+	a.callError("GET", target, nil, 401, handler.CodeUnauthorized)
+	a.signInAsAdmin()
+
+	rec := a.fetch("GET", target, 200, "application/json")
+	if disposition := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(disposition, "attachment") {
+		t.Fatalf("content disposition %q, want an attachment", disposition)
+	}
+	var data model.PersonalData
+	a.call("GET", target, nil, 200, &data)
+	if data.Profile.Email != asha.Email || len(data.Visits) != 1 || len(data.Feedbacks) != 1 || len(data.Notifications) != 1 {
+		t.Fatalf("bundle of %s has %d visits, %d feedbacks and %d notifications, want one each",
+			data.Profile.Email, len(data.Visits), len(data.Feedbacks), len(data.Notifications))
+	}
+	if data.Visits[0].StayArea.Name != "North Dorm" {
+		t.Fatalf("visit in %q, want the preloaded North Dorm", data.Visits[0].StayArea.Name)
+	}
+
+	var records []model.AuditRecord
+	a.call("GET", "/api/audit?entity_id="+asha.ID.String(), nil, 200, &records)
+	if len(records) != 2 || records[0].Action != model.AuditPersonalDataExported || records[0].ActorID == nil {
+		t.Fatalf("got %d audit records, want both exports with their actor", len(records))
+	}
+	a.callError("GET", "/api/profiles/"+unknownID()+"/personal-data", nil, 404, handler.CodeNotFound)
+	a.callError("GET", "/api/audit?entity_id=asha", nil, 400, handler.CodeValidation)
+}
+
+func TestEraseProfile(t *testing.T) { forBackends(t, testEraseProfile) }
+
+func testEraseProfile(t *testing.T, a *app) {
+	stayArea := a.stayArea("North Dorm", 10)
+	asha := a.profile("Asha", model.GenderFemale)
+	a.call("PATCH", "/api/profiles/"+asha.ID.String(), gin.H{"remarks": "Allergic to peanuts"}, 200, nil)
+	a.checkIn(asha, stayArea, "2026-03-01", "")
+	a.call("POST", "/api/feedbacks", gin.H{"profile_id": asha.ID, "content": "Kind to everyone", "type": model.TypePositive}, 201, nil)
+	erase := "/api/profiles/" + asha.ID.String() + "/erase"
+
+	a.callError("POST", erase, gin.H{"confirm": asha.Email}, 401, handler.CodeUnauthorized)
+	admin := a.signInAsAdmin()
+	a.callError("POST", erase, gin.H{}, 400, handler.CodeValidation)
+	a.callError("POST", erase, gin.H{"confirm": "someone@example.com"}, 400, handler.CodeValidation)
+
+	var erased model.Profile
+	a.call("POST", erase, gin.H{"confirm": strings.ToUpper(asha.Email)}, 200, &erased)
+	if erased.Name != model.ErasedName || erased.Email != model.ErasedEmail(asha.ID) || erased.PhoneNumber != "" || erased.Remarks != nil || erased.ErasedAt == nil {
+		t.Fatalf("erased profile %q %q %q %v, want it anonymized", erased.Name, erased.Email, erased.PhoneNumber, erased.Remarks)
+	}
+	if erased.Gender != asha.Gender || erased.Category != asha.Category {
+		t.Fatalf("erased profile is %s %s, want the gender and category kept", erased.Gender, erased.Category)
+	}
+
+	// the statistics still count the visit and the feedback
+	if got := a.occupancy()[stayArea.ID.String()].CurrentOccupiedCount; got != 1 {
+		t.Fatalf("%d occupants after the erasure, want the visit kept", got)
+	}
+	var feedbacks []model.Feedback
+	a.call("GET", "/api/profiles/"+asha.ID.String()+"/feedbacks", nil, 200, &feedbacks)
+	if len(feedbacks) != 1 || feedbacks[0].Content != model.ErasedFeedback || feedbacks[0].Type != model.TypePositive {
+		t.Fatalf("feedbacks after the erasure %+v, want the type kept and the content erased", feedbacks)
+	}
+	var messages []model.OutboxMessage
+	a.call("GET", "/api/notifications", nil, 200, &messages)
+	if len(messages) != 0 {
+		t.Fatalf("%d notifications left after the erasure, want 0", len(messages))
+	}
+
+	var records []model.AuditRecord
+	a.call("GET", "/api/audit?action=profile.erased", nil, 200, &records)
+	if len(records) != 1 || records[0].EntityID != asha.ID || *records[0].ActorID != admin.ID || records[0].Details["feedbacks"] != float64(1) {
+		t.Fatalf("audit records %+v, want the erasure by the admin", records)
+	}
+
+	a.callError("POST", erase, gin.H{"confirm": erased.Email}, 409, handler.CodeConflict)
+	a.callError("POST", "/api/profiles/"+unknownID()+"/erase", gin.H{"confirm": asha.Email}, 404, handler.CodeNotFound)
+}
+
+func TestPrivacyOfDeletedProfile(t *testing.T) { forBackends(t, testPrivacyOfDeletedProfile) }
+
+// a deleted profile holds the same data until it is purged, so it can still
+// be exported and erased
+func testPrivacyOfDeletedProfile(t *testing.T, a *app) {
+	asha := a.profile("Asha", model.GenderFemale)
+	a.checkIn(asha, a.stayArea("North Dorm", 10), "2026-03-01", "2026-03-10")
+	a.call("DELETE", "/api/profiles/"+asha.ID.String(), nil, 204, nil)
+	a.signInAsAdmin()
+
+	var data model.PersonalData
+	a.call("GET", "/api/profiles/"+asha.ID.String()+"/personal-data", nil, 200, &data)
+	if data.Profile.Email != asha.Email || len(data.Visits) != 1 {
+		t.Fatalf("bundle of the deleted profile is %s with %d visits, want %s with its visit", data.Profile.Email, len(data.Visits), asha.Email)
+	}
+
+	var erased model.Profile
+	a.call("POST", "/api/profiles/"+asha.ID.String()+"/erase", gin.H{"confirm": asha.Email}, 200, &erased)
+	if erased.Name != model.ErasedName || erased.Email != model.ErasedEmail(asha.ID) {
+		t.Fatalf("erased profile %q %q, want it anonymized", erased.Name, erased.Email)
+	}
+
+	// still deleted, and anonymized when brought back
+	if n := a.profileCount(); n != 0 {
+		t.Fatalf("%d profiles listed after erasing a deleted one, want 0", n)
+	}
+	var restored model.Profile
+	a.call("POST", "/api/profiles/"+asha.ID.String()+"/restore", nil, 200, &restored)
+	if restored.Name != model.ErasedName || restored.ErasedAt == nil {
+		t.Fatalf("restored profile %q, want the erased one", restored.Name)
+	}
+}
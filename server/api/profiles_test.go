@@ -0,0 +1,124 @@
+package api_test
+
+import (
+	"counterapp/internal/handler"
+	"counterapp/internal/model"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestProfiles(t *testing.T) { forBackends(t, testProfiles) }
+
+func testProfiles(t *testing.T, a *app) {
+	stayArea := a.stayArea("North Dorm", 10)
+	asha := a.profile("Asha", model.GenderFemale)
+	ravi := a.profile("Ravi", model.GenderMale)
+	visit := a.checkIn(asha, stayArea, "2026-03-01", "2026-03-10")
+
+	var profiles []model.ProfileOverview
+	a.call("GET", "/api/profiles", nil, 200, &profiles)
+	byID := map[string]model.ProfileOverview{}
+	for _, p := range profiles {
+		byID[p.ID] = p
+	}
+	if len(byID) != 2 {
+		t.Fatalf("got %d profiles, want 2", len(byID))
+	}
+	if p := byID[asha.ID.String()]; p.ActiveVisitID == nil || *p.ActiveVisitID != visit.ID.String() || p.StayArea == nil || *p.StayArea != "North Dorm" {
+		t.Fatalf("checked in profile lists visit %v in %v, want %s in North Dorm", p.ActiveVisitID, p.StayArea, visit.ID)
+	}
+	if p := byID[ravi.ID.String()]; p.ActiveVisitID != nil {
+		t.Fatalf("profile without visits lists visit %v", *p.ActiveVisitID)
+	}
+
+	var updated model.Profile
+	a.call("PATCH", "/api/profiles/"+ravi.ID.String(), gin.H{"name": "Ravi Kumar", "category": model.CategoryLTV}, 200, &updated)
+	if updated.Name != "Ravi Kumar" || updated.Category != model.CategoryLTV || updated.Email != ravi.Email {
+		t.Fatalf("updated profile %q %q %q", updated.Name, updated.Category, updated.Email)
+	}
+}
+
+func TestProfileValidation(t *testing.T) { forBackends(t, testProfileValidation) }
+
+func testProfileValidation(t *testing.T, a *app) {
+	profile := a.profile("Asha", model.GenderFemale)
+
+	body := a.callError("POST", "/api/profiles", gin.H{"profile": gin.H{"name": "Asha", "email": "not-an-email", "gender": "Unknown"}}, 400, handler.CodeValidation)
+	if len(body.Details) != 2 {
+		t.Fatalf("got details %+v, want one for email and one for gender", body.Details)
+	}
+	a.callError("POST", "/api/profiles", gin.H{"name": "Asha"}, 400, handler.CodeValidation)
+	a.callError("POST", "/api/profiles", strings.NewReader("{"), 400, handler.CodeInvalidBody)
+	a.callError("PATCH", "/api/profiles/"+profile.ID.String(), gin.H{"gender": "Unknown"}, 400, handler.CodeValidation)
+	body = a.callError("POST", "/api/profiles", gin.H{"profile": gin.H{"name": "Ravi", "email": "ravi@example.com", "gender": model.GenderMale, "phone_number": "ask at the desk"}}, 400, handler.CodeValidation)
+	if len(body.Details) != 1 || body.Details[0].Field != "phone_number" {
+		t.Fatalf("got details %+v, want one for phone_number", body.Details)
+	}
+	a.callError("PATCH", "/api/profiles/"+profile.ID.String(), gin.H{"phone_number": "+91 123"}, 400, handler.CodeValidation)
+	a.callError("PATCH", "/api/profiles/"+unknownID(), gin.H{"name": "Nobody"}, 404, handler.CodeNotFound)
+
+	// last, the unique violation aborts the test transaction
+	a.callError("POST", "/api/profiles", gin.H{"profile": gin.H{"name": "Asha", "email": profile.Email, "gender": model.GenderFemale}}, 409, handler.CodeConflict)
+}
+
+func TestDeleteAndRestoreProfile(t *testing.T) { forBackends(t, testDeleteAndRestoreProfile) }
+
+func testDeleteAndRestoreProfile(t *testing.T, a *app) {
+	stayArea := a.stayArea("North Dorm", 10)
+	asha := a.profile("Asha", model.GenderFemale)
+	a.checkIn(asha, stayArea, "2026-03-01", "")
+	a.call("POST", "/api/feedbacks", gin.H{"profile_id": asha.ID, "content": "Helped in the kitchen", "type": model.TypePositive}, 201, nil)
+
+	a.call("DELETE", "/api/profiles/"+asha.ID.String(), nil, 204, nil)
+	if n := a.profileCount(); n != 0 {
+		t.Fatalf("%d profiles listed after the delete, want 0", n)
+	}
+	if got := a.occupancy()[stayArea.ID.String()].CurrentOccupiedCount; got != 0 {
+		t.Fatalf("%d occupants after the delete, want 0", got)
+	}
+	var feedbacks []model.Feedback
+	a.call("GET", "/api/feedbacks", nil, 200, &feedbacks)
+	if len(feedbacks) != 0 {
+		t.Fatalf("%d feedbacks listed after the delete, want 0", len(feedbacks))
+	}
+	a.callError("PATCH", "/api/profiles/"+asha.ID.String(), gin.H{"name": "Asha Devi"}, 404, handler.CodeNotFound)
+	a.callError("DELETE", "/api/profiles/"+asha.ID.String(), nil, 404, handler.CodeNotFound)
+
+	var restored model.Profile
+	a.call("POST", "/api/profiles/"+asha.ID.String()+"/restore", nil, 200, &restored)
+	if restored.ID != asha.ID || restored.DeletedAt.Valid {
+		t.Fatalf("restored profile %s deleted at %v", restored.ID, restored.DeletedAt)
+	}
+	if got := a.occupancy()[stayArea.ID.String()].CurrentOccupiedCount; got != 1 {
+		t.Fatalf("%d occupants after the restore, want the visit back", got)
+	}
+	a.call("GET", "/api/feedbacks", nil, 200, &feedbacks)
+	if len(feedbacks) != 1 {
+		t.Fatalf("%d feedbacks listed after the restore, want 1", len(feedbacks))
+	}
+	a.callError("POST", "/api/profiles/"+asha.ID.String()+"/restore", nil, 404, handler.CodeNotFound)
+	a.callError("DELETE", "/api/profiles/"+unknownID(), nil, 404, handler.CodeNotFound)
+	a.callError("DELETE", "/api/profiles/asha", nil, 404, handler.CodeNotFound)
+}
+
+func TestPurgeProfile(t *testing.T) { forBackends(t, testPurgeProfile) }
+
+func testPurgeProfile(t *testing.T, a *app) {
+	asha := a.profile("Asha", model.GenderFemale)
+	a.checkIn(asha, a.stayArea("North Dorm", 10), "2026-03-01", "")
+	purge := "/api/profiles/" + asha.ID.String() + "/purge"
+
+	a.callError("DELETE", purge, nil, 401, handler.CodeUnauthorized)
+	a.signInAsAdmin()
+	a.callError("DELETE", purge, nil, 409, handler.CodeConflict)
+
+	a.call("DELETE", "/api/profiles/"+asha.ID.String(), nil, 204, nil)
+	a.call("DELETE", purge, nil, 204, nil)
+	a.callError("POST", "/api/profiles/"+asha.ID.String()+"/restore", nil, 404, handler.CodeNotFound)
+	a.callError("DELETE", purge, nil, 404, handler.CodeNotFound)
+
+	// the email is free again
+	a.call("POST", "/api/profiles", gin.H{"profile": gin.H{"name": "Asha", "email": asha.Email, "gender": model.GenderFemale}}, 200, nil)
+}
@@ -0,0 +1,154 @@
+package api_test
+
+import (
+	"counterapp/internal/config"
+	"counterapp/internal/handler"
+	"counterapp/internal/model"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// the profile as stored, there is no endpoint returning one profile
+func (a *app) storedProfile(profile model.Profile) *model.Profile {
+	a.t.Helper()
+	stored, err := a.svc.Profiles.Get(profile.ID.String())
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return stored
+}
+
+func TestRetention(t *testing.T) {
+	rules := config.Retention{Interval: time.Hour, InactiveProfileYears: 2, RemarksMonths: 12, FeedbackMonths: 12}
+	a := newApp(t, func(cfg *config.Config) { cfg.Retention = rules })
+	longAgo := time.Now().AddDate(-3, 0, 0)
+
+	asha := a.profile("Asha", model.GenderFemale)
+	a.call("PATCH", "/api/profiles/"+asha.ID.String(), gin.H{"remarks": "Allergic to peanuts"}, 200, nil)
+	a.tx.Model(&model.Profile{}).Where("id = ?", asha.ID).Update("created_at", longAgo)
+	// still comes, nothing about her is due
+	ravi := a.profile("Ravi", model.GenderMale)
+	a.call("PATCH", "/api/profiles/"+ravi.ID.String(), gin.H{"remarks": "Prefers the kitchen"}, 200, nil)
+	a.tx.Model(&model.Profile{}).Where("id = ?", ravi.ID).Update("created_at", longAgo)
+	a.checkIn(ravi, a.stayArea("North Dorm", 10), "2026-03-01", "")
+	var feedback model.Feedback
+	a.call("POST", "/api/feedbacks", gin.H{"profile_id": ravi.ID, "content": "Kind to everyone", "type": model.TypePositive}, 201, &feedback)
+	a.tx.Model(&model.Feedback{}).Where("id = ?", feedback.ID).Update("created_at", longAgo)
+
+	a.callError("GET", "/api/retention/report", nil, 401, handler.CodeUnauthorized)
+	a.signInAsAdmin()
+
+	var report model.RetentionReport
+	a.call("GET", "/api/retention/report", nil, 200, &report)
+	due := map[string][]string{}
+	for _, r := range report.Rules {
+		for _, id := range r.IDs {
+			due[r.Rule] = append(due[r.Rule], id.String())
+		}
+	}
+	if !report.DryRun || len(report.Rules) != 5 || !slices.Equal(due["inactive_profiles"], []string{asha.ID.String()}) ||
+		!slices.Equal(due["profile_remarks"], []string{asha.ID.String()}) || !slices.Equal(due["feedback"], []string{feedback.ID.String()}) {
+		t.Fatalf("report has %d rules due %v, want Asha anonymized and the old feedback cleared", len(report.Rules), due)
+	}
+
+	if _, err := a.svc.Retention.Apply(rules, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	erased := a.storedProfile(asha)
+	if erased.Name != model.ErasedName || erased.Remarks != nil {
+		t.Fatalf("inactive profile %q with remarks %v, want it anonymized", erased.Name, erased.Remarks)
+	}
+	kept := a.storedProfile(ravi)
+	if kept.Name != "Ravi" || kept.Remarks == nil {
+		t.Fatalf("active profile %q with remarks %v, want it untouched", kept.Name, kept.Remarks)
+	}
+
+	var records []model.AuditRecord
+	a.call("GET", "/api/audit?action=profile.erased", nil, 200, &records)
+	if len(records) != 1 || records[0].EntityID != asha.ID || records[0].ActorID != nil || records[0].Details["reason"] != "inactive_profiles" {
+		t.Fatalf("erasure audit records %+v, want the rule erasing Asha", records)
+	}
+	a.call("GET", "/api/audit?action=feedback.cleared", nil, 200, &records)
+	if len(records) != 1 || records[0].EntityID != feedback.ID || records[0].Details["field"] != "content" {
+		t.Fatalf("feedback audit records %+v, want the old feedback", records)
+	}
+
+	a.call("GET", "/api/retention/report", nil, 200, &report)
+	for _, r := range report.Rules {
+		if r.Count != 0 {
+			t.Fatalf("rule %s still has %d rows due after it was applied", r.Rule, r.Count)
+		}
+	}
+}
+
+// the rules on both backends, applied a few years from now instead of aging
+// the rows
+func TestRetentionLater(t *testing.T) {
+	forBackends(t, func(t *testing.T, a *app) {
+		rules := config.Retention{InactiveProfileYears: 2, RemarksMonths: 12, FeedbackMonths: 12, PurgeDeletedDays: 30}
+		later := time.Now().AddDate(3, 0, 0)
+
+		asha := a.profile("Asha", model.GenderFemale)
+		a.call("PATCH", "/api/profiles/"+asha.ID.String(), gin.H{"remarks": "Allergic to peanuts"}, 200, nil)
+		ravi := a.profile("Ravi", model.GenderMale)
+		a.call("PATCH", "/api/profiles/"+ravi.ID.String(), gin.H{"remarks": "Prefers the kitchen"}, 200, nil)
+		a.checkIn(ravi, a.stayArea("North Dorm", 10), "2026-03-01", "")
+		var feedback model.Feedback
+		a.call("POST", "/api/feedbacks", gin.H{"profile_id": ravi.ID, "content": "Kind to everyone", "type": model.TypePositive}, 201, &feedback)
+		meera := a.profile("Meera", model.GenderFemale)
+		a.call("DELETE", "/api/profiles/"+meera.ID.String(), nil, 204, nil)
+
+		report, err := a.svc.Retention.Report(rules, later)
+		if err != nil {
+			t.Fatal(err)
+		}
+		due := map[string][]string{}
+		for _, r := range report.Rules {
+			for _, id := range r.IDs {
+				due[r.Rule] = append(due[r.Rule], id.String())
+			}
+		}
+		if len(report.Rules) != 7 || !slices.Equal(due["purge_deleted_profiles"], []string{meera.ID.String()}) ||
+			!slices.Equal(due["inactive_profiles"], []string{asha.ID.String()}) ||
+			!slices.Equal(due["profile_remarks"], []string{asha.ID.String()}) || !slices.Equal(due["feedback"], []string{feedback.ID.String()}) {
+			t.Fatalf("report has %d rules due %v, want Meera purged, Asha anonymized and the feedback cleared", len(report.Rules), due)
+		}
+
+		if _, err := a.svc.Retention.Apply(rules, later); err != nil {
+			t.Fatal(err)
+		}
+		erased := a.storedProfile(asha)
+		if erased.Name != model.ErasedName || erased.Remarks != nil {
+			t.Fatalf("inactive profile %q with remarks %v, want it anonymized", erased.Name, erased.Remarks)
+		}
+		kept := a.storedProfile(ravi)
+		if kept.Name != "Ravi" || kept.Remarks == nil {
+			t.Fatalf("checked-in profile %q with remarks %v, want it untouched", kept.Name, kept.Remarks)
+		}
+		a.callError("POST", "/api/profiles/"+meera.ID.String()+"/restore", nil, 404, handler.CodeNotFound)
+
+		a.signInAsAdmin()
+		var records []model.AuditRecord
+		a.call("GET", "/api/audit?action=record.purged", nil, 200, &records)
+		if len(records) != 1 || records[0].EntityID != meera.ID || records[0].Details["rule"] != "purge_deleted_profiles" {
+			t.Fatalf("purge audit records %+v, want the rule purging Meera", records)
+		}
+		a.call("GET", "/api/audit?action=profile.erased", nil, 200, &records)
+		if len(records) != 1 || records[0].EntityID != asha.ID || records[0].Details["reason"] != "inactive_profiles" {
+			t.Fatalf("erasure audit records %+v, want the rule erasing Asha", records)
+		}
+
+		report, err = a.svc.Retention.Report(rules, later)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range report.Rules {
+			if r.Count != 0 {
+				t.Fatalf("rule %s still has %d rows due after it was applied", r.Rule, r.Count)
+			}
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package api_test
+
+import (
+	"counterapp/internal/handler"
+	"counterapp/internal/model"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRollCall(t *testing.T) { forBackends(t, testRollCall) }
+
+func testRollCall(t *testing.T, a *app) {
+	a.sevaType("Kitchen")
+	north, south := a.stayArea("North Dorm", 10), a.stayArea("South Dorm", 10)
+	asha := a.profile("Asha", model.GenderFemale)
+	ashaVisit := a.checkIn(asha, north, "2026-03-01", "")
+	a.checkIn(a.profile("Ravi", model.GenderMale), south, "2026-03-01", "")
+	a.call("POST", "/api/schedules", gin.H{
+		"profile_id": asha.ID, "visit_id": ashaVisit.ID, "seva_type": "Kitchen", "location": "Main Kitchen", "date": time.Now().Format(time.DateOnly),
+	}, 201, nil)
+	// checked out, so not on site
+	left := a.checkIn(a.profile("Meera", model.GenderFemale), north, "2026-03-01", "")
+	a.call("PATCH", "/api/visits/"+left.ID.String(), gin.H{"status": model.StatusCheckedOut}, 200, nil)
+
+	var onSite handler.RollCallResponse
+	a.call("GET", "/api/on-site", nil, 200, &onSite)
+	if onSite.RollCall != nil || onSite.Total != 2 || len(onSite.StayAreas) != 2 || onSite.StayAreas[0].StayArea.Name != "North Dorm" {
+		t.Fatalf("on site %d people in %d stay areas, want Asha and Ravi in North and South Dorm", onSite.Total, len(onSite.StayAreas))
+	}
+	first := onSite.StayAreas[0].Entries[0]
+	if first.Visit.Profile.Name != "Asha" || first.Visit.Profile.PhoneNumber == "" || first.Schedule == nil || *first.Schedule.Location != "Main Kitchen" {
+		t.Fatalf("first on site %q, want Asha with her phone number and seva in the Main Kitchen", first.Visit.Profile.Name)
+	}
+	a.fetch("GET", "/api/on-site?format=pdf", 200, "application/pdf")
+
+	admin := a.signInAsAdmin()
+	var started handler.RollCallResponse
+	a.call("POST", "/api/roll-calls", gin.H{"note": "Fire drill"}, 201, &started)
+	if started.RollCall == nil || *started.RollCall.StartedBy != admin.ID || started.Total != 2 || started.Accounted != 0 {
+		t.Fatalf("started roll call %+v, want both people to account for", started)
+	}
+	// arriving after the start does not change the roll call
+	a.checkIn(a.profile("Kiran", model.GenderMale), south, "2026-03-01", "")
+
+	rollCall := "/api/roll-calls/" + started.RollCall.ID.String()
+	ravi := started.StayAreas[1].Entries[0]
+	var marked model.RollCallEntry
+	a.call("PATCH", rollCall+"/entries/"+ravi.ID.String(), gin.H{"accounted": true}, 200, &marked)
+	if marked.AccountedAt == nil || *marked.AccountedBy != admin.ID || marked.Visit.Profile.Name != "Ravi" {
+		t.Fatalf("marked %q at %v, want Ravi accounted for by the admin", marked.Visit.Profile.Name, marked.AccountedAt)
+	}
+	a.callError("PATCH", rollCall+"/entries/"+ravi.ID.String(), gin.H{}, 400, handler.CodeValidation)
+	a.callError("PATCH", rollCall+"/entries/"+unknownID(), gin.H{"accounted": true}, 404, handler.CodeNotFound)
+
+	var current handler.RollCallResponse
+	a.call("GET", rollCall, nil, 200, &current)
+	if current.Total != 2 || current.Accounted != 1 || current.StayAreas[1].Accounted != 1 {
+		t.Fatalf("roll call has %d of %d accounted for, want Ravi of 2", current.Accounted, current.Total)
+	}
+	a.fetch("GET", rollCall+"?format=pdf", 200, "application/pdf")
+
+	var closed handler.RollCallResponse
+	a.call("POST", rollCall+"/close", nil, 200, &closed)
+	if closed.RollCall.ClosedAt == nil {
+		t.Fatal("roll call not closed")
+	}
+	a.callError("PATCH", rollCall+"/entries/"+ravi.ID.String(), gin.H{"accounted": false}, 409, handler.CodeConflict)
+	a.callError("POST", rollCall+"/close", nil, 409, handler.CodeConflict)
+
+	var summaries []model.RollCallSummary
+	a.call("GET", "/api/roll-calls", nil, 200, &summaries)
+	if len(summaries) != 1 || summaries[0].Total != 2 || summaries[0].Accounted != 1 || *summaries[0].Note != "Fire drill" {
+		t.Fatalf("roll calls %+v, want the drill with 1 of 2 accounted for", summaries)
+	}
+	a.callError("GET", "/api/roll-calls/"+unknownID(), nil, 404, handler.CodeNotFound)
+	a.callError("GET", "/api/roll-calls?limit=0", nil, 400, handler.CodeValidation)
+}
@@ -1,56 +1,128 @@
 package api
 
 import (
+	"counterapp/internal/config"
+	"counterapp/internal/events"
 	"counterapp/internal/handler"
+	"counterapp/internal/metrics"
+	"counterapp/internal/model"
+	"counterapp/internal/service"
+	"log/slog"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-func SetupRouter(db *gorm.DB) *gin.Engine {
-	router := gin.Default()
+// Requests are counted in m when it is not nil, /metrics is served here when
+// no separate metrics port is configured.
+func SetupRouter(cfg *config.Config, logger *slog.Logger, svc *service.Services, bus *events.Bus, m *metrics.Metrics) *gin.Engine {
+	router := gin.New()
+	router.Use(handler.RequestID(), handler.Logger(logger), gin.CustomRecovery(handler.Recover))
+	if m != nil {
+		router.Use(m.Middleware())
+		if cfg.Server.MetricsPort == 0 {
+			router.GET("/metrics", gin.WrapH(m.Handler()))
+		}
+	}
+	router.NoRoute(handler.NoRoute)
+
+	// probes come from the platform, not a browser, and carry no token
+	router.GET("/healthz", handler.Healthz)
+	router.GET("/readyz", handler.Readyz(svc))
 
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"https://ashram-connect.vercel.app", "http://localhost:3000", "http://localhost:5173"},
+		AllowOrigins:     cfg.Server.CORSOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", handler.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", "Content-Disposition", handler.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
+	router.Use(handler.Authenticate(svc))
+	adminOnly := handler.RequireRole(model.RoleAdmin)
+	medicalRoles := handler.RequireRole(model.MedicalRoles...)
 
 	//Profiles
-	router.GET("/api/profiles", handler.GetProfiles(db))
-	router.POST("/api/profiles", handler.CreateProfile(db))
-	router.PATCH("/api/profiles/:id", handler.UpdateProfile(db))
+	router.GET("/api/profiles", handler.GetProfiles(svc))
+	router.POST("/api/profiles", handler.CreateProfile(svc))
+	router.PATCH("/api/profiles/:id", handler.UpdateProfile(svc))
+	router.DELETE("/api/profiles/:id", handler.DeleteProfile(svc))
+	router.POST("/api/profiles/:id/restore", handler.RestoreProfile(svc))
+	router.DELETE("/api/profiles/:id/purge", adminOnly, handler.PurgeProfile(svc))
+	router.GET("/api/profiles/:id/personal-data", adminOnly, handler.GetPersonalData(svc))
+	router.POST("/api/profiles/:id/erase", adminOnly, handler.EraseProfile(svc))
+	router.GET("/api/profiles/:id/medical", medicalRoles, handler.GetMedical(svc))
+	router.PUT("/api/profiles/:id/medical", medicalRoles, handler.SetMedical(svc))
 
 	//Visits
-	router.GET("/api/profiles/:id/visits", handler.GetVisitsForProfile(db))
-	router.POST("/api/visits", handler.AddVisit(db))
-	router.PATCH("/api/visits/:id", handler.UpdateVisit(db))
+	router.GET("/api/profiles/:id/visits", handler.GetVisitsForProfile(svc))
+	router.POST("/api/visits", handler.AddVisit(svc))
+	router.PATCH("/api/visits/:id", handler.UpdateVisit(svc))
+	router.DELETE("/api/visits/:id", handler.DeleteVisit(svc))
+	router.POST("/api/visits/:id/restore", handler.RestoreVisit(svc))
+	router.DELETE("/api/visits/:id/purge", adminOnly, handler.PurgeVisit(svc))
+	router.GET("/api/visits/:id/slip", handler.GetVisitSlip(svc))
+	router.GET("/api/visits/lookup", handler.LookupVisitByCode(svc))
 
 	//Schedules
-	router.GET("/api/schedules", handler.GetScheduleForDateRange(db))
-	router.POST("/api/schedules", handler.AddSchedule(db))
+	router.GET("/api/schedules", handler.GetScheduleForDateRange(svc))
+	router.POST("/api/schedules", handler.AddSchedule(svc))
+	router.GET("/api/schedules/roster.pdf", handler.GetRosterPDF(svc))
 
 	//Lockers
-	router.GET("/api/lockers", handler.GetAllLockersDetails(db))
+	router.GET("/api/lockers", handler.GetAllLockersDetails(svc))
 
 	//Feedbacks
-	router.GET("/api/feedbacks", handler.GetAllFeedbacks(db))
-	router.GET("/api/profiles/:id/feedbacks", handler.GetFeedbackForProfile(db))
-	router.POST("/api/feedbacks", handler.AddFeedback(db))
+	router.GET("/api/feedbacks", handler.GetAllFeedbacks(svc))
+	router.GET("/api/profiles/:id/feedbacks", handler.GetFeedbackForProfile(svc))
+	router.POST("/api/feedbacks", handler.AddFeedback(svc))
 
 	//SevaTypes
-	router.GET("/api/seva-types", handler.GetAllSevaTypes(db))
-	router.POST("/api/seva-types", handler.AddSevaType(db))
+	router.GET("/api/seva-types", handler.GetAllSevaTypes(svc))
+	router.POST("/api/seva-types", handler.AddSevaType(svc))
 
 	//StayAreas
-	router.GET("/api/stay-areas", handler.GetAllStayAreas(db))
-	router.POST("/api/stay-areas", handler.AddStayArea(db))
-	router.GET("/api/stay-areas/occupancy", handler.GetStayAreaDetailsAndOccupancy(db))
+	router.GET("/api/stay-areas", handler.GetAllStayAreas(svc))
+	router.POST("/api/stay-areas", handler.AddStayArea(svc))
+	router.GET("/api/stay-areas/occupancy", handler.GetStayAreaDetailsAndOccupancy(svc))
+
+	//Roll calls
+	router.GET("/api/on-site", handler.GetOnSite(svc))
+	router.GET("/api/roll-calls", handler.GetRollCalls(svc))
+	router.POST("/api/roll-calls", handler.StartRollCall(svc))
+	router.GET("/api/roll-calls/:id", handler.GetRollCall(svc))
+	router.PATCH("/api/roll-calls/:id/entries/:entry_id", handler.MarkRollCallEntry(svc))
+	router.POST("/api/roll-calls/:id/close", handler.CloseRollCall(svc))
+
+	//Events
+	router.GET("/api/events/stream", handler.StreamEvents(svc, bus))
+
+	//Exports
+	router.GET("/api/exports/schedules", handler.ExportSchedules(svc))
+	router.GET("/api/exports/residents", handler.ExportResidents(svc))
+	router.GET("/api/exports/profiles", handler.ExportProfiles(svc))
+
+	//Notifications
+	router.GET("/api/notifications", handler.GetNotifications(svc))
+	router.POST("/api/notifications/:id/retry", handler.RetryNotification(svc))
+
+	//Webhooks
+	router.GET("/api/webhooks", adminOnly, handler.GetAllWebhooks(svc))
+	router.POST("/api/webhooks", adminOnly, handler.AddWebhook(svc))
+	router.DELETE("/api/webhooks/:id", adminOnly, handler.DeleteWebhook(svc))
+	router.GET("/api/webhooks/:id/deliveries", adminOnly, handler.GetWebhookDeliveries(svc))
+	router.POST("/api/webhooks/deliveries/:id/redeliver", adminOnly, handler.RedeliverWebhook(svc))
+
+	//Users
+	router.POST("/api/users", adminOnly, handler.AddUser(svc))
+
+	//Audit
+	router.GET("/api/audit", adminOnly, handler.GetAuditRecords(svc))
+	router.GET("/api/retention/report", adminOnly, handler.GetRetentionReport(svc, cfg.Retention))
+
+	//Imports
+	router.POST("/api/imports/profiles", handler.ImportProfiles(svc))
 
 	return router
 }
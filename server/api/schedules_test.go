@@ -0,0 +1,122 @@
+package api_test
+
+import (
+	"counterapp/internal/handler"
+	"counterapp/internal/model"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAssignSchedule(t *testing.T) { forBackends(t, testAssignSchedule) }
+
+func testAssignSchedule(t *testing.T, a *app) {
+	a.sevaType("Kitchen")
+	profile := a.profile("Asha", model.GenderFemale)
+	visit := a.checkIn(profile, a.stayArea("North Dorm", 10), "2026-03-01", "2026-03-10")
+
+	var schedule model.Schedule
+	a.call("POST", "/api/schedules", gin.H{
+		"profile_id": profile.ID, "visit_id": visit.ID, "seva_type": "Kitchen", "location": "Main Kitchen", "date": "2026-03-02",
+	}, 201, &schedule)
+	if schedule.VisitID != visit.ID || schedule.Location == nil || *schedule.Location != "Main Kitchen" {
+		t.Fatalf("schedule for visit %s at %v", schedule.VisitID, schedule.Location)
+	}
+	a.call("POST", "/api/schedules", gin.H{
+		"profile_id": profile.ID, "visit_id": visit.ID, "seva_type": "Kitchen", "date": "2026-03-03",
+	}, 201, nil)
+
+	var schedules []model.Schedule
+	a.call("GET", "/api/schedules?start_date=2026-03-01&end_date=2026-03-02", nil, 200, &schedules)
+	if len(schedules) != 1 || schedules[0].ID != schedule.ID {
+		t.Fatalf("got %d schedules for the range, want the one on 2026-03-02", len(schedules))
+	}
+	if schedules[0].SevaType.Name != "Kitchen" || schedules[0].Profile.Name != "Asha" {
+		t.Fatalf("schedule of %q for %q, want preloaded Kitchen for Asha", schedules[0].SevaType.Name, schedules[0].Profile.Name)
+	}
+	a.call("GET", "/api/schedules?start_date=2026-03-01&end_date=2026-03-31", nil, 200, &schedules)
+	if len(schedules) != 2 {
+		t.Fatalf("got %d schedules for the month, want 2", len(schedules))
+	}
+	a.callError("GET", "/api/schedules?start_date=2026-03-31&end_date=2026-03-01", nil, 400, handler.CodeValidation)
+	a.callError("GET", "/api/schedules?start_date=2026-03-01", nil, 400, handler.CodeValidation)
+}
+
+func TestDuplicateScheduleConflicts(t *testing.T) { forBackends(t, testDuplicateScheduleConflicts) }
+
+func testDuplicateScheduleConflicts(t *testing.T, a *app) {
+	a.sevaType("Kitchen")
+	a.sevaType("Garden")
+	profile := a.profile("Asha", model.GenderFemale)
+	visit := a.checkIn(profile, a.stayArea("North Dorm", 10), "2026-03-01", "")
+	body := gin.H{"profile_id": profile.ID, "visit_id": visit.ID, "seva_type": "Kitchen", "date": "2026-03-02"}
+
+	a.call("POST", "/api/schedules", body, 201, nil)
+	a.callError("POST", "/api/schedules", body, 409, handler.CodeConflict)
+	body["seva_type"] = "Garden"
+	a.callError("POST", "/api/schedules", body, 409, handler.CodeConflict)
+
+	var schedules []model.Schedule
+	a.call("GET", "/api/schedules?start_date=2026-03-02&end_date=2026-03-02", nil, 200, &schedules)
+	if len(schedules) != 1 || schedules[0].SevaType.Name != "Kitchen" {
+		t.Fatalf("got %d schedules, want only the first", len(schedules))
+	}
+}
+
+func TestAssignScheduleValidation(t *testing.T) { forBackends(t, testAssignScheduleValidation) }
+
+func testAssignScheduleValidation(t *testing.T, a *app) {
+	a.sevaType("Kitchen")
+	stayArea := a.stayArea("North Dorm", 10)
+	asha := a.profile("Asha", model.GenderFemale)
+	ravi := a.profile("Ravi", model.GenderMale)
+	visit := a.checkIn(asha, stayArea, "2026-03-01", "")
+	left := a.checkIn(ravi, stayArea, "2026-03-01", "")
+	a.call("PATCH", "/api/visits/"+left.ID.String(), gin.H{"status": model.StatusCheckedOut}, 200, nil)
+
+	res := a.callError("POST", "/api/schedules", gin.H{
+		"profile_id": asha.ID, "visit_id": visit.ID, "seva_type": "Astrology", "date": "2026-03-02",
+	}, 400, handler.CodeValidation)
+	if len(res.Details) != 1 || res.Details[0].Field != "seva_type" {
+		t.Fatalf("unknown seva type reported on %+v, want seva_type", res.Details)
+	}
+
+	res = a.callError("POST", "/api/schedules", gin.H{
+		"profile_id": ravi.ID, "visit_id": visit.ID, "seva_type": "Kitchen", "date": "2026-03-02",
+	}, 400, handler.CodeValidation)
+	if len(res.Details) != 1 || res.Details[0].Field != "visit_id" {
+		t.Fatalf("visit of another profile reported on %+v, want visit_id", res.Details)
+	}
+
+	a.callError("POST", "/api/schedules", gin.H{
+		"profile_id": ravi.ID, "visit_id": left.ID, "seva_type": "Kitchen", "date": "2026-03-02",
+	}, 400, handler.CodeValidation)
+	a.callError("POST", "/api/schedules", gin.H{
+		"profile_id": asha.ID, "visit_id": unknownID(), "seva_type": "Kitchen", "date": "2026-03-02",
+	}, 404, handler.CodeNotFound)
+	a.callError("POST", "/api/schedules", gin.H{
+		"profile_id": asha.ID, "visit_id": visit.ID, "date": "2026-03-02",
+	}, 400, handler.CodeValidation)
+}
+
+func TestRosterPDF(t *testing.T) { forBackends(t, testRosterPDF) }
+
+func testRosterPDF(t *testing.T, a *app) {
+	a.sevaType("Kitchen")
+	profile := a.profile("Asha", model.GenderFemale)
+	visit := a.checkIn(profile, a.stayArea("North Dorm", 10), "2026-03-01", "")
+	a.call("POST", "/api/schedules", gin.H{
+		"profile_id": profile.ID, "visit_id": visit.ID, "seva_type": "Kitchen", "date": "2026-03-02",
+	}, 201, nil)
+
+	for _, target := range []string{
+		"/api/schedules/roster.pdf?date=2026-03-02",
+		"/api/schedules/roster.pdf?date=2026-03-02&page_per_location=true",
+		"/api/schedules/roster.pdf?date=2026-03-03",
+	} {
+		if body := a.fetch("GET", target, 200, "application/pdf").Body.String(); len(body) < 4 || body[:4] != "%PDF" {
+			t.Fatalf("%s is not a PDF", target)
+		}
+	}
+	a.callError("GET", "/api/schedules/roster.pdf", nil, 400, handler.CodeValidation)
+}
@@ -0,0 +1,170 @@
+package api_test
+
+import (
+	"context"
+	"counterapp/internal/events"
+	"counterapp/internal/handler"
+	"counterapp/internal/logging"
+	"counterapp/internal/model"
+	"counterapp/internal/service"
+	"fmt"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestHealth(t *testing.T) { forBackends(t, testHealth) }
+
+func testHealth(t *testing.T, a *app) {
+	var health handler.HealthResponse
+	a.call("GET", "/healthz", nil, 200, &health)
+	if health.Status != "ok" {
+		t.Fatalf("liveness %+v", health)
+	}
+	a.call("GET", "/readyz", nil, 200, &health)
+	if health.Status != "ok" || health.Checks["database"] != "ok" || health.Checks["migrations"] != "ok" {
+		t.Fatalf("readiness %+v, want every check ok", health)
+	}
+}
+
+func TestUnknownRoute(t *testing.T) {
+	a := newApp(t)
+	a.callError("GET", "/api/nothing", nil, 404, handler.CodeNotFound)
+}
+
+func TestAuthentication(t *testing.T) {
+	a := newApp(t)
+	_, token, err := a.svc.Users.CreateAdmin("Desk Admin", "admin@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.token = token
+	a.call("GET", "/api/stay-areas", nil, 200, nil)
+	a.token = "cat_unknown"
+	a.callError("GET", "/api/stay-areas", nil, 401, handler.CodeUnauthorized)
+	a.token = ""
+	a.call("GET", "/api/stay-areas", nil, 200, nil)
+
+	rec := a.request("GET", "/api/stay-areas", nil, "Authorization", "Basic YWRtaW46YWRtaW4=")
+	if rec.Code != 401 {
+		t.Fatalf("basic auth: status %d, want 401", rec.Code)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	a := newApp(t)
+	stayArea := a.stayArea("North Dorm", 10)
+	a.checkIn(a.profile("Asha", model.GenderFemale), stayArea, "2020-01-01", "2020-01-02")
+	a.checkIn(a.profile("Meera", model.GenderFemale), stayArea, "2026-03-01", "")
+	// a planned visit is not a check-in
+	planned := a.profile("Kavya", model.GenderFemale)
+	if _, err := a.svc.Visits.Plan(service.PlanVisitRequest{ProfileID: planned.ID, ArrivalDate: time.Now(), StayAreaID: stayArea.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	body := a.fetch("GET", "/metrics", 200, "text/plain").Body.String()
+	for _, want := range []string{
+		`counterapp_checked_in_visits{gender="Female",stay_area="North Dorm"} 2`,
+		`counterapp_checked_in_visits{gender="Male",stay_area="North Dorm"} 0`,
+		`counterapp_stay_area_capacity{stay_area="North Dorm"} 10`,
+		`counterapp_overdue_visits 1`,
+		`counterapp_check_ins 2`,
+		`counterapp_check_ins_today 2`,
+		`http_requests_total{method="POST",route="/api/visits",status="200"} 2`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("metrics do not contain %s", want)
+		}
+	}
+}
+
+// new clients get an occupancy snapshot, clients that cannot be resumed a
+// reset first
+func TestEventStream(t *testing.T) {
+	a := newApp(t)
+	stayArea := a.stayArea("North Dorm", 10)
+
+	stream := func(lastEventID string) string {
+		ctx, cancel := context.WithCancel(context.Background())
+		// the stream returns once the initial events are written
+		cancel()
+		req := httptest.NewRequest("GET", "/api/events/stream", nil).WithContext(ctx)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		rec := httptest.NewRecorder()
+		a.router.ServeHTTP(rec, req)
+		if rec.Code != 200 || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/event-stream") {
+			t.Fatalf("stream: status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		return rec.Body.String()
+	}
+
+	body := stream("")
+	if strings.Contains(body, "event: reset") || !strings.Contains(body, "event: occupancy.updated") || !strings.Contains(body, stayArea.ID.String()) {
+		t.Fatalf("new client got %q, want an occupancy snapshot", body)
+	}
+	body = stream("gone-42")
+	if !strings.Contains(body, "event: reset") || !strings.Contains(body, "event: occupancy.updated") {
+		t.Fatalf("unresumable client got %q, want a reset and a snapshot", body)
+	}
+	a.callError("GET", "/api/events/stream?types=visit.teleported", nil, 400, handler.CodeValidation)
+
+	sub, _, _ := a.bus.Subscribe("")
+	defer sub.Close()
+	a.checkIn(a.profile("Asha", model.GenderFemale), stayArea, "2026-03-01", "")
+	var published []events.Type
+	for len(sub.C) > 0 {
+		env := <-sub.C
+		published = append(published, env.Event.Type)
+	}
+	if len(published) != 2 || published[0] != events.VisitCheckedIn || published[1] != events.OccupancyUpdated {
+		t.Fatalf("check-in published %v, want the check-in and an occupancy update", published)
+	}
+}
+
+func TestPostgresEvents(t *testing.T) {
+	if server == nil {
+		t.Skip(skipReason)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bus := events.NewBus(10)
+	sub, _, _ := bus.Subscribe("")
+	defer sub.Close()
+	go events.ListenPostgres(ctx, server.DSN, bus, logging.Discard())
+
+	// far over the 8000 bytes NOTIFY takes, as with many stay areas
+	occupancy := make([]events.OccupancyData, 200)
+	for i := range occupancy {
+		occupancy[i] = events.OccupancyData{StayAreaID: uuid.NewString(), StayName: fmt.Sprintf("Dorm %d", i), Capacity: 10}
+	}
+	publisher := events.NewPostgresPublisher(server.DB, logging.Discard())
+	var published []uuid.UUID
+	t.Cleanup(func() { server.DB.Exec("DELETE FROM event_payloads WHERE id IN ?", published) })
+
+	// the listener may not be listening yet, so publish until one arrives
+	deadline := time.After(10 * time.Second)
+	for {
+		evt := events.New(events.OccupancyUpdated, occupancy)
+		published = append(published, evt.ID)
+		publisher.Publish(evt)
+
+		select {
+		case env := <-sub.C:
+			data, ok := env.Event.Data.([]any)
+			if !slices.Contains(published, env.Event.ID) || env.Event.Type != events.OccupancyUpdated || !ok || len(data) != len(occupancy) {
+				t.Fatalf("received %s %s with %T data, want one of the published occupancy updates", env.Event.Type, env.Event.ID, env.Event.Data)
+			}
+			return
+		case <-time.After(200 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no event received through postgres")
+		}
+	}
+}
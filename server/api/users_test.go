@@ -0,0 +1,43 @@
+package api_test
+
+import (
+	"counterapp/internal/handler"
+	"counterapp/internal/model"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStaffUsers(t *testing.T) {
+	forBackends(t, func(t *testing.T, a *app) {
+		priya := gin.H{"name": "Priya", "email": "priya@example.com", "role": model.RoleStaff}
+		a.callError("POST", "/api/users", priya, 401, handler.CodeUnauthorized)
+
+		a.signInAsAdmin()
+		a.callError("POST", "/api/users", gin.H{"name": "Priya", "email": "priya@example.com", "role": "cook"}, 400, handler.CodeValidation)
+		var staff handler.AddUserResponse
+		a.call("POST", "/api/users", priya, 201, &staff)
+		if staff.Role != model.RoleStaff || !strings.HasPrefix(staff.Token, "cat_") {
+			t.Fatalf("created %s with token %q, want staff with an API token", staff.Role, staff.Token)
+		}
+		a.callError("POST", "/api/users", priya, 409, handler.CodeConflict)
+
+		asha := a.profile("Asha", model.GenderFemale)
+		medical := "/api/profiles/" + asha.ID.String() + "/medical"
+		a.call("PUT", medical, gin.H{"notes": "Asthma"}, 200, nil)
+
+		// staff read and change medical info but manage nothing else
+		a.token = staff.Token
+		var info model.MedicalInfo
+		a.call("GET", medical, nil, 200, &info)
+		if info.Notes == nil || *info.Notes != "Asthma" {
+			t.Fatalf("staff read medical info %+v, want the notes", info)
+		}
+		a.call("PUT", medical, gin.H{"notes": "Asthma, inhaler in her bag"}, 200, nil)
+		a.callError("POST", "/api/users", gin.H{"name": "Ravi", "email": "ravi@example.com", "role": model.RoleAdmin}, 403, handler.CodeForbidden)
+
+		a.token = ""
+		a.callError("GET", medical, nil, 401, handler.CodeUnauthorized)
+	})
+}
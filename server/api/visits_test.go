@@ -0,0 +1,288 @@
+package api_test
+
+import (
+	"counterapp/internal/handler"
+	"counterapp/internal/model"
+	"counterapp/internal/report"
+	"counterapp/internal/repository"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCheckIn(t *testing.T) { forBackends(t, testCheckIn) }
+
+func testCheckIn(t *testing.T, a *app) {
+	stayArea := a.stayArea("North Dorm", 10)
+	profile := a.profile("Asha", model.GenderFemale)
+
+	visit := a.checkIn(profile, stayArea, "2026-03-01", "2026-03-10")
+	if visit.Status != model.StatusCheckedIn {
+		t.Fatalf("status %q, want %q", visit.Status, model.StatusCheckedIn)
+	}
+	if visit.ProfileID != profile.ID || visit.StayAreaID != stayArea.ID {
+		t.Fatalf("visit of profile %s in %s, want %s in %s", visit.ProfileID, visit.StayAreaID, profile.ID, stayArea.ID)
+	}
+	if visit.CheckedOutAt != nil {
+		t.Fatalf("checked out at %v, want nil", visit.CheckedOutAt)
+	}
+
+	visits := a.visits(profile)
+	if len(visits) != 1 || visits[0].ID != visit.ID {
+		t.Fatalf("profile has %d visits, want the one checked in", len(visits))
+	}
+	if visits[0].StayArea.Name != "North Dorm" {
+		t.Fatalf("stay area %q, want the preloaded North Dorm", visits[0].StayArea.Name)
+	}
+}
+
+func TestCheckInValidation(t *testing.T) { forBackends(t, testCheckInValidation) }
+
+func testCheckInValidation(t *testing.T, a *app) {
+	stayArea := a.stayArea("North Dorm", 10)
+	profile := a.profile("Asha", model.GenderFemale)
+
+	a.callError("POST", "/api/visits", gin.H{
+		"profile_id": profile.ID, "stay_area_id": stayArea.ID, "arrival_date": "2026-03-10", "departure_date": "2026-03-01",
+	}, 400, handler.CodeValidation)
+	a.callError("POST", "/api/visits", gin.H{
+		"profile_id": profile.ID, "stay_area_id": stayArea.ID, "arrival_date": "01/03/2026",
+	}, 400, handler.CodeValidation)
+	a.callError("POST", "/api/visits", gin.H{
+		"profile_id": unknownID(), "stay_area_id": stayArea.ID, "arrival_date": "2026-03-01",
+	}, 404, handler.CodeNotFound)
+	a.callError("POST", "/api/visits", gin.H{
+		"profile_id": profile.ID, "stay_area_id": unknownID(), "arrival_date": "2026-03-01",
+	}, 404, handler.CodeNotFound)
+
+	if visits := a.visits(profile); len(visits) != 0 {
+		t.Fatalf("rejected check-ins left %d visits", len(visits))
+	}
+}
+
+func TestBlockedProfileCannotCheckIn(t *testing.T) { forBackends(t, testBlockedProfileCannotCheckIn) }
+
+func testBlockedProfileCannotCheckIn(t *testing.T, a *app) {
+	stayArea := a.stayArea("North Dorm", 10)
+	profile := a.profile("Ravi", model.GenderMale)
+	a.call("PATCH", "/api/profiles/"+profile.ID.String(), gin.H{"is_blocked": true}, 200, nil)
+
+	a.callError("POST", "/api/visits", gin.H{
+		"profile_id": profile.ID, "stay_area_id": stayArea.ID, "arrival_date": "2026-03-01",
+	}, 403, handler.CodeForbidden)
+}
+
+// a person is only in one place, checking in again checks the earlier visit
+// out instead of failing
+func TestDoubleCheckIn(t *testing.T) { forBackends(t, testDoubleCheckIn) }
+
+func testDoubleCheckIn(t *testing.T, a *app) {
+	north := a.stayArea("North Dorm", 10)
+	south := a.stayArea("South Dorm", 10)
+	profile := a.profile("Asha", model.GenderFemale)
+
+	first := a.checkIn(profile, north, "2026-03-01", "")
+	second := a.checkIn(profile, south, "2026-03-02", "")
+
+	visits := a.visits(profile)
+	if len(visits) != 2 {
+		t.Fatalf("profile has %d visits, want 2", len(visits))
+	}
+	if v := findVisit(t, visits, first.ID); v.Status != model.StatusCheckedOut || v.CheckedOutAt == nil {
+		t.Fatalf("first visit is %q checked out at %v, want checked out", v.Status, v.CheckedOutAt)
+	}
+	if v := findVisit(t, visits, second.ID); v.Status != model.StatusCheckedIn {
+		t.Fatalf("second visit is %q, want checked in", v.Status)
+	}
+
+	occupancy := a.occupancy()
+	if got := occupancy[north.ID.String()].CurrentOccupiedCount; got != 0 {
+		t.Fatalf("north dorm has %d occupants, want 0", got)
+	}
+	if got := occupancy[south.ID.String()].CurrentOccupiedCount; got != 1 {
+		t.Fatalf("south dorm has %d occupants, want 1", got)
+	}
+}
+
+func TestCheckInTransaction(t *testing.T) { forBackends(t, testCheckInTransaction) }
+
+// what a check-in changes before it fails is rolled back, and nothing is
+// announced about it
+func testCheckInTransaction(t *testing.T, a *app) {
+	north := a.stayArea("North Dorm", 10)
+	profile := a.profile("Asha", model.GenderFemale)
+	first := a.checkIn(profile, north, "2026-03-01", "")
+	sub, _, _ := a.bus.Subscribe("")
+	defer sub.Close()
+
+	failed := errors.New("stay area went away")
+	err := a.repos.Transactions.Run(func(repos *repository.Repositories) error {
+		contacts := []model.EmergencyContact{{Name: "Lakshmi", Relation: "Mother", PhoneNumber: "+91 98450 12345"}}
+		if _, err := repos.Profiles.Update(profile.ID.String(), &model.ProfileUpdate{EmergencyContacts: &contacts}); err != nil {
+			return err
+		}
+		checkedOut := model.StatusCheckedOut
+		if _, err := repos.Visits.Update(first.ID.String(), model.UpdateVisitRequest{Status: &checkedOut}); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("transaction returned %v, want the error of fn", err)
+	}
+
+	if v := findVisit(t, a.visits(profile), first.ID); v.Status != model.StatusCheckedIn {
+		t.Fatalf("visit is %q after the rollback, want still checked in", v.Status)
+	}
+	if stored := a.storedProfile(profile); len(stored.EmergencyContacts) != 0 {
+		t.Fatalf("emergency contacts %+v after the rollback, want none", stored.EmergencyContacts)
+	}
+	select {
+	case env := <-sub.C:
+		t.Fatalf("published %s from a transaction that was rolled back", env.Event.Type)
+	default:
+	}
+}
+
+func TestCheckOutAndBack(t *testing.T) { forBackends(t, testCheckOutAndBack) }
+
+func testCheckOutAndBack(t *testing.T, a *app) {
+	stayArea := a.stayArea("North Dorm", 10)
+	profile := a.profile("Asha", model.GenderFemale)
+	visit := a.checkIn(profile, stayArea, "2026-03-01", "")
+	path := "/api/visits/" + visit.ID.String()
+
+	var updated model.Visit
+	a.call("PATCH", path, gin.H{"status": model.StatusCheckedOut, "departure_date": "2026-03-05", "remarks": "left early"}, 200, &updated)
+	if updated.Status != model.StatusCheckedOut || updated.CheckedOutAt == nil {
+		t.Fatalf("visit is %q checked out at %v, want checked out with a time", updated.Status, updated.CheckedOutAt)
+	}
+	if updated.Remarks == nil || *updated.Remarks != "left early" {
+		t.Fatalf("remarks %v, want left early", updated.Remarks)
+	}
+	if got := a.occupancy()[stayArea.ID.String()].CurrentOccupiedCount; got != 0 {
+		t.Fatalf("%d occupants after check-out, want 0", got)
+	}
+
+	a.call("PATCH", path, gin.H{"status": model.StatusCheckedIn}, 200, &updated)
+	if updated.CheckedOutAt != nil {
+		t.Fatalf("checked out at %v after checking back in, want nil", updated.CheckedOutAt)
+	}
+
+	a.callError("PATCH", path, gin.H{"status": "gone"}, 400, handler.CodeValidation)
+	a.callError("PATCH", path, gin.H{"departure_date": "2026-02-01"}, 400, handler.CodeValidation)
+	a.callError("PATCH", path, gin.H{"stay_area_id": unknownID()}, 404, handler.CodeNotFound)
+	a.callError("PATCH", "/api/visits/"+unknownID(), gin.H{"remarks": "x"}, 404, handler.CodeNotFound)
+}
+
+func TestOccupancyCounts(t *testing.T) { forBackends(t, testOccupancyCounts) }
+
+func testOccupancyCounts(t *testing.T, a *app) {
+	north := a.stayArea("North Dorm", 3)
+	south := a.stayArea("South Dorm", 2)
+	empty := a.stayArea("Guest House", 4)
+
+	for _, name := range []string{"Asha", "Meera", "Lata"} {
+		a.checkIn(a.profile(name, model.GenderFemale), north, "2026-03-01", "")
+	}
+	leaving := a.checkIn(a.profile("Ravi", model.GenderMale), south, "2026-03-01", "")
+	a.checkIn(a.profile("Arun", model.GenderMale), south, "2026-03-01", "")
+	a.call("PATCH", "/api/visits/"+leaving.ID.String(), gin.H{"status": model.StatusCheckedOut}, 200, nil)
+
+	occupancy := a.occupancy()
+	for _, want := range []handler.StayAreaOccupancyResponse{
+		{StayAreaID: north.ID.String(), StayName: "North Dorm", Capacity: 3, CurrentOccupiedCount: 3, Available: 0},
+		{StayAreaID: south.ID.String(), StayName: "South Dorm", Capacity: 2, CurrentOccupiedCount: 1, Available: 1},
+		{StayAreaID: empty.ID.String(), StayName: "Guest House", Capacity: 4, CurrentOccupiedCount: 0, Available: 4},
+	} {
+		if got := occupancy[want.StayAreaID]; got != want {
+			t.Errorf("occupancy %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestAssignLocker(t *testing.T) {
+	a := newApp(t)
+	locker := model.Locker{LockerNumber: "12", Section: "A"}
+	if err := a.tx.Create(&locker).Error; err != nil {
+		t.Fatal(err)
+	}
+	visit := a.checkIn(a.profile("Asha", model.GenderFemale), a.stayArea("North Dorm", 10), "2026-03-01", "")
+
+	var updated model.Visit
+	a.call("PATCH", "/api/visits/"+visit.ID.String(), gin.H{"locker_id": locker.ID}, 200, &updated)
+	if updated.LockerID == nil || *updated.LockerID != locker.ID {
+		t.Fatalf("locker %v, want %s", updated.LockerID, locker.ID)
+	}
+
+	var lockers []model.Locker
+	a.call("GET", "/api/lockers", nil, 200, &lockers)
+	if len(lockers) != 1 || lockers[0].ID != locker.ID {
+		t.Fatalf("got %d lockers, want the one created", len(lockers))
+	}
+}
+
+func TestVisitSlipAndLookup(t *testing.T) { forBackends(t, testVisitSlipAndLookup) }
+
+func testVisitSlipAndLookup(t *testing.T, a *app) {
+	visit := a.checkIn(a.profile("Asha", model.GenderFemale), a.stayArea("North Dorm", 10), "2026-03-01", "2026-03-10")
+	path := "/api/visits/" + visit.ID.String() + "/slip"
+
+	if body := a.fetch("GET", path, 200, "application/pdf").Body.String(); len(body) < 4 || body[:4] != "%PDF" {
+		t.Fatalf("slip is not a PDF")
+	}
+	if body := a.fetch("GET", path+"?format=png", 200, "image/png").Body.String(); len(body) < 4 || body[1:4] != "PNG" {
+		t.Fatalf("slip is not a PNG")
+	}
+	a.callError("GET", path+"?format=gif", nil, 400, handler.CodeValidation)
+	a.callError("GET", "/api/visits/"+unknownID()+"/slip", nil, 404, handler.CodeNotFound)
+
+	var found model.Visit
+	a.call("GET", "/api/visits/lookup?code="+url.QueryEscape(report.QRPayload(visit.ID)), nil, 200, &found)
+	if found.ID != visit.ID || found.Profile.Name != "Asha" {
+		t.Fatalf("lookup found visit %s of %q, want %s of Asha", found.ID, found.Profile.Name, visit.ID)
+	}
+	a.callError("GET", "/api/visits/lookup?code=nonsense", nil, 400, handler.CodeValidation)
+}
+
+func TestDeleteAndRestoreVisit(t *testing.T) { forBackends(t, testDeleteAndRestoreVisit) }
+
+func testDeleteAndRestoreVisit(t *testing.T, a *app) {
+	stayArea := a.stayArea("North Dorm", 10)
+	profile := a.profile("Asha", model.GenderFemale)
+	visit := a.checkIn(profile, stayArea, "2026-03-01", "")
+
+	a.call("DELETE", "/api/visits/"+visit.ID.String(), nil, 204, nil)
+	if visits := a.visits(profile); len(visits) != 0 {
+		t.Fatalf("profile has %d visits after the delete, want 0", len(visits))
+	}
+	if got := a.occupancy()[stayArea.ID.String()].CurrentOccupiedCount; got != 0 {
+		t.Fatalf("%d occupants after the delete, want 0", got)
+	}
+	a.callError("PATCH", "/api/visits/"+visit.ID.String(), gin.H{"status": model.StatusCheckedOut}, 404, handler.CodeNotFound)
+
+	var restored model.Visit
+	a.call("POST", "/api/visits/"+visit.ID.String()+"/restore", nil, 200, &restored)
+	if restored.ID != visit.ID || restored.StayArea.Name != "North Dorm" {
+		t.Fatalf("restored visit %s in %q, want %s in North Dorm", restored.ID, restored.StayArea.Name, visit.ID)
+	}
+	if got := a.occupancy()[stayArea.ID.String()].CurrentOccupiedCount; got != 1 {
+		t.Fatalf("%d occupants after the restore, want 1", got)
+	}
+
+	// a visit deleted with its profile comes back with the profile only
+	a.call("DELETE", "/api/profiles/"+profile.ID.String(), nil, 204, nil)
+	a.callError("POST", "/api/visits/"+visit.ID.String()+"/restore", nil, 409, handler.CodeConflict)
+	a.call("POST", "/api/profiles/"+profile.ID.String()+"/restore", nil, 200, nil)
+
+	a.callError("DELETE", "/api/visits/"+visit.ID.String()+"/purge", nil, 401, handler.CodeUnauthorized)
+	a.callError("POST", "/api/visits/"+unknownID()+"/restore", nil, 404, handler.CodeNotFound)
+
+	// last, checking in again takes the one checked-in slot and the restore
+	// fails on the unique index, which aborts the test transaction
+	a.call("DELETE", "/api/visits/"+visit.ID.String(), nil, 204, nil)
+	a.checkIn(profile, stayArea, "2026-03-05", "")
+	a.callError("POST", "/api/visits/"+visit.ID.String()+"/restore", nil, 409, handler.CodeConflict)
+}